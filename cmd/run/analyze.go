@@ -0,0 +1,373 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/big"
+	"os"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/zintix-labs/problab"
+	"github.com/zintix-labs/problab-scaffold/internal/exact"
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+	"github.com/zintix-labs/problab/spec"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// analyzeConfig holds the flags of the `analyze` subcommand.
+type analyzeConfig struct {
+	id         spec.GID
+	betMode    int
+	config     string
+	seed       int64
+	spins      int
+	worker     int
+	confidence float64
+	out        string
+	outFile    string
+}
+
+// analyzeRow is an exact value next to its simulated estimate.
+type analyzeRow struct {
+	Metric      string  `json:"metric"      yaml:"metric"`
+	Exact       float64 `json:"exact"       yaml:"exact"`
+	Sim         float64 `json:"sim"         yaml:"sim"`
+	Delta       float64 `json:"delta"       yaml:"delta"` // Sim - Exact
+	SE          float64 `json:"se"          yaml:"se"`    // standard error of Sim around Exact
+	Z           float64 `json:"z"           yaml:"z"`
+	P           float64 `json:"p"           yaml:"p"` // two-sided p-value of Sim == Exact
+	Significant bool    `json:"significant" yaml:"significant"`
+}
+
+// analyzeMode is the analysis of one game mode. Wins are per screen, in bet multiples.
+type analyzeMode struct {
+	GameMode       int           `json:"game_mode"                  yaml:"game_mode"`
+	Skipped        string        `json:"skipped,omitempty"          yaml:"skipped,omitempty"` // why there is no exact result
+	RTP            float64       `json:"rtp"                        yaml:"rtp"`               // exact win per screen over the bet
+	Exact          *exact.Result `json:"exact,omitempty"            yaml:"exact,omitempty"`
+	Screens        int           `json:"screens"                    yaml:"screens"` // simulated rounds of this mode
+	ScreensPerSpin float64       `json:"screens_per_spin"           yaml:"screens_per_spin"`
+	Metrics        []analyzeRow  `json:"metrics,omitempty"          yaml:"metrics,omitempty"`
+}
+
+// analyzeReport is what `analyze` writes.
+type analyzeReport struct {
+	Game       string        `json:"game"        yaml:"game"`
+	GameID     spec.GID      `json:"game_id"     yaml:"game_id"`
+	Config     *compareArm   `json:"config"      yaml:"config"`
+	BetMode    int           `json:"bet_mode"    yaml:"bet_mode"`
+	Seed       int64         `json:"seed"        yaml:"seed"`
+	Workers    int           `json:"workers"     yaml:"workers"`
+	Spins      int           `json:"spins"       yaml:"spins"`
+	ElapsedSec float64       `json:"elapsed_sec" yaml:"elapsed_sec"`
+	Confidence float64       `json:"confidence"  yaml:"confidence"`
+	Modes      []analyzeMode `json:"modes"       yaml:"modes"`
+	Partial    *partialInfo  `json:"partial,omitempty" yaml:"partial,omitempty"`
+}
+
+// runAnalyze computes the exact per-screen statistics of every line game mode of a config
+// and checks them against a simulation.
+//
+// A game mode is analyzed when its screens are drawn with GenReelByReelIdx and paid by
+// lines; the exact values are those of one screen, so they are compared with the
+// simulated rounds of that mode. This matches the logic when each round generates one
+// screen and pays its line wins as they are, like demo_normal; a difference that is
+// significant means the config, the engine or that assumption has changed. Mode 0 is
+// the base game: its exact RTP is the base-game RTP when a base spin plays one round.
+func runAnalyze(args []string) {
+	ac := new(analyzeConfig)
+	fs := flag.NewFlagSet("analyze", flag.ExitOnError)
+	fs.Var(gidFlag{&ac.id}, "game", "target game id")
+	fs.IntVar(&ac.betMode, "mode", 0, "bet mode index")
+	fs.StringVar(&ac.config, "config", armEmbedded, `config: "embedded" or a .yaml/.json config file of -game`)
+	fs.Int64Var(&ac.seed, "seed", -1, "int64 seed of the check simulation")
+	fs.IntVar(&ac.spins, "spins", 1000000, "spins per worker of the check simulation (0: exact values only)")
+	fs.IntVar(&ac.worker, "worker", 1, "number of workers")
+	fs.Float64Var(&ac.confidence, "confidence", 0.999, "confidence level of the exact-vs-simulated test, in (0,1)")
+	fs.StringVar(&ac.out, "out", "", "report format: text|json|csv|yaml (default text, or inferred from -o)")
+	fs.StringVar(&ac.outFile, "o", "", "write the report to this file instead of stdout")
+	fs.Parse(args)
+
+	if ac.spins < 0 || ac.worker < 1 {
+		log.Fatal("value err : spins must >= 0 and worker must > 0")
+	}
+	if ac.confidence <= 0 || ac.confidence >= 1 {
+		log.Fatal("value err : confidence must be in (0,1)")
+	}
+	oc := &config{out: ac.out, outFile: ac.outFile}
+	format, err := oc.outFormat()
+	if err != nil {
+		log.Fatal("value err : " + err.Error())
+	}
+	if ac.seed < 1 {
+		seed, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt64))
+		if err != nil {
+			log.Fatal(err)
+		}
+		ac.seed = seed.Int64()
+	}
+
+	lab := engine.MustNew()
+	ent, ok := lab.EntryById(ac.id)
+	if !ok {
+		log.Fatalf("value err : game id not found: %d", ac.id)
+	}
+	arm, err := loadArm(ac.config, ent.ConfigName, ac.id, ac.betMode)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rep := &analyzeReport{Game: ent.Name, GameID: ac.id, Config: arm, BetMode: ac.betMode, Seed: ac.seed, Workers: ac.worker, Confidence: ac.confidence, Modes: analyzeModes(arm)}
+
+	ctx, stop := interruptible()
+	defer stop()
+	p := message.NewPrinter(language.English)
+	w := oc.msgOut()
+	p.Fprintf(w, "\033[1;32m[ANALYZE] [WORKERS:%d] [GAME:%s] [PLAYMODE:%d] [SPINS:%d]\033[0m\n", ac.worker, ent.Name, ac.betMode, ac.worker*ac.spins)
+	if ac.spins > 0 {
+		if err := ac.simulate(ctx, lab, arm, rep, format == outText || ac.outFile != ""); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if format == outText && ac.outFile == "" {
+		stdOutAnalyze(os.Stdout, rep)
+	} else if err := writeReport(rep, format, ac.outFile); err != nil {
+		log.Fatal(err)
+	}
+	if rep.Partial != nil {
+		stop()
+		os.Exit(exitInterrupted)
+	}
+}
+
+// analyzeModes computes the exact side of every game mode of the config.
+func analyzeModes(arm *compareArm) []analyzeMode {
+	modes := make([]analyzeMode, len(arm.gs.GameModeSettings))
+	for i := range modes {
+		m := &modes[i]
+		m.GameMode = i
+		if res, err := exact.Analyze(&arm.gs.GameModeSettings[i]); err != nil {
+			m.Skipped = err.Error()
+		} else {
+			m.Exact, m.RTP = res, res.Win/float64(arm.BetUnit)
+		}
+	}
+	return modes
+}
+
+// simulate spins -worker machines of the config and sets the simulated side of every
+// analyzed mode. When ctx is cancelled the report is marked partial.
+func (ac *analyzeConfig) simulate(ctx context.Context, lab *problab.Problab, arm *compareArm, rep *analyzeReport, showpb bool) error {
+	t, used, err := tally(ctx, lab, arm, ac.id, ac.betMode, ac.seed, ac.worker, ac.spins, showpb)
+	if err != nil {
+		return err
+	}
+	if t.spins == 0 {
+		return fmt.Errorf("interrupted before the first spin: nothing to report")
+	}
+	rep.Spins, rep.ElapsedSec = t.spins, used.Seconds()
+	arm.MaxWin = t.maxWin
+	arm.MaxWinX = float64(arm.MaxWin) / float64(arm.BetUnit)
+	if planned := ac.spins * ac.worker; rep.Spins < planned {
+		rep.Partial = &partialInfo{Done: rep.Spins, Planned: planned, Unit: "spins"}
+	}
+
+	bet := float64(arm.BetUnit)
+	for i := range rep.Modes {
+		m, s := &rep.Modes[i], t.modes[i]
+		m.Screens = s.n
+		m.ScreensPerSpin = float64(s.n) / float64(rep.Spins)
+		if m.Exact == nil || s.n == 0 {
+			continue
+		}
+		n := float64(s.n)
+		m.Metrics = []analyzeRow{
+			ac.test("win_x", m.Exact.Win/bet, s.sum/n/bet, m.Exact.WinSD/bet/math.Sqrt(n)),
+			ac.test("hit_rate", m.Exact.HitRate, float64(s.hits)/n, math.Sqrt(m.Exact.HitRate*(1-m.Exact.HitRate)/n)),
+		}
+	}
+	return nil
+}
+
+// test compares a simulated mean with its exact value, whose standard error is known.
+func (ac *analyzeConfig) test(metric string, exact, sim, se float64) analyzeRow {
+	r := analyzeRow{Metric: metric, Exact: exact, Sim: sim, Delta: sim - exact, SE: se, P: 1}
+	if se > 0 {
+		r.Z = r.Delta / se
+		r.P = 2 * distuv.UnitNormal.Survival(math.Abs(r.Z))
+	} else if r.Delta != 0 {
+		r.P = 0
+	}
+	r.Significant = r.P < 1-ac.confidence
+	return r
+}
+
+// stdOutAnalyze prints the analysis in text mode.
+func stdOutAnalyze(out io.Writer, rep *analyzeReport) {
+	p := message.NewPrinter(language.English)
+	if rep.Partial != nil {
+		p.Fprintf(out, "\033[1;31m%s\033[0m\n", rep.Partial)
+	}
+	c := rep.Config
+	p.Fprintf(out, "config           : %s (sha256 %.12s, logic %s, bet %d)\n", c.Source, c.SHA256, c.Logic, c.BetUnit)
+	if rep.Spins > 0 {
+		p.Fprintf(out, "simulated spins  : %d (seed %d, %d workers, %.1fs, max win %.2fx)\n", rep.Spins, rep.Seed, rep.Workers, rep.ElapsedSec, c.MaxWinX)
+	}
+	for _, m := range rep.Modes {
+		if m.Exact == nil {
+			p.Fprintf(out, "\ngame mode %d      : skipped (%s)\n", m.GameMode, m.Skipped)
+			continue
+		}
+		e := m.Exact
+		p.Fprintf(out, "\ngame mode %d      : %dx%d %s, %d lines, %d reel sets, %.0f stop combinations\n", m.GameMode, e.Columns, e.Rows, e.BetType, e.Lines, e.ReelSets, e.Combinations)
+		p.Fprintf(out, "exact per screen : win %.6fx (rtp %.6f%%), hit rate %.6f%%, sd %.4fx\n", m.RTP, 100*m.RTP, 100*e.HitRate, e.WinSD/float64(c.BetUnit))
+		if m.Screens > 0 {
+			p.Fprintf(out, "simulated        : %d screens (%.4f per spin)\n", m.Screens, m.ScreensPerSpin)
+		}
+
+		if len(m.Metrics) > 0 {
+			tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+			fmt.Fprintln(tw, "METRIC\tEXACT\tSIM\tSIM-EXACT\tZ\tP\t\t")
+			for _, r := range m.Metrics {
+				sig := ""
+				if r.Significant {
+					sig = "*"
+				}
+				if r.Metric == "hit_rate" {
+					p.Fprintf(tw, "%s\t%.5f%%\t%.5f%%\t%+.5f%%\t%+.2f\t%.4g\t%s\t\n", r.Metric, 100*r.Exact, 100*r.Sim, 100*r.Delta, r.Z, r.P, sig)
+					continue
+				}
+				p.Fprintf(tw, "%s\t%.6f\t%.6f\t%+.6f\t%+.2f\t%.4g\t%s\t\n", r.Metric, r.Exact, r.Sim, r.Delta, r.Z, r.P, sig)
+			}
+			tw.Flush()
+		}
+
+		share := func(win float64) float64 {
+			if e.Win == 0 {
+				return 0
+			}
+			return win / e.Win
+		}
+		bet := float64(c.BetUnit)
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(tw, "SYMBOL\tCOUNT\tPAY\tHITS/SCREEN\tRTP\tSHARE\t")
+		for _, s := range e.Symbols {
+			p.Fprintf(tw, "%s\t%d\t%d\t%.6f\t%.5f%%\t%.2f%%\t\n", s.Symbol, s.Count, s.Pay, s.Hits, 100*s.Win/bet, 100*share(s.Win))
+		}
+		tw.Flush()
+
+		tw = tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(tw, "LINE\tDIR\tHIT RATE\tRTP\tSHARE\t")
+		for _, l := range e.LinePays {
+			p.Fprintf(tw, "%d\t%s\t%.5f%%\t%.5f%%\t%.2f%%\t\n", l.Line, l.Direction, 100*l.HitRate, 100*l.Win/bet, 100*share(l.Win))
+		}
+		tw.Flush()
+	}
+	if slices.ContainsFunc(rep.Modes, func(m analyzeMode) bool { return len(m.Metrics) > 0 }) {
+		p.Fprintf(out, "\n* simulated value differs from the exact one at %g%%; win_x is the win per screen in bet multiples\n", 100*rep.Confidence)
+	}
+	if rep.Partial != nil {
+		p.Fprintf(out, "\033[1;31m%s\033[0m\n", rep.Partial)
+	}
+}
+
+// writeAnalyzeCSV writes the analysis in long format (section,key,value); per-mode keys
+// are prefixed with the game mode, e.g. m0.rtp.
+func writeAnalyzeCSV(w io.Writer, r *analyzeReport) error {
+	cw := csv.NewWriter(w)
+	f := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	i := strconv.Itoa
+	rows := [][]string{
+		{"section", "key", "value"},
+		{"run", "game", r.Game},
+		{"run", "game_id", fmt.Sprint(uint(r.GameID))},
+		{"run", "config", r.Config.Source},
+		{"run", "config_sha256", r.Config.SHA256},
+		{"run", "bet_mode", i(r.BetMode)},
+		{"run", "bet_unit", i(r.Config.BetUnit)},
+		{"run", "seed", strconv.FormatInt(r.Seed, 10)},
+		{"run", "workers", i(r.Workers)},
+		{"run", "spins", i(r.Spins)},
+		{"run", "elapsed_sec", f(r.ElapsedSec)},
+		{"run", "confidence", f(r.Confidence)},
+	}
+	if pt := r.Partial; pt != nil {
+		rows = append(rows,
+			[]string{"partial", "done", i(pt.Done)},
+			[]string{"partial", "planned", i(pt.Planned)},
+			[]string{"partial", "unit", pt.Unit},
+		)
+	}
+	for _, m := range r.Modes {
+		k := "m" + i(m.GameMode) + "."
+		if m.Exact == nil {
+			rows = append(rows, []string{"mode", k + "skipped", m.Skipped})
+			continue
+		}
+		e := m.Exact
+		rows = append(rows,
+			[]string{"mode", k + "rtp", f(m.RTP)},
+			[]string{"mode", k + "win", f(e.Win)},
+			[]string{"mode", k + "win_sd", f(e.WinSD)},
+			[]string{"mode", k + "hit_rate", f(e.HitRate)},
+			[]string{"mode", k + "combinations", f(e.Combinations)},
+			[]string{"mode", k + "screens", i(m.Screens)},
+			[]string{"mode", k + "screens_per_spin", f(m.ScreensPerSpin)},
+		)
+		for _, x := range m.Metrics {
+			rows = append(rows,
+				[]string{"metric", k + x.Metric + ".exact", f(x.Exact)},
+				[]string{"metric", k + x.Metric + ".sim", f(x.Sim)},
+				[]string{"metric", k + x.Metric + ".delta", f(x.Delta)},
+				[]string{"metric", k + x.Metric + ".se", f(x.SE)},
+				[]string{"metric", k + x.Metric + ".z", f(x.Z)},
+				[]string{"metric", k + x.Metric + ".p", f(x.P)},
+				[]string{"metric", k + x.Metric + ".significant", strconv.FormatBool(x.Significant)},
+			)
+		}
+		for _, s := range e.Symbols {
+			sk := k + s.Symbol + "x" + i(s.Count) + "."
+			rows = append(rows,
+				[]string{"symbol", sk + "pay", i(s.Pay)},
+				[]string{"symbol", sk + "hits", f(s.Hits)},
+				[]string{"symbol", sk + "win", f(s.Win)},
+			)
+		}
+		for _, l := range e.LinePays {
+			lk := k + "line" + i(l.Line) + "." + l.Direction + "."
+			rows = append(rows,
+				[]string{"line", lk + "hit_rate", f(l.HitRate)},
+				[]string{"line", lk + "win", f(l.Win)},
+			)
+		}
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+	"github.com/zintix-labs/problab/spec"
+)
+
+func TestAnalyzeAgreesWithSimulation(t *testing.T) {
+	lab, err := engine.New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	ac := &analyzeConfig{id: spec.GID(0), seed: 42, spins: 20000, worker: 2, confidence: 0.999}
+	arm, err := loadArm(armEmbedded, "demo_0.yaml", ac.id, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep := &analyzeReport{GameID: ac.id, Config: arm, Seed: ac.seed, Workers: ac.worker, Confidence: ac.confidence, Modes: analyzeModes(arm)}
+	if err := ac.simulate(context.Background(), lab, arm, rep, false); err != nil {
+		t.Fatal(err)
+	}
+	if rep.Spins != 40000 || len(rep.Modes) != 2 || rep.Partial != nil {
+		t.Fatalf("report %+v", rep)
+	}
+	base := rep.Modes[0]
+	if base.Exact == nil || base.ScreensPerSpin != 1 || len(base.Metrics) != 2 {
+		t.Fatalf("base mode %+v", base)
+	}
+	if base.RTP < 0.55 || base.RTP > 0.65 {
+		t.Fatalf("exact base rtp %v", base.RTP)
+	}
+	for _, m := range rep.Modes {
+		for _, r := range m.Metrics {
+			if r.Significant {
+				t.Errorf("mode %d %s: exact %v, simulated %v (z %.2f)", m.GameMode, r.Metric, r.Exact, r.Sim, r.Z)
+			}
+		}
+	}
+
+	// the base rtp of the simulation is the base share of a plain run
+	r, err := newRunner(lab, ac.id, 0, ac.seed, ac.worker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, _, err := r.run(context.Background(), ac.spins)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := base.Metrics[0].Sim, float64(st.Summary.BaseWin)/float64(st.Summary.TotalBet); got < want-1e-9 || got > want+1e-9 {
+		t.Fatalf("simulated base rtp %v, plain run %v", got, want)
+	}
+
+	var buf bytes.Buffer
+	if err := writeAnalyzeCSV(&buf, rep); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "metric,m0.win_x.exact,") || !strings.Contains(buf.String(), "symbol,m1.H1x5.win,") {
+		t.Fatalf("csv:\n%s", buf.String())
+	}
+}
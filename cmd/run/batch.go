@@ -0,0 +1,206 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/zintix-labs/problab"
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+	"github.com/zintix-labs/problab/spec"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// batchReport is the result of `-all`: one row per (game, bet mode) in catalog order.
+type batchReport struct {
+	Seed    int64      `json:"seed"    yaml:"seed"`
+	Workers int        `json:"workers" yaml:"workers"`
+	Spins   int        `json:"spins"   yaml:"spins"` // spins per pair
+	Rows    []batchRow `json:"games"   yaml:"games"`
+
+	Partial *partialInfo `json:"partial,omitempty" yaml:"partial,omitempty"`
+}
+
+// batchRow summarizes one (game, bet mode) simulation.
+type batchRow struct {
+	Game       string   `json:"game"          yaml:"game"`
+	GameID     spec.GID `json:"game_id"       yaml:"game_id"`
+	Config     string   `json:"config"        yaml:"config"`
+	ConfigHash string   `json:"config_sha256" yaml:"config_sha256"`
+	BetMode    int      `json:"bet_mode"      yaml:"bet_mode"`
+	BetUnit    int      `json:"bet_unit"      yaml:"bet_unit"`
+	Spins      int      `json:"spins"         yaml:"spins"`
+	RTP        float64  `json:"rtp"           yaml:"rtp"`
+	RtpLo      float64  `json:"rtp_ci95_lo"   yaml:"rtp_ci95_lo"`
+	RtpHi      float64  `json:"rtp_ci95_hi"   yaml:"rtp_ci95_hi"`
+	HitRate    float64  `json:"hit_rate"      yaml:"hit_rate"`
+	MaxWin     int      `json:"max_win"       yaml:"max_win"`   // credits
+	MaxWinX    float64  `json:"max_win_x"     yaml:"max_win_x"` // in bet multiples
+	ElapsedSec float64  `json:"elapsed_sec"   yaml:"elapsed_sec"`
+	Partial    bool     `json:"partial,omitempty" yaml:"partial,omitempty"` // cut short by an interrupt
+}
+
+// runAll simulates every registered game and every bet_units index with the same
+// seed, worker count and spins, then prints one consolidated table.
+//
+// Pairs run one after another (each using -worker goroutines), so the runtime column
+// stays comparable between games. Cancelling ctx ends the batch after the current pair,
+// which is reported with the spins done so far.
+func runAll(ctx context.Context, lab *problab.Problab) {
+	sums, err := lab.Summary()
+	if err != nil {
+		log.Fatal(err)
+	}
+	p := message.NewPrinter(language.English)
+	w := cfg.msgOut()
+
+	pairs := 0
+	for _, s := range sums {
+		pairs += len(s.BetUnits)
+	}
+	green := "\033[1;32m"
+	reset := "\033[0m"
+	p.Fprintf(w, "%s[ALL] [GAMES:%d] [PAIRS:%d] [WORKERS:%d] [SPINS:%d per pair]%s\n", green, len(sums), pairs, cfg.worker, cfg.worker*cfg.spins, reset)
+
+	rep := &batchReport{Seed: cfg.seed, Workers: cfg.worker, Spins: cfg.worker * cfg.spins}
+	live.plan("spins", pairs*cfg.worker*cfg.spins, nil)
+	n := 0
+pairs:
+	for _, s := range sums {
+		ent, _ := lab.EntryById(s.GID)
+		hash, err := engine.ConfigSHA256(ent.ConfigName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for mode, bu := range s.BetUnits {
+			n++
+			r, err := newRunner(lab, s.GID, mode, cfg.seed, cfg.worker)
+			if err != nil {
+				log.Fatal(err)
+			}
+			live.track(s.Name, s.GID, mode, cfg.worker*cfg.spins, r.counts)
+			st, used, err := r.run(ctx, cfg.spins)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if st.Summary.Rounds == 0 {
+				break pairs
+			}
+			row := batchRow{
+				Game:       s.Name,
+				GameID:     s.GID,
+				Config:     ent.ConfigName,
+				ConfigHash: hash,
+				BetMode:    mode,
+				BetUnit:    bu,
+				Spins:      st.Summary.Rounds,
+				RTP:        st.Summary.RTP,
+				RtpLo:      st.Summary.RtpCI.Lo,
+				RtpHi:      st.Summary.RtpCI.Hi,
+				HitRate:    st.Summary.HitRate,
+				MaxWin:     r.maxWin(),
+				MaxWinX:    float64(r.maxWin()) / float64(bu),
+				ElapsedSec: used.Seconds(),
+				Partial:    !r.finished(cfg.spins),
+			}
+			rep.Rows = append(rep.Rows, row)
+			p.Fprintf(w, "[%d/%d] %s (gid %d) mode %d: rtp %.4f%%  %v\n", n, pairs, s.Name, s.GID, mode, 100*row.RTP, used.Round(time.Millisecond))
+			if ctx.Err() != nil {
+				break pairs
+			}
+		}
+	}
+	if ctx.Err() != nil {
+		if len(rep.Rows) == 0 {
+			log.Fatal("interrupted before the first spin: nothing to report")
+		}
+		done := 0
+		for _, row := range rep.Rows {
+			if !row.Partial {
+				done++
+			}
+		}
+		if done < pairs {
+			rep.Partial = &partialInfo{Done: done, Planned: pairs, Unit: "pairs"}
+		}
+	}
+	live.finish(rep.Partial != nil)
+
+	format, _ := cfg.outFormat()
+	if format == outText && cfg.outFile == "" {
+		stdOutBatch(os.Stdout, rep)
+		if rep.Partial != nil {
+			p.Printf("\033[1;31m%s\033[0m\n", rep.Partial)
+			os.Exit(exitInterrupted)
+		}
+		return
+	}
+	if err := writeReport(rep, format, cfg.outFile); err != nil {
+		log.Fatal(err)
+	}
+	if cfg.outFile != "" {
+		p.Printf("report written: %s (%s)\n", cfg.outFile, format)
+	}
+	if rep.Partial != nil {
+		p.Fprintf(os.Stderr, "%s\n", rep.Partial)
+		os.Exit(exitInterrupted)
+	}
+}
+
+// stdOutBatch prints the consolidated terminal table of a batch run.
+func stdOutBatch(out io.Writer, rep *batchReport) {
+	p := message.NewPrinter(language.English)
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "GID\tGAME\tMODE\tBET UNIT\tSPINS\tRTP\tRTP 95% CI\tHIT RATE\tMAX WIN (x)\tTIME\t")
+	for _, r := range rep.Rows {
+		game := r.Game
+		if r.Partial {
+			game += " (partial)"
+		}
+		p.Fprintf(tw, "%d\t%s\t%d\t%d\t%d\t%.4f%%\t[%.2f%%,%.2f%%]\t%.4f%%\t%.2f\t%v\t\n",
+			r.GameID, game, r.BetMode, r.BetUnit, r.Spins, 100*r.RTP, 100*r.RtpLo, 100*r.RtpHi, 100*r.HitRate, r.MaxWinX,
+			time.Duration(r.ElapsedSec*float64(time.Second)).Round(time.Millisecond))
+	}
+	tw.Flush()
+}
+
+// writeBatchCSV writes one row per (game, bet mode); unlike the single-run report the
+// columns are fixed, so a wide layout is easier to diff and load into a sheet.
+func writeBatchCSV(w io.Writer, rep *batchReport) error {
+	cw := csv.NewWriter(w)
+	f := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	i := strconv.Itoa
+	rows := [][]string{{"game_id", "game", "config", "config_sha256", "bet_mode", "bet_unit", "spins", "rtp", "rtp_ci95_lo", "rtp_ci95_hi", "hit_rate", "max_win", "max_win_x", "elapsed_sec", "seed", "workers", "partial"}}
+	for _, r := range rep.Rows {
+		rows = append(rows, []string{
+			fmt.Sprint(uint(r.GameID)), r.Game, r.Config, r.ConfigHash, i(r.BetMode), i(r.BetUnit), i(r.Spins),
+			f(r.RTP), f(r.RtpLo), f(r.RtpHi), f(r.HitRate), i(r.MaxWin), f(r.MaxWinX), f(r.ElapsedSec),
+			strconv.FormatInt(rep.Seed, 10), i(rep.Workers), strconv.FormatBool(r.Partial),
+		})
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
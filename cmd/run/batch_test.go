@@ -0,0 +1,141 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+)
+
+func TestRunAll(t *testing.T) {
+	lab, err := engine.New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	sums, err := lab.Summary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []string // game_id/bet_mode of every pair, in catalog order
+	for _, s := range sums {
+		for mode := range s.BetUnits {
+			want = append(want, fmt.Sprintf("%d/%d", s.GID, mode))
+		}
+	}
+	if len(sums) != 2 {
+		t.Fatalf("%d embedded games, want 2", len(sums))
+	}
+
+	saved := *cfg
+	t.Cleanup(func() { *cfg = saved })
+	path := filepath.Join(t.TempDir(), "all.csv")
+	*cfg = config{seed: 11, worker: 2, spins: 300, outFile: path}
+	runAll(context.Background(), lab)
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, row := range rows[1:] {
+		if row[6] != "600" || row[14] != "11" || row[15] != "2" || row[16] != "false" {
+			t.Errorf("row %v: want 600 spins, seed 11, 2 workers, not partial", row)
+		}
+		got = append(got, row[0]+"/"+row[4])
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("rows for %v, want one per (game, bet mode): %v", got, want)
+	}
+}
+
+func TestRunAllInterrupted(t *testing.T) {
+	if testing.Short() {
+		t.Skip("starts cmd/run processes")
+	}
+	sums, err := engine.MustNew().Summary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pairs := 0
+	for _, s := range sums {
+		pairs += len(s.BetUnits)
+	}
+	report := filepath.Join(t.TempDir(), "all.json")
+	for _, tt := range []struct {
+		name string
+		args []string
+	}{
+		{"text", nil},
+		{"json", []string{"-o", report}},
+	} {
+		// interrupt the first pair, long before it can finish its spins
+		args := append([]string{"-all", "-worker", "1", "-spins", "100000000"}, tt.args...)
+		cmd := runCommand(t, args...)
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cmd.Start(); err != nil {
+			t.Fatal(err)
+		}
+		sc := bufio.NewScanner(stdout)
+		for sc.Scan() && !strings.Contains(sc.Text(), "[ALL]") {
+		}
+		time.Sleep(300 * time.Millisecond)
+		cmd.Process.Signal(os.Interrupt)
+		rest, _ := io.ReadAll(stdout)
+		err = cmd.Wait()
+
+		var exit *exec.ExitError
+		if !errors.As(err, &exit) || exit.ExitCode() != exitInterrupted {
+			t.Fatalf("%s: %v, want exit status %d\n%s", tt.name, err, exitInterrupted, rest)
+		}
+		if tt.name == "text" {
+			if !strings.Contains(string(rest), "(partial)") || !strings.Contains(string(rest), fmt.Sprintf("PARTIAL REPORT: interrupted after 0 of %d pairs", pairs)) {
+				t.Fatalf("text report:\n%s", rest)
+			}
+			continue
+		}
+		raw, err := os.ReadFile(report)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rep := new(batchReport)
+		if err := json.Unmarshal(raw, rep); err != nil {
+			t.Fatal(err)
+		}
+		if len(rep.Rows) != 1 || !rep.Rows[0].Partial || rep.Rows[0].Spins == 0 || rep.Partial == nil || rep.Partial.Done != 0 || rep.Partial.Planned != pairs {
+			t.Fatalf("json report: %s", raw)
+		}
+	}
+}
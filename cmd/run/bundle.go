@@ -0,0 +1,294 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/zintix-labs/problab-scaffold/internal/bundle"
+	"github.com/zintix-labs/problab-scaffold/internal/configs"
+	"github.com/zintix-labs/problab-scaffold/internal/lint"
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// bundleCommands are the subcommands of `bundle`.
+var bundleCommands = map[string]func(args []string){
+	"keygen": runBundleKeygen,
+	"create": runBundleCreate,
+	"sign":   runBundleSign,
+	"verify": runBundleVerify,
+}
+
+// runBundle creates, signs and verifies config bundles: signed archives of configs that
+// the engine mounts over the embedded ones once they verify with its built-in public key
+// (see bundlePath and bundleKey in pkg/engine).
+func runBundle(args []string) {
+	if len(args) > 0 {
+		if cmd, ok := bundleCommands[args[0]]; ok {
+			cmd(args[1:])
+			return
+		}
+	}
+	fmt.Fprintln(os.Stderr, "usage: run bundle keygen|create|sign|verify [flags]")
+	os.Exit(2)
+}
+
+// runBundleKeygen writes a new signing key pair: <name>.key, the PEM private key (mode
+// 0600, never overwritten), and <name>.pub, the hex public key to build into the engine.
+func runBundleKeygen(args []string) {
+	var name string
+	fs := flag.NewFlagSet("bundle keygen", flag.ExitOnError)
+	fs.StringVar(&name, "o", "bundle", "write the key pair to <o>.key and <o>.pub")
+	fs.Parse(args)
+
+	pub, privPEM, err := bundle.GenerateKey(rand.Reader)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := writeNew(name+".key", privPEM, 0o600); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(name+".pub", []byte(hex.EncodeToString(pub)+"\n"), 0o644); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("private key : %s (keep it out of the repository)\n", name+".key")
+	fmt.Printf("public key  : %s\n", name+".pub")
+	fmt.Printf("\nset bundleKey in pkg/engine/problab.go to\n  %x\n", pub)
+}
+
+// runBundleCreate bundles a config directory. The configs must mount over the embedded
+// ones and lint without errors; with -key the bundle is signed, otherwise sign it with
+// `bundle sign`.
+func runBundleCreate(args []string) {
+	var dir, out, note, keyFile string
+	fs := flag.NewFlagSet("bundle create", flag.ExitOnError)
+	fs.StringVar(&dir, "dir", "", "config directory to bundle (required)")
+	fs.StringVar(&out, "o", "configs.bundle", "write the bundle to this file")
+	fs.StringVar(&note, "note", "", "note recorded in the manifest, e.g. the approval reference")
+	fs.StringVar(&keyFile, "key", "", "sign with this private key (from bundle keygen)")
+	fs.Parse(args)
+	if dir == "" {
+		log.Fatal("value err : -dir is required")
+	}
+	var key ed25519.PrivateKey
+	if keyFile != "" {
+		var err error
+		if key, err = readPrivateKey(keyFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := bundle.Create(&buf, os.DirFS(dir), note, time.Now(), key); err != nil {
+		log.Fatalf("%s: %v", dir, err)
+	}
+	b, err := bundle.Read(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := checkBundle(os.Stderr, b); err != nil {
+		log.Fatalf("%s not written: %v", out, err)
+	}
+	if err := os.WriteFile(out, buf.Bytes(), 0o644); err != nil {
+		log.Fatal(err)
+	}
+	stdOutBundle(os.Stdout, out, b)
+	if key == nil {
+		fmt.Printf("\n%s is not signed: run bundle sign -key <name>.key %s\n", out, out)
+	}
+}
+
+// runBundleSign signs a bundle in place, or into -o.
+func runBundleSign(args []string) {
+	var keyFile, out string
+	fs := flag.NewFlagSet("bundle sign", flag.ExitOnError)
+	fs.StringVar(&keyFile, "key", "", "private key (from bundle keygen, required)")
+	fs.StringVar(&out, "o", "", "write the signed bundle to this file (default: in place)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: run bundle sign -key <name>.key [-o out.bundle] configs.bundle")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if keyFile == "" || fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	in := fs.Arg(0)
+	if out == "" {
+		out = in
+	}
+	key, err := readPrivateKey(keyFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	raw, err := os.ReadFile(in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	signed, err := bundle.Sign(raw, key)
+	if err != nil {
+		log.Fatalf("%s: %v", in, err)
+	}
+	if err := os.WriteFile(out, signed, 0o644); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("signed %s with key %x\n", out, key.Public())
+}
+
+// runBundleVerify checks a bundle the way the engine does at start, then that its
+// configs mount over the embedded ones and lint without errors. It exits with status 1
+// on any failure.
+func runBundleVerify(args []string) {
+	var pubFlag string
+	fs := flag.NewFlagSet("bundle verify", flag.ExitOnError)
+	fs.StringVar(&pubFlag, "pub", "", "hex public key, or a .pub file (default: the key built into the engine)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: run bundle verify [-pub key] configs.bundle")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	pub, err := readPublicKey(pubFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	raw, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	b, err := bundle.Open(raw, pub)
+	if err == nil {
+		err = checkBundle(os.Stderr, b)
+	}
+	if err != nil {
+		fmt.Printf("FAIL: %s: %v\n", fs.Arg(0), err)
+		os.Exit(1)
+	}
+	stdOutBundle(os.Stdout, fs.Arg(0), b)
+	fmt.Printf("\nOK: %s verifies with key %x\n", fs.Arg(0), pub)
+}
+
+// checkBundle mounts the configs of b over the embedded ones as the engine does, and
+// lints the mounted configs, printing their errors to out.
+func checkBundle(out io.Writer, b *bundle.Bundle) error {
+	m, err := configs.Mount(
+		configs.Source{Name: "embedded", FS: configs.FS},
+		configs.Source{Name: "bundle", FS: b.FS(), Override: true},
+	)
+	if err != nil {
+		return err
+	}
+	diags, err := lint.Dir(m, "")
+	if err != nil {
+		return err
+	}
+	errs, _ := lint.Count(diags)
+	if errs == 0 {
+		return nil
+	}
+	for _, d := range diags {
+		if d.Severity == lint.Error {
+			fmt.Fprintln(out, d)
+		}
+	}
+	return fmt.Errorf("the mounted configs have %d lint errors", errs)
+}
+
+// readPrivateKey reads a private key written by bundle keygen.
+func readPrivateKey(name string) (ed25519.PrivateKey, error) {
+	raw, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	key, err := bundle.ParsePrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return key, nil
+}
+
+// readPublicKey parses s as a hex public key or reads it from the file s; an empty s is
+// the key built into the engine.
+func readPublicKey(s string) (ed25519.PublicKey, error) {
+	if s == "" {
+		if s = engine.BundleKey(); s == "" {
+			return nil, errors.New("value err : no public key built into the engine (bundleKey): set -pub")
+		}
+	}
+	if pub, err := bundle.ParsePublicKey(s); err == nil {
+		return pub, nil
+	}
+	raw, err := os.ReadFile(s)
+	if err != nil {
+		return nil, fmt.Errorf("value err : -pub is neither a hex public key nor a readable file: %w", err)
+	}
+	pub, err := bundle.ParsePublicKey(string(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s, err)
+	}
+	return pub, nil
+}
+
+// writeNew writes a file that must not exist yet.
+func writeNew(name string, data []byte, perm os.FileMode) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// stdOutBundle prints the manifest of the bundle name.
+func stdOutBundle(out io.Writer, name string, b *bundle.Bundle) {
+	p := message.NewPrinter(language.English)
+	m := b.Manifest
+	p.Fprintf(out, "bundle      : %s\n", name)
+	p.Fprintf(out, "created     : %s\n", m.Created.Format(time.RFC3339))
+	if m.Note != "" {
+		p.Fprintf(out, "note        : %s\n", m.Note)
+	}
+	signed := "no"
+	if b.Signature != nil {
+		signed = "yes"
+	}
+	p.Fprintf(out, "signed      : %s\n", signed)
+	p.Fprintf(out, "sha256      : %s\n\n", b.SHA256)
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CONFIG\tSIZE\tSHA256")
+	for _, f := range m.Files {
+		p.Fprintf(tw, "%s\t%d\t%s\n", f.Path, f.Size, f.SHA256)
+	}
+	tw.Flush()
+}
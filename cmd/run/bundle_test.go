@@ -0,0 +1,108 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/zintix-labs/problab-scaffold/internal/bundle"
+	"github.com/zintix-labs/problab-scaffold/internal/configs"
+)
+
+func TestCheckBundle(t *testing.T) {
+	raw, err := fs.ReadFile(configs.FS, "zintix/demo_0/demo_0.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := func(tree fstest.MapFS) *bundle.Bundle {
+		var buf bytes.Buffer
+		if _, err := bundle.Create(&buf, tree, "", time.Now(), nil); err != nil {
+			t.Fatal(err)
+		}
+		b, err := bundle.Read(buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	var out bytes.Buffer
+	if err := checkBundle(&out, read(fstest.MapFS{"demo_0.yaml": {Data: raw}})); err != nil {
+		t.Fatalf("%v\n%s", err, out.String())
+	}
+	out.Reset()
+	stdOutBundle(&out, "x.bundle", read(fstest.MapFS{"demo_0.yaml": {Data: raw}}))
+	if !strings.Contains(out.String(), "signed      : no") || !strings.Contains(out.String(), "demo_0.yaml  8,") {
+		t.Fatalf("bundle text:\n%s", out.String())
+	}
+
+	// a second game named like an embedded one does not mount
+	renamed := bytes.Replace(raw, []byte("game_id: 0"), []byte("game_id: 7"), 1)
+	err = checkBundle(&out, read(fstest.MapFS{"demo_7.yaml": {Data: renamed}}))
+	if err == nil || !strings.Contains(err.Error(), "demo_normal") {
+		t.Fatalf("duplicate game name: %v", err)
+	}
+
+	out.Reset()
+	broken := bytes.Replace(raw, []byte("gen_reel_type: GenReelByReelIdx"), []byte("gen_reel_type: GenReelByNothing"), 1)
+	err = checkBundle(&out, read(fstest.MapFS{"demo_0.yaml": {Data: broken}}))
+	if err == nil || !strings.Contains(err.Error(), "lint errors") || !strings.Contains(out.String(), "demo_0.yaml:") {
+		t.Fatalf("lint errors: %v\n%s", err, out.String())
+	}
+}
+
+func TestBundleKeys(t *testing.T) {
+	dir := t.TempDir()
+	pub, privPEM, err := bundle.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile, pubFile := filepath.Join(dir, "k.key"), filepath.Join(dir, "k.pub")
+	if err := writeNew(keyFile, privPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeNew(keyFile, privPEM, 0o600); err == nil {
+		t.Fatal("writeNew overwrites a key")
+	}
+	if fi, _ := os.Stat(keyFile); fi.Mode().Perm() != 0o600 {
+		t.Fatalf("key mode %v", fi.Mode())
+	}
+	os.WriteFile(pubFile, []byte(hex.EncodeToString(pub)+"\n"), 0o644)
+
+	priv, err := readPrivateKey(keyFile)
+	if err != nil || !pub.Equal(priv.Public()) {
+		t.Fatalf("readPrivateKey = %v", err)
+	}
+	for _, s := range []string{hex.EncodeToString(pub), pubFile} {
+		if got, err := readPublicKey(s); err != nil || !got.Equal(pub) {
+			t.Fatalf("readPublicKey(%s) = %x, %v", s, got, err)
+		}
+	}
+	if _, err := readPublicKey(keyFile); err == nil {
+		t.Fatal("a private key read as a public key")
+	}
+	if _, err := readPublicKey(filepath.Join(dir, "none")); err == nil {
+		t.Fatal("a missing file read as a public key")
+	}
+}
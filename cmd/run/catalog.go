@@ -0,0 +1,126 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+	"github.com/zintix-labs/problab/spec"
+)
+
+// catalogConfig holds the flags of the `catalog` subcommand.
+type catalogConfig struct {
+	id       spec.GID
+	resolved bool
+	out      string
+	outFile  string
+}
+
+// catalogGame is one game of the catalog.
+type catalogGame struct {
+	GameID   spec.GID      `json:"game_id"   yaml:"game_id"`
+	Name     string        `json:"name"      yaml:"name"`
+	Logic    spec.LogicKey `json:"logic"     yaml:"logic"`
+	BetUnits []int         `json:"bet_units" yaml:"bet_units"`
+	Config   string        `json:"config"    yaml:"config"`
+	SHA256   string        `json:"sha256"    yaml:"sha256"` // of the resolved config
+	Origin   string        `json:"origin"    yaml:"origin"`
+	Lineage  []string      `json:"lineage"   yaml:"lineage"` // origins from the config to its root base
+}
+
+// catalogReport is what `catalog` writes.
+type catalogReport struct {
+	Games []catalogGame `json:"games" yaml:"games"`
+}
+
+// runCatalog lists the mounted games: id, name, logic, config file, the digest of the
+// resolved config, and where it comes from. A variant lists its lineage, from itself to
+// its root base. With -resolved, it prints the resolved config of -game instead.
+func runCatalog(args []string) {
+	cc := new(catalogConfig)
+	fs := flag.NewFlagSet("catalog", flag.ExitOnError)
+	fs.Var(gidFlag{&cc.id}, "game", "target game id of -resolved")
+	fs.BoolVar(&cc.resolved, "resolved", false, "print the resolved config of -game")
+	fs.StringVar(&cc.out, "out", "", "report format: text|json|yaml (default text, or inferred from -o)")
+	fs.StringVar(&cc.outFile, "o", "", "write the report to this file instead of stdout")
+	fs.Parse(args)
+
+	lab := engine.MustNew()
+	if cc.resolved {
+		ent, ok := lab.EntryById(cc.id)
+		if !ok {
+			log.Fatalf("value err : game id not found: %d", cc.id)
+		}
+		raw, err := engine.ReadConfig(ent.ConfigName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		os.Stdout.Write(raw)
+		return
+	}
+
+	oc := &config{out: cc.out, outFile: cc.outFile}
+	format, err := oc.outFormat()
+	if err != nil || format == outCSV {
+		log.Fatal("value err : -out must be text, json or yaml")
+	}
+	sums, err := lab.Summary()
+	if err != nil {
+		log.Fatal(err)
+	}
+	rep := new(catalogReport)
+	for _, s := range sums {
+		ent, _ := lab.EntryById(s.GID)
+		info, ok := engine.Config(ent.ConfigName)
+		if !ok {
+			log.Fatalf("config not mounted: %s", ent.ConfigName)
+		}
+		hash, err := engine.ConfigSHA256(ent.ConfigName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		rep.Games = append(rep.Games, catalogGame{
+			GameID: s.GID, Name: s.Name, Logic: s.Logic, BetUnits: s.BetUnits,
+			Config: info.Name, SHA256: hash, Origin: info.Origin, Lineage: info.Lineage,
+		})
+	}
+
+	if format == outText && cc.outFile == "" {
+		stdOutCatalog(os.Stdout, rep)
+	} else if err := writeReport(rep, format, cc.outFile); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// stdOutCatalog prints the catalog in text mode, one game per row.
+func stdOutCatalog(out io.Writer, rep *catalogReport) {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "GID\tNAME\tLOGIC\tCONFIG\tSHA256\tSOURCE\tBASES")
+	for _, g := range rep.Games {
+		bases := "-"
+		if len(g.Lineage) > 1 {
+			bases = strings.Join(g.Lineage[1:], " <- ")
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%.12s\t%s\t%s\n", uint(g.GameID), g.Name, g.Logic, g.Config, g.SHA256, g.Origin, bases)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,42 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestStdOutCatalog(t *testing.T) {
+	rep := &catalogReport{Games: []catalogGame{
+		{GameID: 0, Name: "alpha", Logic: "demo_normal", Config: "alpha.yaml", SHA256: "0123456789abcdef",
+			Origin: "embedded:a/alpha.yaml", Lineage: []string{"embedded:a/alpha.yaml"}},
+		{GameID: 7, Name: "alpha_94", Logic: "demo_normal", Config: "alpha_94.yaml", SHA256: "fedcba9876543210",
+			Origin: "local:alpha_94.yaml", Lineage: []string{"local:alpha_94.yaml", "embedded:a/alpha_90.yaml", "embedded:a/alpha.yaml"}},
+	}}
+	var out bytes.Buffer
+	stdOutCatalog(&out, rep)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "GID") {
+		t.Fatalf("catalog table:\n%s", out.String())
+	}
+	if !strings.Contains(lines[1], "0123456789ab  embedded:a/alpha.yaml") || !strings.HasSuffix(lines[1], "-") {
+		t.Fatalf("base row %q", lines[1])
+	}
+	if !strings.HasSuffix(lines[2], "embedded:a/alpha_90.yaml <- embedded:a/alpha.yaml") {
+		t.Fatalf("variant row %q", lines[2])
+	}
+}
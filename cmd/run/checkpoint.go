@@ -0,0 +1,291 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/zintix-labs/problab"
+	"github.com/zintix-labs/problab-scaffold/internal/prng"
+	"github.com/zintix-labs/problab-scaffold/internal/simstat"
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+	"github.com/zintix-labs/problab/recorder"
+	"github.com/zintix-labs/problab/spec"
+	"github.com/zintix-labs/problab/stats"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// checkpointVersion is bumped whenever the file layout changes.
+const checkpointVersion = 2
+
+// checkpoint is everything needed to continue a machine simulation exactly where it stopped:
+// the run parameters, the accumulated statistics and the PRNG position of every worker.
+type checkpoint struct {
+	Version    int           `json:"version"`
+	Game       string        `json:"game"`
+	GameID     spec.GID      `json:"game_id"`
+	Config     string        `json:"config"`
+	ConfigHash string        `json:"config_sha256"`
+	Seed       int64         `json:"seed"`
+	PRNG       string        `json:"prng,omitempty"` // empty before PRNGs were selectable: pcg64
+	BetMode    int           `json:"bet_mode"`
+	Spins      int           `json:"spins"` // target spins per worker
+	Elapsed    time.Duration `json:"elapsed_ns"`
+	SavedAt    time.Time     `json:"saved_at"`
+	Workers    []workerState `json:"workers"`
+}
+
+// workerState is the resumable state of one runWorker.
+//
+// Only the machine core (PRNG) is restored; game logic keeps no state between spins.
+type workerState struct {
+	Seed            int64                `json:"seed"`
+	Done            int                  `json:"done"`
+	MaxWin          int                  `json:"max_win"`
+	Core            []byte               `json:"core"`
+	Basic           recorder.BasicRecord `json:"basic"`
+	TotalWinCollect []int                `json:"total_win_collect"`
+	BaseWinCollect  []int                `json:"base_win_collect"`
+	FreeWinCollect  []int                `json:"free_win_collect"`
+	Wins            [][2]int             `json:"wins"` // (win, spins) pairs
+	ModeWin         []int                `json:"mode_win"`
+	ModeSpins       []int                `json:"mode_spins"`
+}
+
+// snapshot captures the state of every worker. It must not be called while run is active.
+func (r *runner) snapshot() ([]workerState, error) {
+	ws := make([]workerState, len(r.workers))
+	for i, w := range r.workers {
+		s, err := w.state()
+		if err != nil {
+			return nil, err
+		}
+		ws[i] = s
+	}
+	return ws, nil
+}
+
+// restore loads worker states produced by snapshot into a runner built with the same
+// game, bet mode and seeds.
+func (r *runner) restore(ws []workerState) error {
+	if len(ws) != len(r.workers) {
+		return fmt.Errorf("checkpoint has %d workers, runner has %d", len(ws), len(r.workers))
+	}
+	for i, w := range r.workers {
+		if err := w.load(ws[i]); err != nil {
+			return fmt.Errorf("worker %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (w *runWorker) state() (workerState, error) {
+	core, err := w.m.SnapshotCore()
+	if err != nil {
+		return workerState{}, err
+	}
+	d := w.rec.Dist
+	return workerState{
+		Seed:            w.seed,
+		Done:            w.done,
+		MaxWin:          w.maxWin,
+		Core:            core,
+		Basic:           *w.rec.Basic,
+		TotalWinCollect: append([]int(nil), d.TotalWinCollect...),
+		BaseWinCollect:  append([]int(nil), d.BaseWinCollect...),
+		FreeWinCollect:  append([]int(nil), d.FreeWinCollect...),
+		Wins:            w.wins.Pairs(),
+		ModeWin:         append([]int(nil), w.modeWin...),
+		ModeSpins:       append([]int(nil), w.modeSpins...),
+	}, nil
+}
+
+func (w *runWorker) load(s workerState) error {
+	if s.Seed != w.seed {
+		return fmt.Errorf("state seed %d does not match %d", s.Seed, w.seed)
+	}
+	d := w.rec.Dist
+	if len(s.TotalWinCollect) != len(d.TotalWinCollect) || len(s.BaseWinCollect) != len(d.BaseWinCollect) || len(s.FreeWinCollect) != len(d.FreeWinCollect) {
+		return fmt.Errorf("state has a different win bucket layout")
+	}
+	if err := w.m.RestoreCore(s.Core); err != nil {
+		return err
+	}
+	*w.rec.Basic = s.Basic
+	copy(d.TotalWinCollect, s.TotalWinCollect)
+	copy(d.BaseWinCollect, s.BaseWinCollect)
+	copy(d.FreeWinCollect, s.FreeWinCollect)
+	w.done, w.maxWin = s.Done, s.MaxWin
+	w.wins = simstat.WinCountsFromPairs(s.Wins)
+	w.modeWin = append([]int(nil), s.ModeWin...)
+	w.modeSpins = append([]int(nil), s.ModeSpins...)
+	w.publish()
+	return nil
+}
+
+// newCheckpoint captures r, running `spins` spins per worker of the config with the
+// given hash. It must not be called while run is active.
+func newCheckpoint(r *runner, game, config, hash string, spins int) (*checkpoint, error) {
+	ws, err := r.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &checkpoint{
+		Version:    checkpointVersion,
+		Game:       game,
+		GameID:     r.gid,
+		Config:     config,
+		ConfigHash: hash,
+		Seed:       r.seed,
+		PRNG:       engine.PRNG(),
+		BetMode:    r.betMode,
+		Spins:      spins,
+		Elapsed:    r.elapsed,
+		Workers:    ws,
+	}, nil
+}
+
+func loadCheckpoint(path string) (*checkpoint, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cp := new(checkpoint)
+	if err := json.Unmarshal(raw, cp); err != nil {
+		return nil, fmt.Errorf("read checkpoint %s: %w", path, err)
+	}
+	if cp.Version != checkpointVersion {
+		return nil, fmt.Errorf("checkpoint %s has version %d, want %d", path, cp.Version, checkpointVersion)
+	}
+	return cp, nil
+}
+
+// save writes the checkpoint atomically (temp file + rename), so a crash while saving
+// never leaves a truncated file behind.
+func (cp *checkpoint) save(path string) error {
+	cp.SavedAt = time.Now().UTC()
+	raw, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(raw); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// useCheckpoint replaces the run parameters with the ones stored in cp, so a resumed run
+// is the same run regardless of the flags given on the command line.
+func (cfg *config) useCheckpoint(cp *checkpoint) {
+	cfg.id = cp.GameID
+	cfg.betMode = cp.BetMode
+	cfg.seed = cp.Seed
+	cfg.worker = len(cp.Workers)
+	cfg.spins = cp.Spins
+	if cfg.checkpoint == "" {
+		cfg.checkpoint = cfg.resume
+	}
+}
+
+// runCheckpointed runs a machine simulation that saves a checkpoint every
+// -checkpoint-every and when ctx is cancelled (SIGINT/SIGTERM), in which case it returns
+// the partial report. With cp != nil it continues that checkpoint.
+//
+// Workers pause at a spin boundary while saving, so the saved statistics and PRNG
+// positions always describe the same spins and the resumed run ends with the same
+// report as an uninterrupted one.
+func runCheckpointed(ctx context.Context, lab *problab.Problab, cp *checkpoint, w io.Writer) (*stats.StatReport, *runner, time.Duration, error) {
+	p := message.NewPrinter(language.English)
+	ent, _ := lab.EntryById(cfg.id)
+	hash, err := engine.ConfigSHA256(ent.ConfigName)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	r, err := newRunner(lab, cfg.id, cfg.betMode, cfg.seed, cfg.worker)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if cp != nil {
+		if cp.ConfigHash != hash {
+			return nil, nil, 0, fmt.Errorf("config %s changed since the checkpoint was written (sha256 %s, checkpoint %s)", ent.ConfigName, hash, cp.ConfigHash)
+		}
+		if p := cmp.Or(cp.PRNG, prng.PCG64.Name()); p != engine.PRNG() {
+			return nil, nil, 0, fmt.Errorf("the checkpoint was written with the %s PRNG, this binary is built with %s", p, engine.PRNG())
+		}
+		if err := r.restore(cp.Workers); err != nil {
+			return nil, nil, 0, err
+		}
+		r.elapsed = cp.Elapsed
+		p.Fprintf(w, "resumed %s at %d/%d spins\n", cfg.resume, r.spinsDone(), cfg.spins*cfg.worker)
+	}
+	live.plan("spins", cfg.worker*cfg.spins, nil)
+	live.track(ent.Name, cfg.id, cfg.betMode, cfg.worker*cfg.spins, r.counts)
+
+	save := func() error {
+		next, err := newCheckpoint(r, ent.Name, ent.ConfigName, hash, cfg.spins)
+		if err != nil {
+			return err
+		}
+		return next.save(cfg.checkpoint)
+	}
+
+	for {
+		seg, cancel := context.WithTimeout(ctx, cfg.checkpointEvery)
+		st, _, err := r.run(seg, cfg.spins)
+		cancel()
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		if err := save(); err != nil {
+			return nil, nil, 0, fmt.Errorf("save checkpoint: %w", err)
+		}
+		if r.finished(cfg.spins) {
+			p.Fprintf(w, "[checkpoint] %d/%d spins, run complete: %s\n", r.spinsDone(), cfg.spins*cfg.worker, cfg.checkpoint)
+			return st, r, r.elapsed, nil
+		}
+		p.Fprintf(w, "[checkpoint] %d/%d spins saved to %s\n", r.spinsDone(), cfg.spins*cfg.worker, cfg.checkpoint)
+		if ctx.Err() != nil {
+			p.Fprintf(os.Stderr, "interrupted, continue with: -resume %s\n", cfg.checkpoint)
+			return st, r, r.elapsed, nil
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+	"github.com/zintix-labs/problab/spec"
+)
+
+func TestCheckpointResumeMatchesUninterrupted(t *testing.T) {
+	lab, err := engine.New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	const (
+		gid     = spec.GID(1)
+		seed    = 2024
+		workers = 3
+		spins   = 3000
+		first   = 1100 // spins per worker before the stop
+	)
+	saved := *cfg
+	t.Cleanup(func() { *cfg = saved })
+
+	full, err := newRunner(lab, gid, 0, seed, workers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _, err := full.run(context.Background(), spins)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// stop after `first` spins, save and reload the checkpoint into a fresh runner
+	part, err := newRunner(lab, gid, 0, seed, workers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := part.run(context.Background(), first); err != nil {
+		t.Fatal(err)
+	}
+	ent, _ := lab.EntryById(gid)
+	hash, err := engine.ConfigSHA256(ent.ConfigName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp, err := newCheckpoint(part, ent.Name, ent.ConfigName, hash, spins)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "run.ckpt")
+	if err := cp.save(path); err != nil {
+		t.Fatal(err)
+	}
+	cp, err = loadCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.resume, cfg.checkpoint, cfg.checkpointEvery = path, "", time.Hour
+	cfg.useCheckpoint(cp)
+	if cfg.id != gid || cfg.seed != seed || cfg.worker != workers || cfg.spins != spins || cfg.checkpoint != path {
+		t.Fatalf("useCheckpoint: %+v", cfg)
+	}
+	got, r, _, err := runCheckpointed(context.Background(), lab, cp, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.spinsDone() != workers*spins || !r.finished(spins) {
+		t.Fatalf("resumed run stopped at %d spins", r.spinsDone())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("resumed report differs:\ngot  %+v\nwant %+v", got.Summary, want.Summary)
+	}
+	if gp, wp := r.wins().Pairs(), full.wins().Pairs(); !slices.Equal(gp, wp) {
+		t.Fatalf("resumed win counts differ:\ngot  %v\nwant %v", gp, wp)
+	}
+	if r.maxWin() != full.maxWin() {
+		t.Fatalf("max win %d, want %d", r.maxWin(), full.maxWin())
+	}
+
+	// a checkpoint of another config or PRNG is refused
+	for _, tt := range []struct {
+		name  string
+		edit  func(*checkpoint)
+		error string
+	}{
+		{"config", func(c *checkpoint) { c.ConfigHash = strings.Repeat("0", 64) }, "changed since the checkpoint"},
+		{"prng", func(c *checkpoint) { c.PRNG = "mt19937" }, "written with the mt19937 PRNG"},
+	} {
+		bad, err := loadCheckpoint(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tt.edit(bad)
+		if _, _, _, err := runCheckpointed(context.Background(), lab, bad, io.Discard); err == nil || !strings.Contains(err.Error(), tt.error) {
+			t.Errorf("%s changed: %v, want an error containing %q", tt.name, err, tt.error)
+		}
+	}
+}
@@ -0,0 +1,464 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cheggaaa/pb/v3"
+	"github.com/zintix-labs/problab"
+	"github.com/zintix-labs/problab-scaffold/internal/simstat"
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+	"github.com/zintix-labs/problab/sdk/buf"
+	"github.com/zintix-labs/problab/spec"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// armEmbedded selects the config of -game as mounted by the engine.
+const armEmbedded = "embedded"
+
+// compareConfig holds the flags of the `compare` subcommand.
+type compareConfig struct {
+	id         spec.GID
+	betMode    int
+	seed       int64
+	spins      int
+	worker     int
+	a, b       string
+	tails      string
+	tailEdges  []float64
+	confidence float64
+	out        string
+	outFile    string
+}
+
+// compareArm is one side of a comparison: the mounted config or a config file.
+type compareArm struct {
+	Source  string  `json:"source"   yaml:"source"` // "embedded:<config name>" or the file path
+	SHA256  string  `json:"sha256"   yaml:"sha256"`
+	Logic   string  `json:"logic"    yaml:"logic"`
+	BetUnit int     `json:"bet_unit" yaml:"bet_unit"`
+	MaxWin  int     `json:"max_win"  yaml:"max_win"`
+	MaxWinX float64 `json:"max_win_x" yaml:"max_win_x"`
+	raw     []byte  // nil for the mounted config
+	json    bool    // raw is JSON rather than YAML
+	gs      *spec.GameSetting
+}
+
+// compareRow is the paired result of one metric.
+type compareRow struct {
+	Metric        string `json:"metric" yaml:"metric"`
+	simstat.Delta `yaml:",inline"`
+	Significant   bool `json:"significant" yaml:"significant"` // P < 1-confidence
+}
+
+// compareReport is what `compare` writes.
+type compareReport struct {
+	Game       string       `json:"game"        yaml:"game"`
+	GameID     spec.GID     `json:"game_id"     yaml:"game_id"`
+	BetMode    int          `json:"bet_mode"    yaml:"bet_mode"`
+	Seed       int64        `json:"seed"        yaml:"seed"`
+	Workers    int          `json:"workers"     yaml:"workers"`
+	Spins      int          `json:"spins"       yaml:"spins"` // per arm
+	ElapsedSec float64      `json:"elapsed_sec" yaml:"elapsed_sec"`
+	Confidence float64      `json:"confidence"  yaml:"confidence"`
+	A          *compareArm  `json:"a"           yaml:"a"`
+	B          *compareArm  `json:"b"           yaml:"b"`
+	Metrics    []compareRow `json:"metrics"     yaml:"metrics"`
+	Partial    *partialInfo `json:"partial,omitempty" yaml:"partial,omitempty"`
+}
+
+// compareMetric is a per-spin value, in bet multiples or as 0/1, compared between arms.
+type compareMetric struct {
+	name  string
+	value func(sr *buf.SpinResult, bet int) float64
+}
+
+// compareMetrics returns the compared metrics: RTP and its base/free split, hit and
+// trigger rate, and the probability of a win of at least each tail edge.
+func compareMetrics(tails []float64) []compareMetric {
+	bit := func(ok bool) float64 {
+		if ok {
+			return 1
+		}
+		return 0
+	}
+	ms := []compareMetric{
+		{"rtp", func(sr *buf.SpinResult, bet int) float64 { return float64(sr.TotalWin) / float64(bet) }},
+		{"base_rtp", func(sr *buf.SpinResult, bet int) float64 { return float64(sr.GameModeList[0].TotalWin) / float64(bet) }},
+		{"free_rtp", func(sr *buf.SpinResult, bet int) float64 {
+			return float64(sr.TotalWin-sr.GameModeList[0].TotalWin) / float64(bet)
+		}},
+		{"hit_rate", func(sr *buf.SpinResult, bet int) float64 { return bit(sr.TotalWin > 0) }},
+		{"trigger_rate", func(sr *buf.SpinResult, bet int) float64 { return bit(sr.GameModeCount > 1) }},
+	}
+	for _, x := range tails {
+		ms = append(ms, compareMetric{
+			name:  "p(win>=" + strconv.FormatFloat(x, 'g', -1, 64) + "x)",
+			value: func(sr *buf.SpinResult, bet int) float64 { return bit(float64(sr.TotalWin) >= x*float64(bet)) },
+		})
+	}
+	return ms
+}
+
+// compareWorker spins arm A and arm B from the same seed, one spin each in lockstep.
+type compareWorker struct {
+	a, b       *problab.Machine
+	betA, betB int
+	done       int
+	maxA, maxB int
+	paired     []simstat.Paired
+}
+
+// runCompare simulates two variants of one game with common random numbers and prints
+// the per-metric differences with paired significance tests.
+//
+// An arm is either "embedded" (the config of -game as mounted by the engine) or a
+// .yaml/.json config file. Both arms are built by the same engine, so the PRNG and the
+// logic registry are the ones of this binary; to compare two logic versions, register
+// both and point each arm's logic_key at one of them. A file arm must keep the game_id
+// and game_name of -game.
+//
+// Worker k of both arms starts from the seed of worker k of a plain run, and the arms
+// spin in lockstep, so each pair of spins sees the same random numbers for as long as
+// the variants draw them the same way. The paired tests stay valid when the streams
+// drift apart (for example after a feature of different length); they only lose power.
+func runCompare(args []string) {
+	cc := new(compareConfig)
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	fs.Var(gidFlag{&cc.id}, "game", "target game id")
+	fs.IntVar(&cc.betMode, "mode", 0, "bet mode index")
+	fs.Int64Var(&cc.seed, "seed", -1, "int64 seed shared by both arms")
+	fs.IntVar(&cc.spins, "spins", 10000000, "spins per worker and arm")
+	fs.IntVar(&cc.worker, "worker", 1, "number of workers")
+	fs.StringVar(&cc.a, "a", armEmbedded, `arm A: "embedded" or a .yaml/.json config file`)
+	fs.StringVar(&cc.b, "b", "", `arm B: "embedded" or a .yaml/.json config file`)
+	fs.StringVar(&cc.tails, "tails", "10,100,1000", "compare P(win >= Nx) at these bet multiples")
+	fs.Float64Var(&cc.confidence, "confidence", 0.95, "confidence level of the intervals, in (0,1)")
+	fs.StringVar(&cc.out, "out", "", "report format: text|json|csv|yaml (default text, or inferred from -o)")
+	fs.StringVar(&cc.outFile, "o", "", "write the report to this file instead of stdout")
+	fs.Parse(args)
+
+	if cc.b == "" {
+		log.Fatal("value err : compare needs -b")
+	}
+	if cc.a == cc.b {
+		log.Fatal("value err : -a and -b are the same config")
+	}
+	if cc.spins < 1 || cc.worker < 1 {
+		log.Fatal("value err : spins and worker must > 0")
+	}
+	if cc.confidence <= 0 || cc.confidence >= 1 {
+		log.Fatal("value err : confidence must be in (0,1)")
+	}
+	var err error
+	if cc.tailEdges, err = simstat.ParseEdges(cc.tails); err != nil {
+		log.Fatal("value err : tails: " + err.Error())
+	}
+	oc := &config{out: cc.out, outFile: cc.outFile}
+	format, err := oc.outFormat()
+	if err != nil {
+		log.Fatal("value err : " + err.Error())
+	}
+	if cc.seed < 1 {
+		seed, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt64))
+		if err != nil {
+			log.Fatal(err)
+		}
+		cc.seed = seed.Int64()
+	}
+
+	lab := engine.MustNew()
+	ent, ok := lab.EntryById(cc.id)
+	if !ok {
+		log.Fatalf("value err : game id not found: %d", cc.id)
+	}
+	a, err := loadArm(cc.a, ent.ConfigName, cc.id, cc.betMode)
+	if err != nil {
+		log.Fatal("arm a: ", err)
+	}
+	b, err := loadArm(cc.b, ent.ConfigName, cc.id, cc.betMode)
+	if err != nil {
+		log.Fatal("arm b: ", err)
+	}
+
+	ctx, stop := interruptible()
+	defer stop()
+	p := message.NewPrinter(language.English)
+	w := oc.msgOut()
+	p.Fprintf(w, "\033[1;32m[COMPARE] [WORKERS:%d] [GAME:%s] [PLAYMODE:%d] [SPINS:%d x 2]\033[0m\n", cc.worker, ent.Name, cc.betMode, cc.worker*cc.spins)
+	rep, err := cc.run(ctx, lab, a, b, format == outText || cc.outFile != "")
+	if err != nil {
+		log.Fatal(err)
+	}
+	rep.Game = ent.Name
+
+	if format == outText && cc.outFile == "" {
+		stdOutCompare(os.Stdout, rep)
+	} else if err := writeReport(rep, format, cc.outFile); err != nil {
+		log.Fatal(err)
+	}
+	if rep.Partial != nil {
+		stop()
+		os.Exit(exitInterrupted)
+	}
+}
+
+// loadArm resolves an arm spec and checks that it is a variant of game id.
+func loadArm(src, mounted string, id spec.GID, betMode int) (*compareArm, error) {
+	arm := &compareArm{Source: src}
+	var (
+		raw []byte
+		gs  *spec.GameSetting
+		err error
+	)
+	if src == armEmbedded {
+		arm.Source = armEmbedded + ":" + mounted
+		if raw, err = engine.ReadConfig(mounted); err != nil {
+			return nil, err
+		}
+		gs, err = engine.GameSetting(mounted)
+	} else {
+		if raw, err = os.ReadFile(src); err != nil {
+			return nil, err
+		}
+		arm.raw = raw
+		if arm.json = strings.EqualFold(filepath.Ext(src), ".json"); arm.json {
+			gs, err = spec.GetGameSettingByJSON(raw)
+		} else {
+			gs, err = spec.GetGameSettingByYAML(raw)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if spec.GID(gs.GameID) != id {
+		return nil, fmt.Errorf("%s is game %d, not -game %d", src, gs.GameID, id)
+	}
+	if betMode < 0 || betMode >= len(gs.BetUnits) {
+		return nil, fmt.Errorf("bet mode err: must >= 0 and < len(betunits)")
+	}
+	sum := sha256.Sum256(raw)
+	arm.SHA256 = hex.EncodeToString(sum[:])
+	arm.Logic = string(gs.LogicKey)
+	arm.BetUnit = gs.BetUnits[betMode]
+	arm.gs = gs
+	return arm, nil
+}
+
+// machine builds a simulation machine of the arm.
+func (arm *compareArm) machine(lab *problab.Problab, id spec.GID, seed int64) (*problab.Machine, error) {
+	switch {
+	case arm.raw == nil:
+		return lab.NewMachineWithSeed(id, seed, true)
+	case arm.json:
+		return lab.NewMachineByJSON(arm.raw, seed)
+	default:
+		return lab.NewMachineByYAML(arm.raw, seed)
+	}
+}
+
+// run spins both arms on -worker common streams and builds the report. When ctx is
+// cancelled the workers stop at the next spin boundary and the report is marked partial.
+func (cc *compareConfig) run(ctx context.Context, lab *problab.Problab, a, b *compareArm, showpb bool) (*compareReport, error) {
+	metrics := compareMetrics(cc.tailEdges)
+	seeds := shardSeeds(cc.seed, cc.worker)
+	workers := make([]*compareWorker, len(seeds))
+	for i, seed := range seeds {
+		ma, err := a.machine(lab, cc.id, seed)
+		if err != nil {
+			return nil, fmt.Errorf("arm a: %w", err)
+		}
+		mb, err := b.machine(lab, cc.id, seed)
+		if err != nil {
+			return nil, fmt.Errorf("arm b: %w", err)
+		}
+		workers[i] = &compareWorker{a: ma, b: mb, betA: a.BetUnit, betB: b.BetUnit, paired: make([]simstat.Paired, len(metrics))}
+	}
+
+	bar := pb.New(cc.spins * len(workers))
+	bar.Set(pb.CleanOnFinish, true)
+	if !showpb {
+		bar.SetWriter(io.Discard)
+	}
+	bar.Start()
+	wg := new(sync.WaitGroup)
+	wg.Add(len(workers))
+	for _, w := range workers {
+		go func() {
+			defer wg.Done()
+			from := 0
+			for ; w.done < cc.spins; w.done++ {
+				if w.done%stopCheck == 0 {
+					if ctx.Err() != nil {
+						break
+					}
+					bar.Add(w.done - from)
+					from = w.done
+				}
+				ra := w.a.SpinInternal(cc.betMode)
+				rb := w.b.SpinInternal(cc.betMode)
+				w.maxA, w.maxB = max(w.maxA, ra.TotalWin), max(w.maxB, rb.TotalWin)
+				for i, m := range metrics {
+					w.paired[i].Add(m.value(ra, w.betA), m.value(rb, w.betB))
+				}
+			}
+			bar.Add(w.done - from)
+		}()
+	}
+	wg.Wait()
+	used := time.Since(bar.StartTime())
+	bar.Finish()
+
+	rep := &compareReport{
+		GameID:     cc.id,
+		BetMode:    cc.betMode,
+		Seed:       cc.seed,
+		Workers:    len(workers),
+		ElapsedSec: used.Seconds(),
+		Confidence: cc.confidence,
+		A:          a,
+		B:          b,
+	}
+	all := make([]simstat.Paired, len(metrics))
+	for _, w := range workers {
+		rep.Spins += w.done
+		a.MaxWin, b.MaxWin = max(a.MaxWin, w.maxA), max(b.MaxWin, w.maxB)
+		for i := range all {
+			all[i].Merge(&w.paired[i])
+		}
+	}
+	if rep.Spins == 0 {
+		return nil, fmt.Errorf("interrupted before the first spin: nothing to report")
+	}
+	a.MaxWinX = float64(a.MaxWin) / float64(a.BetUnit)
+	b.MaxWinX = float64(b.MaxWin) / float64(b.BetUnit)
+	for i, m := range metrics {
+		d := all[i].Delta(cc.confidence)
+		rep.Metrics = append(rep.Metrics, compareRow{Metric: m.name, Delta: d, Significant: d.P < 1-cc.confidence})
+	}
+	if planned := cc.spins * len(workers); rep.Spins < planned {
+		rep.Partial = &partialInfo{Done: rep.Spins, Planned: planned, Unit: "spins"}
+	}
+	return rep, nil
+}
+
+// stdOutCompare prints the comparison in text mode.
+func stdOutCompare(out io.Writer, rep *compareReport) {
+	p := message.NewPrinter(language.English)
+	if rep.Partial != nil {
+		p.Fprintf(out, "\033[1;31m%s\033[0m\n", rep.Partial)
+	}
+	for _, arm := range []struct {
+		name string
+		*compareArm
+	}{{"a", rep.A}, {"b", rep.B}} {
+		p.Fprintf(out, "arm %s            : %s (sha256 %.12s, logic %s, bet %d, max win %.2fx)\n", arm.name, arm.Source, arm.SHA256, arm.Logic, arm.BetUnit, arm.MaxWinX)
+	}
+	p.Fprintf(out, "spins per arm    : %d (seed %d, %d common streams, %.1fs)\n", rep.Spins, rep.Seed, rep.Workers, rep.ElapsedSec)
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "METRIC\tA\tB\tB-A\tCI%g LO\tCI%g HI\tP\tCRN GAIN\t\t\n", 100*rep.Confidence, 100*rep.Confidence)
+	for _, m := range rep.Metrics {
+		sig, gain := "", "-"
+		if m.Significant {
+			sig = "*"
+		}
+		if m.Gain > 0 {
+			gain = p.Sprintf("%.1fx", m.Gain)
+		}
+		p.Fprintf(tw, "%s\t%.5f%%\t%.5f%%\t%+.5f%%\t%+.5f%%\t%+.5f%%\t%.4g\t%s\t%s\t\n",
+			m.Metric, 100*m.A, 100*m.B, 100*m.Delta.Delta, 100*m.Lo, 100*m.Hi, m.P, gain, sig)
+	}
+	tw.Flush()
+	p.Fprintf(out, "* significant at %g%%; CRN GAIN = spins two independent runs would need for the same precision, relative to this run\n", 100*rep.Confidence)
+	if rep.Partial != nil {
+		p.Fprintf(out, "\033[1;31m%s\033[0m\n", rep.Partial)
+	}
+}
+
+// writeCompareCSV writes the comparison in long format (section,key,value).
+func writeCompareCSV(w io.Writer, r *compareReport) error {
+	cw := csv.NewWriter(w)
+	f := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	i := strconv.Itoa
+	rows := [][]string{
+		{"section", "key", "value"},
+		{"run", "game", r.Game},
+		{"run", "game_id", fmt.Sprint(uint(r.GameID))},
+		{"run", "bet_mode", i(r.BetMode)},
+		{"run", "seed", strconv.FormatInt(r.Seed, 10)},
+		{"run", "workers", i(r.Workers)},
+		{"run", "spins", i(r.Spins)},
+		{"run", "elapsed_sec", f(r.ElapsedSec)},
+		{"run", "confidence", f(r.Confidence)},
+	}
+	if pt := r.Partial; pt != nil {
+		rows = append(rows,
+			[]string{"partial", "done", i(pt.Done)},
+			[]string{"partial", "planned", i(pt.Planned)},
+			[]string{"partial", "unit", pt.Unit},
+		)
+	}
+	for _, arm := range []struct {
+		name string
+		*compareArm
+	}{{"a", r.A}, {"b", r.B}} {
+		rows = append(rows,
+			[]string{"arm", arm.name + ".source", arm.Source},
+			[]string{"arm", arm.name + ".sha256", arm.SHA256},
+			[]string{"arm", arm.name + ".logic", arm.Logic},
+			[]string{"arm", arm.name + ".bet_unit", i(arm.BetUnit)},
+			[]string{"arm", arm.name + ".max_win", i(arm.MaxWin)},
+			[]string{"arm", arm.name + ".max_win_x", f(arm.MaxWinX)},
+		)
+	}
+	for _, m := range r.Metrics {
+		rows = append(rows,
+			[]string{"metric", m.Metric + ".a", f(m.A)},
+			[]string{"metric", m.Metric + ".b", f(m.B)},
+			[]string{"metric", m.Metric + ".delta", f(m.Delta.Delta)},
+			[]string{"metric", m.Metric + ".se", f(m.SE)},
+			[]string{"metric", m.Metric + ".lo", f(m.Lo)},
+			[]string{"metric", m.Metric + ".hi", f(m.Hi)},
+			[]string{"metric", m.Metric + ".z", f(m.Z)},
+			[]string{"metric", m.Metric + ".p", f(m.P)},
+			[]string{"metric", m.Metric + ".gain", f(m.Gain)},
+			[]string{"metric", m.Metric + ".significant", strconv.FormatBool(m.Significant)},
+		)
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+	"github.com/zintix-labs/problab/spec"
+)
+
+func TestCompareCommonRandomNumbers(t *testing.T) {
+	lab, err := engine.New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	raw, err := engine.ReadConfig("demo_0.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	same := filepath.Join(dir, "same.yaml")
+	richer := filepath.Join(dir, "richer.yaml")
+	os.WriteFile(same, raw, 0o644)
+	os.WriteFile(richer, bytes.ReplaceAll(raw, []byte("[0, 0, 30, 240, 360]"), []byte("[0, 0, 36, 240, 360]")), 0o644)
+
+	cc := &compareConfig{id: spec.GID(0), seed: 42, spins: 20000, worker: 2, tailEdges: []float64{10}, confidence: 0.95}
+	compare := func(b string) *compareReport {
+		armA, err := loadArm(armEmbedded, "demo_0.yaml", cc.id, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		armB, err := loadArm(b, "demo_0.yaml", cc.id, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rep, err := cc.run(context.Background(), lab, armA, armB, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rep
+	}
+
+	// a byte-identical file draws the same spins: every delta is exactly zero
+	rep := compare(same)
+	for _, m := range rep.Metrics {
+		if m.Delta.Delta != 0 || m.Significant {
+			t.Fatalf("identical arms differ on %s: %+v", m.Metric, m)
+		}
+	}
+	if rep.A.SHA256 != rep.B.SHA256 || rep.Spins != 40000 || rep.Partial != nil {
+		t.Fatalf("report %+v", rep)
+	}
+
+	// arm A reproduces a plain run with the same seed and workers
+	r, err := newRunner(lab, cc.id, 0, cc.seed, cc.worker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, _, err := r.run(context.Background(), cc.spins)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rep.Metrics[0].A; got < st.Rtp()-1e-9 || got > st.Rtp()+1e-9 {
+		t.Fatalf("arm a rtp %v, plain run %v", got, st.Rtp())
+	}
+
+	// a higher free-game pay is detected on 40k spins per arm
+	rep = compare(richer)
+	if m := rep.Metrics[0]; m.Metric != "rtp" || m.Delta.Delta <= 0 || !m.Significant {
+		t.Fatalf("richer pay table: %+v", m)
+	}
+
+	if _, err := loadArm(same, "demo_0.yaml", spec.GID(1), 0); err == nil {
+		t.Fatal("a demo_0 file should not load as game 1")
+	}
+}
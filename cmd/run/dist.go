@@ -0,0 +1,516 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zintix-labs/problab"
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+	"github.com/zintix-labs/problab/spec"
+	"github.com/zintix-labs/problab/stats"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// Distributed simulation
+//
+// A coordinator (-coordinator :5809) splits one machine simulation into -shards seeded
+// shards of -spins spins each. Worker processes (-join http://host:5809) lease shards over
+// HTTP, run them and post back the raw counters, which the coordinator merges into one
+// report.
+//
+// Shard i uses stream i of shardSeeds(-seed), the same streams as `SimMP` workers, so a
+// distributed run with N shards gives exactly the report of `-worker N` on one machine,
+// and any single shard can be re-run on its own for auditing.
+//
+// The coordinator merges whatever counters a lease holder posts, so it trusts every
+// client that can reach it. It listens on loopback unless given a host, and on any other
+// interface it requires -token: workers send it as a bearer token on every request.
+
+// Coordinator routes.
+const (
+	routeLease  = "/v1/shards/lease"
+	routeResult = "/v1/shards/{id}"
+)
+
+// shardTokenEnv is read for -token when the flag is not set, keeping the token out of
+// the process list.
+const shardTokenEnv = "PROBLAB_SHARD_TOKEN"
+
+// shardTask is one unit of work handed to a worker process.
+type shardTask struct {
+	ID         int      `json:"id"`
+	GameID     spec.GID `json:"game_id"`
+	ConfigHash string   `json:"config_sha256"`
+	PRNG       string   `json:"prng"`
+	BetMode    int      `json:"bet_mode"`
+	Seed       int64    `json:"seed"`
+	Spins      int      `json:"spins"`
+}
+
+// shardResult is the finished state of one shard, posted back by a worker.
+type shardResult struct {
+	ID      int           `json:"id"`
+	Elapsed time.Duration `json:"elapsed_ns"`
+	State   workerState   `json:"state"`
+}
+
+// coordinator hands out shards and collects their results.
+//
+// A leased shard that is not reported back within lease is handed out again, so a
+// worker that dies only costs time. Results are deterministic, so a late duplicate
+// result for an already finished shard is simply ignored.
+type coordinator struct {
+	mu      sync.Mutex
+	tasks   []shardTask
+	layout  stateLayout // of every result
+	leased  []time.Time // zero: never leased
+	results []*shardResult
+	left    int
+	closed  bool // stopped before every shard was reported
+	lease   time.Duration
+	token   string // required bearer token; empty accepts any client
+	done    chan struct{}
+}
+
+func newCoordinator(gid spec.GID, hash string, layout stateLayout, betMode int, seed int64, shards int, spins int, lease time.Duration) *coordinator {
+	c := &coordinator{
+		tasks:   make([]shardTask, shards),
+		layout:  layout,
+		leased:  make([]time.Time, shards),
+		results: make([]*shardResult, shards),
+		left:    shards,
+		lease:   lease,
+		done:    make(chan struct{}),
+	}
+	for i, s := range shardSeeds(seed, shards) {
+		c.tasks[i] = shardTask{ID: i, GameID: gid, ConfigHash: hash, PRNG: engine.PRNG(), BetMode: betMode, Seed: s, Spins: spins}
+	}
+	return c
+}
+
+// next leases a shard. ok is false when every unfinished shard is currently leased;
+// finished is true once all results are in.
+func (c *coordinator) next(now time.Time) (t shardTask, ok bool, finished bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.left == 0 || c.closed {
+		return shardTask{}, false, true
+	}
+	for i := range c.tasks {
+		if c.results[i] != nil {
+			continue
+		}
+		if c.leased[i].IsZero() || now.Sub(c.leased[i]) > c.lease {
+			c.leased[i] = now
+			return c.tasks[i], true, false
+		}
+	}
+	return shardTask{}, false, false
+}
+
+// stateLayout is the shape of the workerState of a game and bet mode: the lengths of its
+// win buckets and the number of game modes its mode counters may cover.
+type stateLayout struct {
+	total, base, free, modes int
+}
+
+// layoutOf reads the layout of a worker of game gid built locally.
+func layoutOf(lab *problab.Problab, gid spec.GID, betMode int) (stateLayout, error) {
+	ent, ok := lab.EntryById(gid)
+	if !ok {
+		return stateLayout{}, fmt.Errorf("game id not found: %d", gid)
+	}
+	gs, err := engine.GameSetting(ent.ConfigName)
+	if err != nil {
+		return stateLayout{}, err
+	}
+	r, err := newRunnerSeeds(lab, gid, betMode, []int64{1})
+	if err != nil {
+		return stateLayout{}, err
+	}
+	d := r.workers[0].rec.Dist
+	return stateLayout{len(d.TotalWinCollect), len(d.BaseWinCollect), len(d.FreeWinCollect), len(gs.GameModeSettings)}, nil
+}
+
+// check rejects a state of another layout, such as a truncated result.
+func (l stateLayout) check(s *workerState) error {
+	if len(s.TotalWinCollect) != l.total || len(s.BaseWinCollect) != l.base || len(s.FreeWinCollect) != l.free {
+		return fmt.Errorf("win buckets (%d, %d, %d), want (%d, %d, %d)", len(s.TotalWinCollect), len(s.BaseWinCollect), len(s.FreeWinCollect), l.total, l.base, l.free)
+	}
+	if len(s.ModeWin) > l.modes || len(s.ModeSpins) != len(s.ModeWin) {
+		return fmt.Errorf("mode counters (%d wins, %d spins) for %d game modes", len(s.ModeWin), len(s.ModeSpins), l.modes)
+	}
+	return nil
+}
+
+// complete records a shard result after checking it belongs to the issued task.
+func (c *coordinator) complete(res *shardResult) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if res.ID < 0 || res.ID >= len(c.tasks) {
+		return fmt.Errorf("unknown shard %d", res.ID)
+	}
+	t := c.tasks[res.ID]
+	if res.State.Seed != t.Seed || res.State.Done != t.Spins {
+		return fmt.Errorf("shard %d: result (seed %d, spins %d) does not match task (seed %d, spins %d)", t.ID, res.State.Seed, res.State.Done, t.Seed, t.Spins)
+	}
+	if err := c.layout.check(&res.State); err != nil {
+		return fmt.Errorf("shard %d: result has %w", t.ID, err)
+	}
+	if c.results[res.ID] != nil {
+		return nil
+	}
+	c.results[res.ID] = res
+	c.left--
+	if c.left == 0 {
+		close(c.done)
+	}
+	return nil
+}
+
+func (c *coordinator) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+routeLease, func(w http.ResponseWriter, r *http.Request) {
+		t, ok, finished := c.next(time.Now())
+		switch {
+		case finished:
+			w.WriteHeader(http.StatusGone)
+		case !ok:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(t)
+		}
+	})
+	mux.HandleFunc("POST "+routeResult, func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "bad shard id", http.StatusBadRequest)
+			return
+		}
+		res := new(shardResult)
+		if err := json.NewDecoder(r.Body).Decode(res); err != nil || res.ID != id {
+			http.Error(w, "bad shard result", http.StatusBadRequest)
+			return
+		}
+		if err := c.complete(res); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	if c.token == "" {
+		return mux
+	}
+	want := []byte("Bearer " + c.token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			http.Error(w, "missing or wrong shard token", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// close stops handing out shards; workers polling for work are told the run is over.
+func (c *coordinator) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+}
+
+// reported is the number of shards reported so far.
+func (c *coordinator) reported() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.tasks) - c.left
+}
+
+// counts sums the totals of the reported shards.
+func (c *coordinator) counts() progressCounts {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var pc progressCounts
+	for _, res := range c.results {
+		if res == nil {
+			continue
+		}
+		b := res.State.Basic
+		pc.add(progressCounts{
+			spins:    b.Rounds,
+			bet:      b.TotalBet,
+			win:      b.TotalWin,
+			hits:     b.Rounds - res.State.TotalWinCollect[0],
+			triggers: b.Trigger,
+			modeWin:  res.State.ModeWin,
+		})
+	}
+	return pc
+}
+
+// merge rebuilds one runner stream per reported shard from the posted counters and
+// merges them. After an interrupt only the shards reported so far are merged.
+func (c *coordinator) merge(lab *problab.Problab) (*stats.StatReport, *runner, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var (
+		seeds []int64
+		ws    []workerState
+	)
+	for i, res := range c.results {
+		if res == nil {
+			continue
+		}
+		seeds = append(seeds, c.tasks[i].Seed)
+		ws = append(ws, res.State)
+	}
+	if len(ws) == 0 {
+		return nil, nil, fmt.Errorf("no shard was reported")
+	}
+	r, err := newRunnerSeeds(lab, c.tasks[0].GameID, c.tasks[0].BetMode, seeds)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := r.restore(ws); err != nil {
+		return nil, nil, err
+	}
+	st, err := r.report()
+	return st, r, err
+}
+
+// runCoordinator serves shards on -coordinator until every shard is reported (or ctx is
+// cancelled), then returns the merged report and the wall time of the distributed run.
+func runCoordinator(ctx context.Context, lab *problab.Problab, w io.Writer) (*stats.StatReport, *runner, time.Duration, error) {
+	p := message.NewPrinter(language.English)
+	ent, _ := lab.EntryById(cfg.id)
+	hash, err := engine.ConfigSHA256(ent.ConfigName)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	layout, err := layoutOf(lab, cfg.id, cfg.betMode)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	c := newCoordinator(cfg.id, hash, layout, cfg.betMode, cfg.seed, cfg.shards, cfg.spins, cfg.lease)
+	c.token = cfg.token
+	live.plan("shards", cfg.shards, c.reported)
+	live.track(ent.Name, cfg.id, cfg.betMode, cfg.shards*cfg.spins, c.counts)
+
+	ln, err := net.Listen("tcp", cfg.coordinator)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	svr := &http.Server{Handler: c.handler(), ReadHeaderTimeout: 10 * time.Second}
+	go svr.Serve(ln)
+	p.Fprintf(w, "coordinator listening on %s, waiting for workers (-join http://<host>:%s)\n", ln.Addr(), strconv.Itoa(ln.Addr().(*net.TCPAddr).Port))
+
+	start := time.Now()
+	tick := time.NewTicker(5 * time.Second)
+	defer tick.Stop()
+wait:
+	for {
+		select {
+		case <-c.done:
+			break wait
+		case <-ctx.Done():
+			c.close()
+			break wait
+		case <-tick.C:
+			c.mu.Lock()
+			left := c.left
+			c.mu.Unlock()
+			p.Fprintf(w, "[coordinator] %d/%d shards done\n", cfg.shards-left, cfg.shards)
+		}
+	}
+	used := time.Since(start)
+
+	// keep answering 410 for a moment so polling workers learn the run is over
+	time.Sleep(2 * time.Second)
+	sctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	svr.Shutdown(sctx)
+
+	st, r, err := c.merge(lab)
+	return st, r, used, err
+}
+
+// runShardWorker leases and runs shards from -join until the coordinator reports the
+// run finished. -worker shards are processed concurrently.
+//
+// Cancelling ctx stops leasing shards and drops the ones in flight: they are not
+// reported, so the coordinator leases them again once their lease expires.
+func runShardWorker(ctx context.Context, lab *problab.Problab, w io.Writer) error {
+	p := message.NewPrinter(language.English)
+	client := &http.Client{Timeout: 30 * time.Second}
+	errc := make(chan error, cfg.worker)
+	for range cfg.worker {
+		go func() { errc <- shardLoop(ctx, lab, client, p, w) }()
+	}
+	var errs []error
+	for range cfg.worker {
+		errs = append(errs, <-errc)
+	}
+	return errors.Join(errs...)
+}
+
+func shardLoop(ctx context.Context, lab *problab.Problab, client *http.Client, p *message.Printer, w io.Writer) error {
+	wait := time.Now().Add(cfg.joinWait)
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+		req, err := newShardRequest(ctx, cfg.join+routeLease, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			// coordinator not up yet (or briefly unreachable): retry until -join-wait
+			if ctx.Err() != nil {
+				return nil
+			}
+			if time.Now().After(wait) {
+				return err
+			}
+			sleepCtx(ctx, time.Second)
+			continue
+		}
+		wait = time.Now().Add(cfg.joinWait)
+		switch resp.StatusCode {
+		case http.StatusGone:
+			resp.Body.Close()
+			return nil
+		case http.StatusNoContent:
+			resp.Body.Close()
+			sleepCtx(ctx, time.Second)
+			continue
+		case http.StatusOK:
+		default:
+			resp.Body.Close()
+			return fmt.Errorf("lease shard: %s", resp.Status)
+		}
+		t := new(shardTask)
+		err = json.NewDecoder(resp.Body).Decode(t)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("lease shard: %w", err)
+		}
+
+		res, st, err := runShard(ctx, lab, t)
+		if errors.Is(err, context.Canceled) {
+			p.Fprintf(w, "[shard %d] interrupted: dropped, the coordinator leases it again\n", t.ID)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("shard %d: %w", t.ID, err)
+		}
+		if err := postResult(client, res); err != nil {
+			return fmt.Errorf("shard %d: %w", t.ID, err)
+		}
+		p.Fprintf(w, "[shard %d] %d spins  rtp %.4f%%  %v\n", t.ID, t.Spins, 100*st.Summary.RTP, res.Elapsed.Round(time.Millisecond))
+	}
+}
+
+// sleepCtx sleeps for d or until ctx is cancelled.
+func sleepCtx(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
+
+// runShard runs one task locally. It refuses tasks built from a different config or PRNG,
+// and returns ctx.Err() when ctx is cancelled before the shard is finished.
+func runShard(ctx context.Context, lab *problab.Problab, t *shardTask) (*shardResult, *stats.StatReport, error) {
+	ent, ok := lab.EntryById(t.GameID)
+	if !ok {
+		return nil, nil, fmt.Errorf("game id not found: %d", t.GameID)
+	}
+	hash, err := engine.ConfigSHA256(ent.ConfigName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if hash != t.ConfigHash {
+		return nil, nil, fmt.Errorf("config %s differs from the coordinator (sha256 %s, coordinator %s)", ent.ConfigName, hash, t.ConfigHash)
+	}
+	if t.PRNG != engine.PRNG() {
+		return nil, nil, fmt.Errorf("the coordinator uses the %s PRNG, this binary is built with %s", t.PRNG, engine.PRNG())
+	}
+	r, err := newRunnerSeeds(lab, t.GameID, t.BetMode, []int64{t.Seed})
+	if err != nil {
+		return nil, nil, err
+	}
+	st, used, err := r.run(ctx, t.Spins)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !r.finished(t.Spins) {
+		return nil, nil, ctx.Err()
+	}
+	ws, err := r.snapshot()
+	if err != nil {
+		return nil, nil, err
+	}
+	return &shardResult{ID: t.ID, Elapsed: used, State: ws[0]}, st, nil
+}
+
+// newShardRequest builds a POST to the coordinator, carrying -token when set.
+func newShardRequest(ctx context.Context, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if cfg.token != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.token)
+	}
+	return req, nil
+}
+
+func postResult(client *http.Client, res *shardResult) error {
+	raw, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	req, err := newShardRequest(context.Background(), cfg.join+"/v1/shards/"+strconv.Itoa(res.ID), bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("post result: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
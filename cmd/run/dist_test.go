@@ -0,0 +1,370 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+	"github.com/zintix-labs/problab/spec"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+func TestDistributedMatchesLocal(t *testing.T) {
+	lab, err := engine.New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	const (
+		gid    = spec.GID(0)
+		seed   = 99
+		shards = 5
+		spins  = 4000
+	)
+	ent, _ := lab.EntryById(gid)
+	hash, err := engine.ConfigSHA256(ent.ConfigName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	layout, err := layoutOf(lab, gid, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newCoordinator(gid, hash, layout, 0, seed, shards, spins, time.Minute)
+	svr := httptest.NewServer(c.handler())
+	defer svr.Close()
+	cfg.join, cfg.joinWait = svr.URL, 5*time.Second
+
+	// two worker loops stand in for two worker processes
+	p := message.NewPrinter(language.English)
+	errc := make(chan error, 2)
+	for range 2 {
+		go func() { errc <- shardLoop(context.Background(), lab, svr.Client(), p, io.Discard) }()
+	}
+	select {
+	case <-c.done:
+	case err := <-errc:
+		t.Fatalf("worker stopped before the run finished: %v", err)
+	case <-time.After(time.Minute):
+		t.Fatal("distributed run timed out")
+	}
+	got, _, err := c.merge(lab)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	local, err := newRunner(lab, gid, 0, seed, shards)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _, err := local.run(context.Background(), spins)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gs, ws := got.Summary, want.Summary
+	if gs.Rounds != ws.Rounds || gs.TotalWin != ws.TotalWin || gs.BaseWin != ws.BaseWin || gs.Trigger != ws.Trigger || gs.NoWinRounds != ws.NoWinRounds {
+		t.Fatalf("distributed summary %+v != local %+v", gs, ws)
+	}
+	for i := range want.Dist.TotalWinCollect {
+		if got.Dist.TotalWinCollect[i] != want.Dist.TotalWinCollect[i] {
+			t.Fatalf("bucket %d: distributed %d != local %d", i, got.Dist.TotalWinCollect[i], want.Dist.TotalWinCollect[i])
+		}
+	}
+}
+
+func TestRunnerMatchesSimMP(t *testing.T) {
+	lab, err := engine.New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	s, err := lab.NewSimulatorWithSeed(spec.GID(1), 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _, err := s.SimMP(0, 3000, 3, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := newRunner(lab, spec.GID(1), 0, 7, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _, err := r.run(context.Background(), 3000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Summary.TotalWin != want.Summary.TotalWin || got.Summary.Trigger != want.Summary.Trigger {
+		t.Fatalf("runner win %d/trigger %d != SimMP win %d/trigger %d", got.Summary.TotalWin, got.Summary.Trigger, want.Summary.TotalWin, want.Summary.Trigger)
+	}
+}
+
+func TestCoordinatorMergesReportedShardsAfterClose(t *testing.T) {
+	lab, err := engine.New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	ent, _ := lab.EntryById(spec.GID(0))
+	hash, err := engine.ConfigSHA256(ent.ConfigName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layout, err := layoutOf(lab, spec.GID(0), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newCoordinator(spec.GID(0), hash, layout, 0, 5, 3, 1000, time.Minute)
+	if _, _, err := c.merge(lab); err == nil {
+		t.Fatal("merge without any reported shard should fail")
+	}
+	task, ok, _ := c.next(time.Now())
+	if !ok {
+		t.Fatal("no shard leased")
+	}
+	res, want, err := runShard(context.Background(), lab, &task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a result whose win buckets do not match a local worker is refused
+	bad := *res
+	bad.State.TotalWinCollect = bad.State.TotalWinCollect[:len(bad.State.TotalWinCollect)-1]
+	body, _ := json.Marshal(&bad)
+	rec := httptest.NewRecorder()
+	c.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/v1/shards/%d", bad.ID), bytes.NewReader(body)))
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "win buckets") {
+		t.Fatalf("truncated result: %d %s", rec.Code, rec.Body.String())
+	}
+	if err := c.complete(res); err != nil {
+		t.Fatal(err)
+	}
+	c.close()
+	if _, _, finished := c.next(time.Now()); !finished {
+		t.Fatal("closed coordinator should report the run finished")
+	}
+	got, r, err := c.merge(lab)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.workers) != 1 || got.Summary.TotalWin != want.Summary.TotalWin {
+		t.Fatalf("merged %d shards, win %d; want 1 shard, win %d", len(r.workers), got.Summary.TotalWin, want.Summary.TotalWin)
+	}
+}
+
+func TestDistributedProcesses(t *testing.T) {
+	if testing.Short() {
+		t.Skip("starts cmd/run processes")
+	}
+	lab, err := engine.New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	const (
+		seed   = 99
+		shards = 4
+		spins  = 3000
+	)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	report := filepath.Join(t.TempDir(), "dist.json")
+
+	var coordOut bytes.Buffer
+	coord := runCommand(t, "-game", "0", "-seed", strconv.Itoa(seed), "-spins", strconv.Itoa(spins),
+		"-shards", strconv.Itoa(shards), "-coordinator", addr, "-o", report)
+	coord.Stdout, coord.Stderr = &coordOut, &coordOut
+	if err := coord.Start(); err != nil {
+		t.Fatal(err)
+	}
+	workers := make([]*exec.Cmd, 2)
+	outs := make([]bytes.Buffer, len(workers))
+	for i := range workers {
+		workers[i] = runCommand(t, "-join", "http://"+addr, "-join-wait", "30s")
+		workers[i].Stdout, workers[i].Stderr = &outs[i], &outs[i]
+		if err := workers[i].Start(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, w := range workers {
+		if err := w.Wait(); err != nil {
+			t.Errorf("worker %d: %v\n%s", i, err, outs[i].String())
+		}
+	}
+	if err := coord.Wait(); err != nil {
+		t.Fatalf("coordinator: %v\n%s", err, coordOut.String())
+	}
+
+	raw, err := os.ReadFile(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got simReport
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatal(err)
+	}
+	r, err := newRunnerSeeds(lab, 0, 0, shardSeeds(seed, shards))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _, err := r.run(context.Background(), spins)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Workers != shards || got.Spins != want.Summary.Rounds || got.TotalWin != want.Summary.TotalWin {
+		t.Fatalf("distributed report: %d streams, %d spins, win %d; want %d streams, %d spins, win %d",
+			got.Workers, got.Spins, got.TotalWin, shards, want.Summary.Rounds, want.Summary.TotalWin)
+	}
+
+	// bad flags, and a worker that finds nobody listening on addr any more, exit with 1
+	for _, args := range [][]string{
+		{"-coordinator", addr, "-join", "http://" + addr},
+		{"-coordinator", addr, "-shards", "0"},
+		{"-coordinator", "0.0.0.0:0", "-shards", "2"},
+		{"-join", "http://" + addr, "-join-wait", "1s"},
+	} {
+		out, err := runCommand(t, args...).CombinedOutput()
+		var exit *exec.ExitError
+		if !errors.As(err, &exit) || exit.ExitCode() != 1 {
+			t.Errorf("run %v: %v\n%s", args, err, out)
+		}
+	}
+}
+
+func TestShardWorkerInterrupted(t *testing.T) {
+	if testing.Short() {
+		t.Skip("starts a cmd/run process")
+	}
+	lab, err := engine.New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	ent, _ := lab.EntryById(spec.GID(0))
+	hash, err := engine.ConfigSHA256(ent.ConfigName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layout, err := layoutOf(lab, spec.GID(0), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// shards far longer than the test: the worker is interrupted in the first one
+	c := newCoordinator(spec.GID(0), hash, layout, 0, 5, 3, 1_000_000_000, time.Minute)
+	svr := httptest.NewServer(c.handler())
+	defer svr.Close()
+
+	worker := runCommand(t, "-join", svr.URL, "-worker", "1")
+	var out bytes.Buffer
+	worker.Stdout, worker.Stderr = &out, &out
+	if err := worker.Start(); err != nil {
+		t.Fatal(err)
+	}
+	leased := func() (n int) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for _, at := range c.leased {
+			if !at.IsZero() {
+				n++
+			}
+		}
+		return n
+	}
+	for deadline := time.Now().Add(30 * time.Second); leased() == 0; time.Sleep(50 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			worker.Process.Kill()
+			t.Fatalf("no shard leased\n%s", out.String())
+		}
+	}
+	time.Sleep(300 * time.Millisecond) // mid-shard
+	worker.Process.Signal(os.Interrupt)
+
+	done := make(chan error, 1)
+	go func() { done <- worker.Wait() }()
+	select {
+	case err = <-done:
+	case <-time.After(30 * time.Second):
+		worker.Process.Kill()
+		t.Fatalf("the worker kept running after the interrupt\n%s", out.String())
+	}
+	var exit *exec.ExitError
+	if !errors.As(err, &exit) || exit.ExitCode() != exitInterrupted {
+		t.Fatalf("worker: %v, want exit status %d\n%s", err, exitInterrupted, out.String())
+	}
+	if n, r := leased(), c.reported(); n != 1 || r != 0 {
+		t.Fatalf("%d shards leased, %d reported; want the one in flight dropped\n%s", n, r, out.String())
+	}
+	if task, ok, _ := c.next(time.Now().Add(2 * time.Minute)); !ok || task.ID != 0 {
+		t.Fatalf("the dropped shard is not leased again: %+v", task)
+	}
+}
+
+func TestCoordinatorToken(t *testing.T) {
+	lab, err := engine.New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	ent, _ := lab.EntryById(spec.GID(0))
+	hash, err := engine.ConfigSHA256(ent.ConfigName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layout, err := layoutOf(lab, spec.GID(0), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newCoordinator(spec.GID(0), hash, layout, 0, 5, 2, 200, time.Minute)
+	c.token = "s3cret"
+	svr := httptest.NewServer(c.handler())
+	defer svr.Close()
+	saved := *cfg
+	t.Cleanup(func() { *cfg = saved })
+	cfg.join, cfg.joinWait = svr.URL, 5*time.Second
+	p := message.NewPrinter(language.English)
+
+	for _, token := range []string{"", "wrong"} {
+		cfg.token = token
+		if err := shardLoop(context.Background(), lab, svr.Client(), p, io.Discard); err == nil || !strings.Contains(err.Error(), "401") {
+			t.Fatalf("token %q: %v, want 401", token, err)
+		}
+	}
+	if c.reported() != 0 {
+		t.Fatal("a shard was leased without the token")
+	}
+	cfg.token = "s3cret"
+	if err := shardLoop(context.Background(), lab, svr.Client(), p, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if c.reported() != 2 {
+		t.Fatalf("%d shards reported, want 2", c.reported())
+	}
+}
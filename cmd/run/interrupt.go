@@ -0,0 +1,71 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// exitInterrupted is the exit status of a run stopped by SIGINT/SIGTERM, after its
+// partial report has been written.
+const exitInterrupted = 130
+
+// interruptible returns a context that is cancelled by the first SIGINT/SIGTERM, so the
+// workers stop at a spin boundary and the statistics gathered so far can be reported.
+// A second signal exits immediately. stop releases the signal handler.
+func interruptible() (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sigc := make(chan os.Signal, 2)
+	signal.Notify(sigc, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-sigc:
+		case <-done:
+			return
+		}
+		fmt.Fprintln(os.Stderr, "\ninterrupted: stopping workers and reporting the spins so far (Ctrl-C again to exit now)")
+		cancel()
+		select {
+		case <-sigc:
+			fmt.Fprintln(os.Stderr, "interrupted again: exiting without a report")
+			os.Exit(exitInterrupted)
+		case <-done:
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(sigc)
+		close(done)
+		cancel()
+	}
+}
+
+// partialInfo marks a report cut short by an interrupt.
+type partialInfo struct {
+	Done    int    `json:"done"    yaml:"done"`
+	Planned int    `json:"planned" yaml:"planned"`
+	Unit    string `json:"unit"    yaml:"unit"` // spins, sessions or shards
+}
+
+func (pi *partialInfo) String() string {
+	return message.NewPrinter(language.English).Sprintf("PARTIAL REPORT: interrupted after %d of %d %s", pi.Done, pi.Planned, pi.Unit)
+}
@@ -0,0 +1,159 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// What a run does when it asks for more than a limit allows.
+const (
+	exceedRefuse = "refuse" // stop with an error
+	exceedWarn   = "warn"   // run as requested, warn and record the violation
+	exceedClamp  = "clamp"  // reduce to the limit, warn and record the violation
+)
+
+// limits is the policy for player simulations. Studies that need longer horizons (e.g.
+// 24-hour sessions) raise a limit in a policy file or with -max-player-spins; the applied
+// policy is written into the report.
+type limits struct {
+	Name           string `yaml:"name"             json:"name"`
+	MaxPlayers     int    `yaml:"max_players"      json:"max_players"`
+	MaxPlayerSpins int    `yaml:"max_player_spins" json:"max_player_spins"`
+	OnExceed       string `yaml:"on_exceed"        json:"on_exceed"` // refuse|warn|clamp
+}
+
+// defaultLimits keeps player runs to short-term play: 15,000 spins is more than 10 hours
+// of turbo play, and longer horizons belong to machine-level simulation.
+var defaultLimits = limits{Name: "default", MaxPlayers: 100000, MaxPlayerSpins: 15000, OnExceed: exceedRefuse}
+
+// limitsReport is the policy a run was checked against, as recorded in the report.
+type limitsReport struct {
+	limits     `yaml:",inline"`
+	Source     string   `yaml:"source"               json:"source"` // "default", the policy file, plus "flags" when overridden
+	Violations []string `yaml:"violations,omitempty" json:"violations,omitempty"`
+}
+
+// loadLimits reads a policy file (.json, or YAML otherwise). Fields left out keep their
+// default value.
+func loadLimits(path string) (limits, error) {
+	l := defaultLimits
+	l.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return l, err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&l)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(raw))
+		dec.KnownFields(true)
+		err = dec.Decode(&l)
+	}
+	if err != nil {
+		return l, fmt.Errorf("read limits %s: %w", path, err)
+	}
+	return l, nil
+}
+
+func (l limits) valid() error {
+	if l.MaxPlayers < 1 || l.MaxPlayerSpins < 1 {
+		return fmt.Errorf("max_players and max_player_spins must > 0")
+	}
+	switch l.OnExceed {
+	case exceedRefuse, exceedWarn, exceedClamp:
+		return nil
+	}
+	return fmt.Errorf("on_exceed must be %s|%s|%s, got %q", exceedRefuse, exceedWarn, exceedClamp, l.OnExceed)
+}
+
+// applyLimits resolves the policy from -limits and the override flags, checks the run
+// against it and records the result in cfg.limits. Without -spins every player plays the
+// policy's max_player_spins. Violations are printed to stderr in any mode, so they are
+// never silent.
+func (cfg *config) applyLimits() error {
+	rep := &limitsReport{limits: defaultLimits, Source: "default"}
+	if cfg.limitsFile != "" {
+		l, err := loadLimits(cfg.limitsFile)
+		if err != nil {
+			return err
+		}
+		rep.limits, rep.Source = l, cfg.limitsFile
+	}
+	var flags bool
+	if cfg.set["max-players"] {
+		rep.MaxPlayers, flags = cfg.maxPlayers, true
+	}
+	if cfg.set["max-player-spins"] {
+		rep.MaxPlayerSpins, flags = cfg.maxPlayerSpins, true
+	}
+	if cfg.set["on-exceed"] {
+		rep.OnExceed, flags = cfg.onExceed, true
+	}
+	if flags {
+		rep.Source += " + flags"
+	}
+	if err := rep.valid(); err != nil {
+		return fmt.Errorf("limits %s: %w", rep.Source, err)
+	}
+
+	check := func(what string, got *int, limit int, flag string) error {
+		if *got <= limit {
+			return nil
+		}
+		msg := fmt.Sprintf("%s %d exceeds the %s policy limit %d", what, *got, rep.Name, limit)
+		switch rep.OnExceed {
+		case exceedRefuse:
+			return fmt.Errorf("%s (raise it with -%s or -limits, or set -on-exceed warn|clamp)", msg, flag)
+		case exceedClamp:
+			msg += fmt.Sprintf("; clamped to %d", limit)
+			*got = limit
+		default:
+			msg += "; running as requested"
+		}
+		fmt.Fprintf(os.Stderr, "\033[1;33mWARNING: %s\033[0m\n", msg)
+		rep.Violations = append(rep.Violations, msg)
+		return nil
+	}
+	if !cfg.set["spins"] {
+		cfg.spins = rep.MaxPlayerSpins
+	}
+	if err := check("players", &cfg.player, rep.MaxPlayers, "max-players"); err != nil {
+		return err
+	}
+	if err := check("spins per player", &cfg.spins, rep.MaxPlayerSpins, "max-player-spins"); err != nil {
+		return err
+	}
+	cfg.limits = rep
+	return nil
+}
+
+// String is the one-line form printed with text reports.
+func (r *limitsReport) String() string {
+	s := fmt.Sprintf("%s (%s): players <= %d, spins/player <= %d, on exceed: %s", r.Name, r.Source, r.MaxPlayers, r.MaxPlayerSpins, r.OnExceed)
+	if len(r.Violations) > 0 {
+		s += fmt.Sprintf(" [%d violation(s)]", len(r.Violations))
+	}
+	return s
+}
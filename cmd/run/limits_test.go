@@ -0,0 +1,63 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyLimits(t *testing.T) {
+	saved := *cfg
+	defer func() { *cfg = saved }()
+
+	// default policy refuses
+	*cfg = config{player: 10, spins: 20000, set: map[string]bool{"spins": true}}
+	if err := cfg.applyLimits(); err == nil {
+		t.Fatal("20k spins per player should be refused by the default policy")
+	}
+
+	// clamp from flags
+	*cfg = config{player: 10, spins: 20000, onExceed: exceedClamp, set: map[string]bool{"spins": true, "on-exceed": true}}
+	if err := cfg.applyLimits(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.spins != 15000 || len(cfg.limits.Violations) != 1 || cfg.limits.Source != "default + flags" {
+		t.Fatalf("clamp: spins %d, limits %+v", cfg.spins, cfg.limits)
+	}
+
+	// a policy file raises the limit
+	path := filepath.Join(t.TempDir(), "day.yaml")
+	if err := os.WriteFile(path, []byte("max_player_spins: 28800\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	*cfg = config{player: 10, spins: 28800, limitsFile: path, set: map[string]bool{"spins": true}}
+	if err := cfg.applyLimits(); err != nil {
+		t.Fatal(err)
+	}
+	if l := cfg.limits; l.Name != "day" || l.MaxPlayers != defaultLimits.MaxPlayers || l.OnExceed != exceedRefuse || len(l.Violations) != 0 {
+		t.Fatalf("policy file: %+v", l)
+	}
+
+	// without -spins every player plays the policy's maximum
+	*cfg = config{player: 10, spins: 10000000, limitsFile: path, set: map[string]bool{}}
+	if err := cfg.applyLimits(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.spins != 28800 || len(cfg.limits.Violations) != 0 {
+		t.Fatalf("default spins: %d, limits %+v", cfg.spins, cfg.limits)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/zintix-labs/problab-scaffold/internal/configs"
+	"github.com/zintix-labs/problab-scaffold/internal/lint"
+)
+
+// lintConfig holds the flags of the `lint` subcommand.
+type lintConfig struct {
+	out    string
+	strict bool
+}
+
+// lintReport is what `lint -out json` writes.
+type lintReport struct {
+	Configs     int               `json:"configs"`
+	Errors      int               `json:"errors"`
+	Warnings    int               `json:"warnings"`
+	Diagnostics []lint.Diagnostic `json:"diagnostics"`
+}
+
+// runLint checks configs without running them: the files given as arguments, or every
+// embedded config. A variant is checked over its base, which must be linted with it. Each problem is reported at its file:line:column with a suggested
+// fix; the command exits 1 on errors (and with -strict, on warnings too).
+func runLint(args []string) {
+	lc := new(lintConfig)
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	fs.StringVar(&lc.out, "out", "text", "report format: text|json")
+	fs.BoolVar(&lc.strict, "strict", false, "fail on warnings too")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: run lint [-out text|json] [-strict] [config.yaml ...]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	var (
+		diags []lint.Diagnostic
+		n     int
+		err   error
+	)
+	if fs.NArg() > 0 {
+		n = fs.NArg()
+		diags, err = lint.Files(fs.Args())
+	} else {
+		var srcs []lint.Source
+		srcs, err = lint.ReadDir(configs.FS, "internal/configs/games")
+		n, diags = len(srcs), lint.Lint(srcs)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	errs, warnings := lint.Count(diags)
+	rep := &lintReport{Configs: n, Errors: errs, Warnings: warnings, Diagnostics: diags}
+
+	switch lc.out {
+	case outText:
+		stdOutLint(os.Stdout, rep)
+	case outJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rep); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatal("value err : -out must be text or json")
+	}
+	if errs > 0 || (lc.strict && warnings > 0) {
+		os.Exit(1)
+	}
+}
+
+// stdOutLint prints the diagnostics in text mode, errors in red and warnings in yellow.
+func stdOutLint(out io.Writer, rep *lintReport) {
+	for _, d := range rep.Diagnostics {
+		color := "\033[1;33m"
+		if d.Severity == lint.Error {
+			color = "\033[1;31m"
+		}
+		msg := d.Message
+		if d.Variant != "" {
+			msg += " (in variant " + d.Variant + ")"
+		}
+		fmt.Fprintf(out, "%s:%d:%d: %s%s\033[0m: %s\n", d.File, d.Line, d.Column, color, d.Severity, msg)
+		if d.Fix != "" {
+			fmt.Fprintf(out, "\tfix: %s\n", d.Fix)
+		}
+	}
+	fmt.Fprintf(out, "%d errors, %d warnings in %d configs\n", rep.Errors, rep.Warnings, rep.Configs)
+}
@@ -0,0 +1,41 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zintix-labs/problab-scaffold/internal/lint"
+)
+
+func TestStdOutLint(t *testing.T) {
+	rep := &lintReport{Configs: 2, Errors: 1, Warnings: 1, Diagnostics: []lint.Diagnostic{
+		{File: "a.yaml", Line: 3, Column: 12, Severity: lint.Error, Message: "unknown bet_type", Fix: `did you mean "line_ltr"?`},
+		{File: "a.yaml", Line: 9, Column: 1, Severity: lint.Warning, Message: "game_name is empty"},
+	}}
+	var out bytes.Buffer
+	stdOutLint(&out, rep)
+	for _, want := range []string{
+		"a.yaml:3:12: \033[1;31merror\033[0m: unknown bet_type\n\tfix: did you mean \"line_ltr\"?\n",
+		"a.yaml:9:1: \033[1;33mwarning\033[0m: game_name is empty\n1 errors",
+		"1 errors, 1 warnings in 2 configs\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("missing %q in\n%s", want, out.String())
+		}
+	}
+}
@@ -14,10 +14,38 @@
 
 package main
 
-import "github.com/zintix-labs/problab/sdk/perf"
+import (
+	"os"
+
+	"github.com/zintix-labs/problab/sdk/perf"
+)
+
+// commands are the subcommands of cmd/run; without one, cmd/run runs the simulator.
+var commands = map[string]func(args []string){
+	"replay":   runReplay,
+	"compare":  runCompare,
+	"analyze":  runAnalyze,
+	"par":      runPar,
+	"reels":    runReels,
+	"lint":     runLint,
+	"schema":   runSchema,
+	"catalog":  runCatalog,
+	"export":   runExport,
+	"import":   runImport,
+	"rng":      runRng,
+	"picks":    runPicks,
+	"manifest": runManifest,
+	"bundle":   runBundle,
+}
 
 // makefile runner
 func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			cmd(os.Args[2:])
+			return
+		}
+	}
 	bindVar()
 	perf.RunPProf(executeSimulator, cfg.pprofmode)
 }
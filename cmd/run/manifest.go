@@ -0,0 +1,181 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"gopkg.in/yaml.v3"
+)
+
+// manifestConfig holds the flags of the `manifest` subcommand.
+type manifestConfig struct {
+	url     string
+	verify  string
+	out     string
+	outFile string
+}
+
+// runManifest prints the build manifest of this binary (engine.Manifest): module and
+// source revision, engine version, PRNG, the SHA-256 of every embedded and mounted config
+// and the module of every registered logic. With -url it reads the manifest a server
+// serves instead; with -verify it compares the manifest with a certified one and exits
+// with status 1 on any difference.
+func runManifest(args []string) {
+	mc := new(manifestConfig)
+	fs := flag.NewFlagSet("manifest", flag.ExitOnError)
+	fs.StringVar(&mc.url, "url", "", "read the manifest of a running server, e.g. http://localhost:5808/manifest")
+	fs.StringVar(&mc.verify, "verify", "", "compare with this certified manifest file (.json or .yaml)")
+	fs.StringVar(&mc.out, "out", "", "report format: text|json|yaml (default text, or inferred from -o)")
+	fs.StringVar(&mc.outFile, "o", "", "write the manifest to this file instead of stdout")
+	fs.Parse(args)
+
+	oc := &config{out: mc.out, outFile: mc.outFile}
+	format, err := oc.outFormat()
+	if err != nil || format == outCSV {
+		log.Fatal("value err : -out must be text, json or yaml")
+	}
+	var m *engine.BuildManifest
+	if mc.url != "" {
+		m, err = fetchManifest(mc.url)
+	} else {
+		m, err = engine.Manifest()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if mc.verify != "" {
+		want, err := readManifest(mc.verify)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !verifyManifest(os.Stdout, m, want, mc.verify) {
+			os.Exit(1)
+		}
+		return
+	}
+	if format == outText && mc.outFile == "" {
+		stdOutManifest(os.Stdout, m)
+	} else if err := writeReport(m, format, mc.outFile); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// fetchManifest reads the manifest served at url.
+func fetchManifest(url string) (*engine.BuildManifest, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get manifest: %s", resp.Status)
+	}
+	return decodeManifest(resp.Body, url)
+}
+
+// readManifest reads a manifest written by `manifest -out json|yaml`.
+func readManifest(path string) (*engine.BuildManifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return decodeManifest(f, path)
+}
+
+// decodeManifest decodes a JSON or YAML manifest (JSON is YAML).
+func decodeManifest(r io.Reader, name string) (*engine.BuildManifest, error) {
+	m := new(engine.BuildManifest)
+	if err := yaml.NewDecoder(r).Decode(m); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if m.SHA256 == "" {
+		return nil, fmt.Errorf("%s: not a manifest (no sha256)", name)
+	}
+	return m, nil
+}
+
+// verifyManifest prints whether m matches want and every difference.
+func verifyManifest(out io.Writer, m, want *engine.BuildManifest, name string) bool {
+	diffs := m.Diff(want)
+	if len(diffs) == 0 {
+		fmt.Fprintf(out, "OK: the manifest matches %s (sha256 %s)\n", name, want.SHA256)
+		return true
+	}
+	fmt.Fprintf(out, "MISMATCH: the manifest differs from %s: %d difference(s)\n", name, len(diffs))
+	for _, d := range diffs {
+		fmt.Fprintf(out, "  %s\n", d)
+	}
+	return false
+}
+
+// stdOutManifest prints the manifest in text mode.
+func stdOutManifest(out io.Writer, m *engine.BuildManifest) {
+	p := message.NewPrinter(language.English)
+	p.Fprintf(out, "module      : %s\n", m.Module)
+	p.Fprintf(out, "go          : %s\n", m.GoVersion)
+	if m.Tags != "" {
+		p.Fprintf(out, "tags        : %s\n", m.Tags)
+	}
+	if v := m.VCS; v != nil {
+		modified := ""
+		if v.Modified {
+			modified = " (modified)"
+		}
+		p.Fprintf(out, "vcs         : %s %s %s%s\n", v.System, v.Revision, v.Time, modified)
+	} else {
+		p.Fprintf(out, "vcs         : not stamped (go run / go test)\n")
+	}
+	p.Fprintf(out, "engine      : %s\n", m.Engine)
+	p.Fprintf(out, "prng        : %s\n", m.PRNG)
+	if b := m.Bundle; b != nil {
+		p.Fprintf(out, "bundle      : %s (sha256 %s, key %s, created %s)\n", m.BundlePath, b.SHA256, b.Key, b.Created.Format(time.RFC3339))
+	}
+	p.Fprintf(out, "sha256      : %s\n\n", m.SHA256)
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "EMBEDDED CONFIG\tSIZE\tSHA256")
+	for _, c := range m.Configs {
+		p.Fprintf(tw, "%s\t%d\t%s\n", c.Path, c.Size, c.SHA256)
+	}
+	tw.Flush()
+	fmt.Fprintln(out)
+	tw = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MOUNTED CONFIG\tORIGIN\tSHA256 (RESOLVED)")
+	for _, c := range m.Mounted {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", c.Name, c.Origin, c.SHA256)
+	}
+	tw.Flush()
+	fmt.Fprintln(out)
+	tw = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LOGIC\tPACKAGE\tMODULE")
+	for _, l := range m.Logics {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", l.Key, l.Package, l.Module)
+	}
+	tw.Flush()
+}
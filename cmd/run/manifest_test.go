@@ -0,0 +1,80 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+)
+
+func TestManifestVerify(t *testing.T) {
+	m, err := engine.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	for _, format := range []string{outJSON, outYAML} {
+		path := filepath.Join(dir, "certified."+format)
+		if err := writeReport(m, format, path); err != nil {
+			t.Fatal(err)
+		}
+		want, err := readManifest(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out bytes.Buffer
+		if !verifyManifest(&out, m, want, path) || !strings.HasPrefix(out.String(), "OK:") {
+			t.Fatalf("%s: %s", format, out.String())
+		}
+	}
+
+	// a server serving another config
+	served := *m
+	served.Mounted = append([]engine.MountedDigest(nil), m.Mounted...)
+	served.Mounted[0].SHA256 = strings.Repeat("0", 64)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&served)
+	}))
+	defer srv.Close()
+	got, err := fetchManifest(srv.URL + "/manifest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := readManifest(filepath.Join(dir, "certified.json"))
+	var out bytes.Buffer
+	if verifyManifest(&out, got, want, "certified.json") || !strings.Contains(out.String(), "1 difference(s)\n  mounted demo_0.yaml:") {
+		t.Fatalf("verify:\n%s", out.String())
+	}
+
+	out.Reset()
+	stdOutManifest(&out, m)
+	if !strings.Contains(out.String(), "zintix/demo_1/demo_1.yaml") || !strings.Contains(out.String(), "demo_cascade") {
+		t.Fatalf("manifest text:\n%s", out.String())
+	}
+	if err := os.WriteFile(filepath.Join(dir, "x.json"), []byte(`{"prng": "pcg64"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readManifest(filepath.Join(dir, "x.json")); err == nil {
+		t.Fatal("a file without sha256 read as a manifest")
+	}
+}
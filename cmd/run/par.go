@@ -0,0 +1,511 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	_ "embed"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/zintix-labs/problab"
+	"github.com/zintix-labs/problab-scaffold/internal/exact"
+	"github.com/zintix-labs/problab-scaffold/internal/simstat"
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+	"github.com/zintix-labs/problab/spec"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+//go:embed par.html
+var parHTML string
+
+// parConfig holds the flags of the `par` subcommand.
+type parConfig struct {
+	id         spec.GID
+	all        bool // -game not given: every mounted config
+	dir        string
+	formats    []string
+	seed       int64
+	spins      int
+	worker     int
+	confidence float64
+}
+
+// parSheet is the probability accounting report of one game.
+type parSheet struct {
+	Game        string
+	GameID      spec.GID
+	Config      string
+	SHA256      string
+	Logic       string
+	BetUnits    []int
+	MaxWinLimit int // credits at bet multiplier 1
+	Seed        int64
+	Workers     int
+	Confidence  float64
+	Modes       []parMode
+	Bets        []parBet
+	Partial     *partialInfo
+}
+
+// parMode is the config and the exact statistics of one game mode.
+type parMode struct {
+	GameMode    int
+	Columns     int
+	Rows        int
+	GenReelType string
+	BetType     string
+	Symbols     []string
+	PayTable    [][]int
+	Lines       [][]int16
+	ReelSets    []parReelSet
+	Exact       *exact.Result
+	Cycle       int64 // stop combinations when one reel set is used; 0 otherwise
+	Skipped     string
+}
+
+// parReelSet is one reel set with its strips.
+type parReelSet struct {
+	Weight int
+	Reels  []parReel
+}
+
+// parReel is one reel strip and the number of stops of each symbol.
+type parReel struct {
+	Strip  []string
+	Counts []int // by symbol id
+	Length int
+}
+
+// parBet is the simulation of one bet mode.
+type parBet struct {
+	BetMode     int
+	BetUnit     int
+	Spins       int
+	RTP         float64
+	RtpLo       float64
+	RtpHi       float64
+	HitRate     float64
+	SD          float64 // per spin, in bet multiples
+	Triggers    int
+	TriggerRate float64
+	MaxWin      int
+	MaxWinX     float64
+	Modes       []parModeRTP
+}
+
+// parModeRTP is the RTP contributed by one game mode in one bet mode.
+type parModeRTP struct {
+	GameMode       int
+	RTP            float64 // exact when Exact, simulated otherwise
+	Exact          bool
+	SimRTP         float64
+	ScreensPerSpin float64
+	PerScreen      float64 // exact win per screen in bet multiples; 0 when not analyzed
+}
+
+// runPar writes a PAR sheet in HTML and CSV for every mounted config (or -game).
+//
+// The config parts (reel strips and symbol counts, pay table, line table) are read from
+// the YAML. Line game modes with GenReelByReelIdx reels get exact hit combinations,
+// probabilities, RTP and max screen win, as in `analyze`. Everything that depends on
+// the logic (feature triggers, mode frequencies, the RTP of other modes, the max win of
+// a spin) is simulated with -spins per worker for each bet mode. A mode's RTP
+// contribution is exact when its exact per-screen RTP applies to every spin, that is
+// when the simulation played exactly one round of that mode per spin.
+func runPar(args []string) {
+	pc := new(parConfig)
+	fs := flag.NewFlagSet("par", flag.ExitOnError)
+	fs.Var(gidFlag{&pc.id}, "game", "target game id (default: every mounted config)")
+	fs.StringVar(&pc.dir, "dir", "build/par", "output directory; one <config>.html/.csv per game")
+	format := fs.String("format", "html,csv", "comma-separated output formats: html, csv")
+	fs.Int64Var(&pc.seed, "seed", -1, "int64 seed of the simulation")
+	fs.IntVar(&pc.spins, "spins", 1000000, "spins per worker and bet mode for the simulated figures")
+	fs.IntVar(&pc.worker, "worker", 1, "number of workers")
+	fs.Float64Var(&pc.confidence, "confidence", 0.95, "confidence level of the RTP interval, in (0,1)")
+	fs.Parse(args)
+
+	pc.all = true
+	fs.Visit(func(f *flag.Flag) { pc.all = pc.all && f.Name != "game" })
+	for _, f := range strings.Split(*format, ",") {
+		f = strings.TrimSpace(f)
+		if f != "html" && f != "csv" {
+			log.Fatalf("value err : unsupported par format: %q", f)
+		}
+		pc.formats = append(pc.formats, f)
+	}
+	if pc.spins < 1 || pc.worker < 1 {
+		log.Fatal("value err : spins and worker must > 0")
+	}
+	if pc.confidence <= 0 || pc.confidence >= 1 {
+		log.Fatal("value err : confidence must be in (0,1)")
+	}
+	if pc.seed < 1 {
+		seed, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt64))
+		if err != nil {
+			log.Fatal(err)
+		}
+		pc.seed = seed.Int64()
+	}
+
+	lab := engine.MustNew()
+	ids := lab.IDs()
+	if !pc.all {
+		if _, ok := lab.EntryById(pc.id); !ok {
+			log.Fatalf("value err : game id not found: %d", pc.id)
+		}
+		ids = []spec.GID{pc.id}
+	}
+	slices.Sort(ids)
+
+	ctx, stop := interruptible()
+	defer stop()
+	p := message.NewPrinter(language.English)
+	p.Fprintf(os.Stderr, "\033[1;32m[PAR] [GAMES:%d] [WORKERS:%d] [SPINS:%d per bet mode]\033[0m\n", len(ids), pc.worker, pc.worker*pc.spins)
+	for _, id := range ids {
+		sheet, err := pc.sheet(ctx, lab, id)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, f := range pc.formats {
+			path := filepath.Join(pc.dir, strings.TrimSuffix(sheet.Config, filepath.Ext(sheet.Config))+"."+f)
+			if err := writePar(sheet, f, path); err != nil {
+				log.Fatal(err)
+			}
+			p.Fprintf(os.Stderr, "%s (gid %d): %s\n", sheet.Game, id, path)
+		}
+		if sheet.Partial != nil {
+			p.Fprintf(os.Stderr, "\033[1;31m%s\033[0m\n", sheet.Partial)
+			stop()
+			os.Exit(exitInterrupted)
+		}
+	}
+}
+
+// sheet builds the PAR sheet of game id.
+func (pc *parConfig) sheet(ctx context.Context, lab *problab.Problab, id spec.GID) (*parSheet, error) {
+	ent, _ := lab.EntryById(id)
+	arm, err := loadArm(armEmbedded, ent.ConfigName, id, 0)
+	if err != nil {
+		return nil, err
+	}
+	gs := arm.gs
+	sheet := &parSheet{
+		Game:        ent.Name,
+		GameID:      id,
+		Config:      ent.ConfigName,
+		SHA256:      arm.SHA256,
+		Logic:       arm.Logic,
+		BetUnits:    gs.BetUnits,
+		MaxWinLimit: gs.MaxWinLimit,
+		Seed:        pc.seed,
+		Workers:     pc.worker,
+		Confidence:  pc.confidence,
+	}
+	for i := range gs.GameModeSettings {
+		sheet.Modes = append(sheet.Modes, newParMode(i, &gs.GameModeSettings[i]))
+	}
+
+	z := simstat.Z(pc.confidence)
+	for mode := range gs.BetUnits {
+		arm, err := loadArm(armEmbedded, ent.ConfigName, id, mode)
+		if err != nil {
+			return nil, err
+		}
+		t, _, err := tally(ctx, lab, arm, id, mode, pc.seed, pc.worker, pc.spins, false)
+		if err != nil {
+			return nil, err
+		}
+		if t.spins == 0 {
+			return nil, fmt.Errorf("interrupted before the first spin: nothing to report")
+		}
+		bet := float64(arm.BetUnit)
+		n := float64(t.spins)
+		mean := t.win / n / bet
+		sd := math.Sqrt(max(t.winSq/n/bet/bet-mean*mean, 0))
+		pb := parBet{
+			BetMode:     mode,
+			BetUnit:     arm.BetUnit,
+			Spins:       t.spins,
+			RTP:         mean,
+			RtpLo:       mean - z*sd/math.Sqrt(n),
+			RtpHi:       mean + z*sd/math.Sqrt(n),
+			HitRate:     float64(t.hits) / n,
+			SD:          sd,
+			Triggers:    t.triggers,
+			TriggerRate: float64(t.triggers) / n,
+			MaxWin:      t.maxWin,
+			MaxWinX:     float64(t.maxWin) / bet,
+		}
+		for i, s := range t.modes {
+			m := parModeRTP{GameMode: i, SimRTP: s.win / n / bet, ScreensPerSpin: float64(s.n) / n}
+			m.RTP = m.SimRTP
+			if e := sheet.Modes[i].Exact; e != nil {
+				m.PerScreen = e.Win / bet
+				if s.n == t.spins && s.win == s.sum {
+					m.RTP, m.Exact = m.PerScreen, true
+				}
+			}
+			pb.Modes = append(pb.Modes, m)
+		}
+		sheet.Bets = append(sheet.Bets, pb)
+		if planned := pc.spins * pc.worker; t.spins < planned {
+			sheet.Partial = &partialInfo{Done: t.spins, Planned: planned, Unit: "spins"}
+			break
+		}
+	}
+	return sheet, nil
+}
+
+// newParMode reads the config of one game mode and analyzes it when it is a line game.
+func newParMode(i int, gms *spec.GameModeSetting) parMode {
+	ss := &gms.SymbolSetting
+	m := parMode{
+		GameMode:    i,
+		Columns:     gms.ScreenSetting.Columns,
+		Rows:        gms.ScreenSetting.Rows,
+		GenReelType: gms.GenScreenSetting.GenReelTypeStr,
+		BetType:     gms.HitSetting.BetTypeStr,
+		Symbols:     ss.SymbolUsedStr,
+		PayTable:    ss.PayTable,
+		Lines:       gms.HitSetting.LineTable,
+	}
+	used := 0
+	for _, rs := range gms.GenScreenSetting.ReelSetGroup {
+		set := parReelSet{Weight: rs.Weight}
+		for _, reel := range rs.Reels {
+			r := parReel{Counts: make([]int, len(ss.SymbolUsedStr)), Length: len(reel.ReelSymbols)}
+			for _, s := range reel.ReelSymbols {
+				name := strconv.Itoa(int(s))
+				if int(s) >= 0 && int(s) < len(r.Counts) {
+					name = ss.SymbolUsedStr[s]
+					r.Counts[s]++
+				}
+				r.Strip = append(r.Strip, name)
+			}
+			set.Reels = append(set.Reels, r)
+		}
+		if rs.Weight > 0 {
+			used++
+		}
+		m.ReelSets = append(m.ReelSets, set)
+	}
+	res, err := exact.Analyze(gms)
+	if err != nil {
+		m.Skipped = err.Error()
+		return m
+	}
+	m.Exact = res
+	if used == 1 {
+		m.Cycle = int64(res.Combinations)
+	}
+	return m
+}
+
+// writePar writes a PAR sheet to path in format (html or csv).
+func writePar(sheet *parSheet, format, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if format == "csv" {
+		return writeParCSV(f, sheet)
+	}
+	return writeParHTML(f, sheet)
+}
+
+// writeParHTML renders par.html.
+func writeParHTML(w io.Writer, sheet *parSheet) error {
+	p := message.NewPrinter(language.English)
+	funcs := template.FuncMap{
+		"pct":  func(v float64) string { return p.Sprintf("%.4f%%", 100*v) },
+		"num":  func(v any) string { return p.Sprintf("%v", v) },
+		"prob": func(v float64) string { return strconv.FormatFloat(v, 'g', 6, 64) },
+		"odds": func(v float64) string {
+			if v <= 0 {
+				return "-"
+			}
+			return p.Sprintf("%.2f", 1/v)
+		},
+		"x": func(credits, bet int) string { return p.Sprintf("%.2fx", float64(credits)/float64(bet)) },
+		"combos": func(hits float64, cycle int64) string {
+			if cycle == 0 {
+				return "-"
+			}
+			return p.Sprintf("%d", int64(math.Round(hits*float64(cycle))))
+		},
+		"atleast": func(ps []float64, k int) float64 {
+			s := 0.0
+			for _, v := range ps[k:] {
+				s += v
+			}
+			return s
+		},
+		"rtp": func(win float64, bet int) float64 { return win / float64(bet) },
+		"add": func(a, b int) int { return a + b },
+	}
+	t, err := template.New("par").Funcs(funcs).Parse(parHTML)
+	if err != nil {
+		return err
+	}
+	return t.Execute(w, sheet)
+}
+
+// writeParCSV writes the sheet in long format (section,key,value). Per-mode keys are
+// prefixed with the game mode (m0.), per-bet-mode keys with the bet mode (b0.).
+func writeParCSV(w io.Writer, s *parSheet) error {
+	cw := csv.NewWriter(w)
+	f := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	i := strconv.Itoa
+	rows := [][]string{
+		{"section", "key", "value"},
+		{"game", "game", s.Game},
+		{"game", "game_id", fmt.Sprint(uint(s.GameID))},
+		{"game", "config", s.Config},
+		{"game", "config_sha256", s.SHA256},
+		{"game", "logic", s.Logic},
+		{"game", "max_win_limit", i(s.MaxWinLimit)},
+		{"game", "seed", strconv.FormatInt(s.Seed, 10)},
+		{"game", "workers", i(s.Workers)},
+	}
+	for b, u := range s.BetUnits {
+		rows = append(rows, []string{"game", "bet_unit." + i(b), i(u)})
+	}
+	if pt := s.Partial; pt != nil {
+		rows = append(rows,
+			[]string{"partial", "done", i(pt.Done)},
+			[]string{"partial", "planned", i(pt.Planned)},
+			[]string{"partial", "unit", pt.Unit},
+		)
+	}
+	for _, m := range s.Modes {
+		k := "m" + i(m.GameMode) + "."
+		rows = append(rows,
+			[]string{"mode", k + "screen", i(m.Columns) + "x" + i(m.Rows)},
+			[]string{"mode", k + "gen_reel_type", m.GenReelType},
+			[]string{"mode", k + "bet_type", m.BetType},
+		)
+		for si, sym := range m.Symbols {
+			for c, v := range m.PayTable[si] {
+				rows = append(rows, []string{"pay_table", k + sym + "x" + i(c+1), i(v)})
+			}
+		}
+		for li, line := range m.Lines {
+			cells := make([]string, len(line))
+			for c, r := range line {
+				cells[c] = strconv.Itoa(int(r))
+			}
+			rows = append(rows, []string{"line_table", k + "line" + i(li), strings.Join(cells, " ")})
+		}
+		for ri, rs := range m.ReelSets {
+			rk := k + "set" + i(ri) + "."
+			rows = append(rows, []string{"reel_set", rk + "weight", i(rs.Weight)})
+			for c, reel := range rs.Reels {
+				ck := rk + "reel" + i(c) + "."
+				rows = append(rows,
+					[]string{"reel", ck + "length", i(reel.Length)},
+					[]string{"reel", ck + "strip", strings.Join(reel.Strip, " ")},
+				)
+				for si, n := range reel.Counts {
+					rows = append(rows, []string{"symbol_count", ck + m.Symbols[si], i(n)})
+				}
+			}
+		}
+		if m.Exact == nil {
+			rows = append(rows, []string{"exact", k + "skipped", m.Skipped})
+			continue
+		}
+		e := m.Exact
+		rows = append(rows,
+			[]string{"exact", k + "cycle", strconv.FormatInt(m.Cycle, 10)},
+			[]string{"exact", k + "win", f(e.Win)},
+			[]string{"exact", k + "win_sd", f(e.WinSD)},
+			[]string{"exact", k + "hit_rate", f(e.HitRate)},
+			[]string{"exact", k + "max_win", i(e.MaxWin)},
+		)
+		for _, sp := range e.Symbols {
+			hk := k + sp.Symbol + "x" + i(sp.Count) + "."
+			rows = append(rows,
+				[]string{"hits", hk + "pay", i(sp.Pay)},
+				[]string{"hits", hk + "probability", f(sp.Hits)},
+				[]string{"hits", hk + "win", f(sp.Win)},
+			)
+			if m.Cycle > 0 {
+				rows = append(rows, []string{"hits", hk + "combinations", strconv.FormatInt(int64(math.Round(sp.Hits*float64(m.Cycle))), 10)})
+			}
+		}
+		for _, l := range e.LinePays {
+			lk := k + "line" + i(l.Line) + "." + l.Direction + "."
+			rows = append(rows,
+				[]string{"line", lk + "hit_rate", f(l.HitRate)},
+				[]string{"line", lk + "win", f(l.Win)},
+			)
+		}
+		for _, sc := range e.Scatters {
+			for n, p := range sc.P {
+				rows = append(rows, []string{"scatter", k + sc.Symbol + "." + i(n), f(p)})
+			}
+		}
+	}
+	for _, b := range s.Bets {
+		k := "b" + i(b.BetMode) + "."
+		rows = append(rows,
+			[]string{"bet_mode", k + "bet_unit", i(b.BetUnit)},
+			[]string{"bet_mode", k + "spins", i(b.Spins)},
+			[]string{"bet_mode", k + "rtp", f(b.RTP)},
+			[]string{"bet_mode", k + "rtp_lo", f(b.RtpLo)},
+			[]string{"bet_mode", k + "rtp_hi", f(b.RtpHi)},
+			[]string{"bet_mode", k + "hit_rate", f(b.HitRate)},
+			[]string{"bet_mode", k + "sd", f(b.SD)},
+			[]string{"bet_mode", k + "trigger_rate", f(b.TriggerRate)},
+			[]string{"bet_mode", k + "max_win", i(b.MaxWin)},
+			[]string{"bet_mode", k + "max_win_x", f(b.MaxWinX)},
+		)
+		for _, m := range b.Modes {
+			mk := k + "m" + i(m.GameMode) + "."
+			source := "simulated"
+			if m.Exact {
+				source = "exact"
+			}
+			rows = append(rows,
+				[]string{"mode_rtp", mk + "rtp", f(m.RTP)},
+				[]string{"mode_rtp", mk + "source", source},
+				[]string{"mode_rtp", mk + "sim_rtp", f(m.SimRTP)},
+				[]string{"mode_rtp", mk + "screens_per_spin", f(m.ScreensPerSpin)},
+			)
+		}
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+	"github.com/zintix-labs/problab/spec"
+)
+
+func TestParSheet(t *testing.T) {
+	lab, err := engine.New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	pc := &parConfig{seed: 42, spins: 5000, worker: 2, confidence: 0.95}
+
+	sheet, err := pc.sheet(context.Background(), lab, spec.GID(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sheet.Modes) != 2 || len(sheet.Bets) != len(sheet.BetUnits) || sheet.Partial != nil {
+		t.Fatalf("sheet %+v", sheet)
+	}
+	for _, m := range sheet.Modes {
+		if m.Exact == nil || m.Cycle <= 0 || len(m.ReelSets) == 0 {
+			t.Fatalf("mode %d: %+v", m.GameMode, m)
+		}
+	}
+	bet := sheet.Bets[0]
+	if bet.Spins != 10000 || len(bet.Modes) != 2 {
+		t.Fatalf("bet mode %+v", bet)
+	}
+	// the base game is one screen per spin: exact; the free game depends on triggers
+	if base := bet.Modes[0]; !base.Exact || base.RTP != base.PerScreen || base.RTP < 0.55 || base.RTP > 0.65 {
+		t.Fatalf("base %+v", base)
+	}
+	if free := bet.Modes[1]; free.Exact || free.RTP != free.SimRTP {
+		t.Fatalf("free %+v", free)
+	}
+
+	var csv, html bytes.Buffer
+	if err := writeParCSV(&csv, sheet); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeParHTML(&html, sheet); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"pay_table,m0.H1x5,", "line_table,m0.line0,", "symbol_count,m0.set0.reel0.C1,", "hits,m0.", "scatter,m0.C1.3,", "mode_rtp,b0.m0.source,exact", "bet_mode,b0.trigger_rate,"} {
+		if !strings.Contains(csv.String(), want) {
+			t.Errorf("csv has no %q", want)
+		}
+	}
+	for _, want := range []string{"Pay table", "Line table", "Reel strips", "Exact line wins", "Feature trigger", "Scatter C1"} {
+		if !strings.Contains(html.String(), want) {
+			t.Errorf("html has no %q", want)
+		}
+	}
+
+	// a cluster game has no exact analysis but still gets its sheet
+	if sheet, err = pc.sheet(context.Background(), lab, spec.GID(1)); err != nil {
+		t.Fatal(err)
+	}
+	if sheet.Modes[0].Exact != nil || sheet.Modes[0].Skipped == "" || sheet.Bets[0].Modes[0].Exact {
+		t.Fatalf("cluster sheet %+v", sheet.Modes[0])
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/big"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/zintix-labs/problab-scaffold/internal/reels"
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+	"github.com/zintix-labs/problab/sdk/core"
+	"github.com/zintix-labs/problab/spec"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// picksConfig holds the flags of the `picks` subcommand.
+type picksConfig struct {
+	id      spec.GID
+	all     bool
+	screens int
+	seed    int64
+	alpha   float64
+	out     string
+	outFile string
+}
+
+// picksMode is the sampled picks of one game mode.
+type picksMode struct {
+	GameMode     int    `json:"game_mode"         yaml:"game_mode"`
+	Skipped      string `json:"skipped,omitempty" yaml:"skipped,omitempty"` // why its reels are not sampled
+	*reels.Picks `json:",inline,omitempty" yaml:",inline,omitempty"`
+}
+
+// picksGame is the sampled picks of one game.
+type picksGame struct {
+	GameID spec.GID    `json:"game_id" yaml:"game_id"`
+	Name   string      `json:"name"    yaml:"name"`
+	Config string      `json:"config"  yaml:"config"`
+	SHA256 string      `json:"sha256"  yaml:"sha256"`
+	Modes  []picksMode `json:"modes"   yaml:"modes"`
+}
+
+// picksReport is what `picks` writes.
+type picksReport struct {
+	PRNG    string  `json:"prng"    yaml:"prng"`
+	Seed    int64   `json:"seed"    yaml:"seed"`
+	Screens int     `json:"screens" yaml:"screens"` // per check
+	Alpha   float64 `json:"alpha"   yaml:"alpha"`
+	// Threshold is alpha over the number of checks (Bonferroni): the p-value below which
+	// a check deviates.
+	Threshold  float64     `json:"threshold"   yaml:"threshold"`
+	ElapsedSec float64     `json:"elapsed_sec" yaml:"elapsed_sec"`
+	Games      []picksGame `json:"games"       yaml:"games"`
+	Checks     int         `json:"checks"      yaml:"checks"`
+	Deviating  int         `json:"deviating"   yaml:"deviating"`
+	Pass       bool        `json:"pass"        yaml:"pass"`
+}
+
+// runPicks verifies that the screen generator lands on every reel stop, and on every
+// reel set, with the probability of its weight: it draws screens through the upstream
+// ScreenGenerator with the PRNG this binary is built with, reads the stop picked on
+// every reel, and chi-square tests the counts against the config. It exits with status
+// 1 when a reel deviates, picks a stop of weight 0 or shows another stop than it picked.
+func runPicks(args []string) {
+	pc := new(picksConfig)
+	fs := flag.NewFlagSet("picks", flag.ExitOnError)
+	fs.Var(gidFlag{&pc.id}, "game", "target game id")
+	fs.BoolVar(&pc.all, "all", false, "verify every registered game (ignores -game)")
+	fs.IntVar(&pc.screens, "screens", 1_000_000, "screens drawn per reel set, and for the reel set selection")
+	fs.Int64Var(&pc.seed, "seed", -1, "int64 seed of every game mode (< 1: random, reported)")
+	fs.Float64Var(&pc.alpha, "alpha", 0.001, "false alarm rate of the whole run, split over every check")
+	fs.StringVar(&pc.out, "out", "", "report format: text|json|csv|yaml (default text, or inferred from -o)")
+	fs.StringVar(&pc.outFile, "o", "", "write the report to this file instead of stdout")
+	fs.Parse(args)
+
+	oc := &config{out: pc.out, outFile: pc.outFile}
+	format, err := oc.outFormat()
+	if err != nil {
+		log.Fatal("value err : " + err.Error())
+	}
+	if pc.screens < 1 {
+		log.Fatal("value err : -screens must be at least 1")
+	}
+	if pc.alpha <= 0 || pc.alpha >= 1 {
+		log.Fatal("value err : -alpha must be in (0,1)")
+	}
+	if pc.seed < 1 {
+		seed, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt64))
+		if err != nil {
+			log.Fatal(err)
+		}
+		pc.seed = seed.Int64()
+	}
+	lab := engine.MustNew()
+	ids := []spec.GID{pc.id}
+	if pc.all {
+		sums, err := lab.Summary()
+		if err != nil {
+			log.Fatal(err)
+		}
+		ids = ids[:0]
+		for _, s := range sums {
+			ids = append(ids, s.GID)
+		}
+	}
+
+	start := time.Now()
+	rep := &picksReport{PRNG: engine.PRNG(), Seed: pc.seed, Screens: pc.screens, Alpha: pc.alpha}
+	for _, id := range ids {
+		ent, ok := lab.EntryById(id)
+		if !ok {
+			log.Fatalf("value err : game id not found: %d", id)
+		}
+		g, err := samplePicks(ent.ConfigName, engine.PRNGFactory(), pc.seed, pc.screens)
+		if err != nil {
+			log.Fatal(err)
+		}
+		g.GameID, g.Name = id, ent.Name
+		rep.Games = append(rep.Games, *g)
+	}
+	judgePicks(rep)
+	rep.ElapsedSec = time.Since(start).Seconds()
+
+	if format == outText && pc.outFile == "" {
+		stdOutPicks(os.Stdout, rep)
+	} else if err := writeReport(rep, format, pc.outFile); err != nil {
+		log.Fatal(err)
+	}
+	if !rep.Pass {
+		os.Exit(1)
+	}
+}
+
+// samplePicks samples every game mode of the mounted config name. Every mode draws from
+// its own PRNG seeded with seed, so a mode replays alone.
+func samplePicks(name string, f core.PRNGFactory, seed int64, screens int) (*picksGame, error) {
+	gs, err := engine.GameSetting(name)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := engine.ConfigSHA256(name)
+	if err != nil {
+		return nil, err
+	}
+	g := &picksGame{Config: name, SHA256: hash}
+	for i := range gs.GameModeSettings {
+		m := picksMode{GameMode: i}
+		if p, err := reels.SamplePicks(&gs.GameModeSettings[i], f, seed, screens); err != nil {
+			m.Skipped = err.Error()
+		} else {
+			m.Picks = p
+		}
+		g.Modes = append(g.Modes, m)
+	}
+	return g, nil
+}
+
+// judgePicks splits alpha over every check of rep and marks the deviating ones.
+func judgePicks(rep *picksReport) {
+	rep.Checks, rep.Deviating = 0, 0
+	for _, g := range rep.Games {
+		for _, m := range g.Modes {
+			if m.Picks != nil {
+				rep.Checks += len(m.Checks)
+			}
+		}
+	}
+	rep.Threshold = rep.Alpha / float64(max(rep.Checks, 1))
+	for _, g := range rep.Games {
+		for _, m := range g.Modes {
+			if m.Picks == nil {
+				continue
+			}
+			for i := range m.Checks {
+				if m.Checks[i].Judge(rep.Threshold) {
+					rep.Deviating++
+				}
+			}
+		}
+	}
+	rep.Pass = rep.Deviating == 0
+}
+
+// stdOutPicks prints the checks in text mode, one reel per row.
+func stdOutPicks(out io.Writer, rep *picksReport) {
+	p := message.NewPrinter(language.English)
+	p.Fprintf(out, "prng        : %s (seed %d)\n", rep.PRNG, rep.Seed)
+	p.Fprintf(out, "screens     : %d per reel set, alpha %g over %d checks (p < %s deviates), %.1fs\n", rep.Screens, rep.Alpha, rep.Checks, strconv.FormatFloat(rep.Threshold, 'g', 3, 64), rep.ElapsedSec)
+	for _, g := range rep.Games {
+		p.Fprintf(out, "\ngame %d : %s (%s, sha256 %.12s)\n", uint(g.GameID), g.Name, g.Config, g.SHA256)
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "MODE\tCHECK\tCELLS\tPICKS\tCHI2\tDF\tP-VALUE\tWORST\tOBSERVED\tEXPECTED\tRESULT")
+		for _, m := range g.Modes {
+			if m.Picks == nil {
+				p.Fprintf(tw, "%d\tskipped (%s)\n", m.GameMode, m.Skipped)
+				continue
+			}
+			for _, c := range m.Checks {
+				result := "ok"
+				switch {
+				case c.ZeroWeight > 0:
+					result = p.Sprintf("FAIL: %d picks of weight 0", c.ZeroWeight)
+				case c.Mismatches > 0:
+					result = p.Sprintf("FAIL: %d screens mismatch", c.Mismatches)
+				case c.Deviates:
+					result = "FAIL"
+				}
+				p.Fprintf(tw, "%d\t%s\t%d\t%d\t%.2f\t%d\t%.4f\t%d\t%.4f%%\t%.4f%%\t%s\n",
+					m.GameMode, c.Label(), c.Cells, c.Picks, c.Stat, c.DF, c.P, c.Worst, 100*c.Observed, 100*c.Expected, result)
+			}
+		}
+		tw.Flush()
+	}
+	if rep.Pass {
+		p.Fprintf(out, "\nPASS: %d/%d checks\n", rep.Checks, rep.Checks)
+	} else {
+		p.Fprintf(out, "\nFAIL: %d of %d checks\n", rep.Deviating, rep.Checks)
+	}
+	p.Fprintf(out, "(WORST: the stop, or reel set, furthest from its weight in standard deviations)\n")
+}
+
+// writePicksCSV writes one row per check.
+func writePicksCSV(w io.Writer, r *picksReport) error {
+	cw := csv.NewWriter(w)
+	f := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	i := strconv.Itoa
+	cw.Write([]string{"prng", "seed", "game_id", "game_mode", "reel_set", "reel", "cells", "picks", "chi2", "df", "p_value", "worst", "observed", "expected", "zero_weight", "mismatches", "deviates"})
+	for _, g := range r.Games {
+		for _, m := range g.Modes {
+			if m.Picks == nil {
+				continue
+			}
+			for _, c := range m.Checks {
+				cw.Write([]string{r.PRNG, strconv.FormatInt(r.Seed, 10), fmt.Sprint(uint(g.GameID)), i(m.GameMode), i(c.ReelSet), i(c.Reel),
+					i(c.Cells), i(c.Picks), f(c.Stat), i(c.DF), f(c.P), i(c.Worst), f(c.Observed), f(c.Expected),
+					i(c.ZeroWeight), i(c.Mismatches), strconv.FormatBool(c.Deviates)})
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
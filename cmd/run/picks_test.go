@@ -0,0 +1,59 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/zintix-labs/problab-scaffold/internal/prng"
+)
+
+func TestSamplePicks(t *testing.T) {
+	g, err := samplePicks("demo_1.yaml", prng.Xoshiro256, 3, 5000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep := &picksReport{PRNG: prng.Xoshiro256.Name(), Seed: 3, Screens: 5000, Alpha: 0.001, Games: []picksGame{*g}}
+	judgePicks(rep)
+	// per mode: the reel set selection, then 2 reel sets of 5 reels
+	if len(g.Modes) != 2 || rep.Checks != 22 || !rep.Pass || rep.Threshold != rep.Alpha/22 {
+		t.Fatalf("report %+v", rep)
+	}
+
+	// a check that picked a weight-0 stop fails the run
+	g.Modes[1].Checks[4].ZeroWeight = 1
+	judgePicks(rep)
+	if rep.Pass || rep.Deviating != 1 {
+		t.Fatalf("deviating %d", rep.Deviating)
+	}
+
+	var out bytes.Buffer
+	stdOutPicks(&out, rep)
+	s := out.String()
+	if !strings.Contains(s, "reel set selection") || !strings.Contains(s, "FAIL: 1 picks of weight 0") || !strings.Contains(s, "FAIL: 1 of 22 checks") {
+		t.Fatalf("picks table:\n%s", s)
+	}
+	out.Reset()
+	if err := writePicksCSV(&out, rep); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil || len(rows) != 23 || rows[16][3] != "1" || rows[16][14] != "1" || rows[16][16] != "true" {
+		t.Fatalf("picks csv %v, %v", rows, err)
+	}
+}
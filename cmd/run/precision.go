@@ -0,0 +1,103 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/zintix-labs/problab"
+	"github.com/zintix-labs/problab-scaffold/internal/simstat"
+	"github.com/zintix-labs/problab/stats"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// precisionInfo describes the statistical quality of an RTP estimate.
+type precisionInfo struct {
+	Confidence      float64  `json:"confidence"            yaml:"confidence"`
+	CI              stats.CI `json:"rtp_ci"                yaml:"rtp_ci"`
+	Width           float64  `json:"ci_width"              yaml:"ci_width"`
+	Target          float64  `json:"target_width,omitzero" yaml:"target_width,omitempty"`
+	TargetSpins     int      `json:"target_spins,omitzero" yaml:"target_spins,omitempty"` // estimated spins for Target
+	Converged       bool     `json:"converged"             yaml:"converged"`
+	Chunks          int      `json:"chunks,omitzero"       yaml:"chunks,omitempty"`
+	StdPerSpin      float64  `json:"std_per_spin"          yaml:"std_per_spin"`
+	VolatilityIndex float64  `json:"volatility_index"      yaml:"volatility_index"`
+}
+
+func newPrecisionInfo(st *stats.StatReport, confidence float64) *precisionInfo {
+	ci := simstat.CI(st, confidence)
+	return &precisionInfo{
+		Confidence:      confidence,
+		CI:              ci,
+		Width:           ci.Hi - ci.Lo,
+		StdPerSpin:      st.Std(),
+		VolatilityIndex: simstat.VolatilityIndex(st, confidence),
+	}
+}
+
+// runPrecision keeps simulating in chunks until the RTP interval at -confidence is
+// no wider than -target-precision, or -max-spins is reached.
+//
+// Each chunk continues the same machines (and therefore the same PRNG streams), so a
+// precision run is reproducible from its seed just like a fixed-size run. Cancelling ctx
+// ends the run with the spins of the current chunk done so far.
+func runPrecision(ctx context.Context, lab *problab.Problab, w io.Writer) (*stats.StatReport, *runner, *precisionInfo, time.Duration, error) {
+	p := message.NewPrinter(language.English)
+	r, err := newRunner(lab, cfg.id, cfg.betMode, cfg.seed, cfg.worker)
+	if err != nil {
+		return nil, nil, nil, 0, err
+	}
+	live.plan("spins", cfg.maxSpins, nil)
+	live.track(cfg.name, cfg.id, cfg.betMode, cfg.maxSpins, r.counts)
+	var (
+		st   *stats.StatReport
+		info *precisionInfo
+	)
+	for chunk := 1; ; chunk++ {
+		if st, _, err = r.run(ctx, chunk*cfg.chunk); err != nil {
+			return nil, nil, nil, 0, err
+		}
+		info = newPrecisionInfo(st, cfg.confidence)
+		info.Target = cfg.precision
+		info.TargetSpins = simstat.SpinsForWidth(st, cfg.confidence, cfg.precision)
+		info.Chunks = chunk
+		info.Converged = info.Width <= cfg.precision
+		p.Fprintf(w, "[chunk %d] spins: %d  rtp: %.4f%%  ci: [%.4f%%, %.4f%%]  width: %.4f%% (target %.4f%%, est. %d spins)\n",
+			chunk, st.Summary.Rounds, 100*st.Rtp(), 100*info.CI.Lo, 100*info.CI.Hi, 100*info.Width, 100*cfg.precision, info.TargetSpins)
+
+		if info.Converged || ctx.Err() != nil {
+			break
+		}
+		if st.Summary.Rounds+cfg.chunk*cfg.worker > cfg.maxSpins {
+			p.Fprintf(w, "max spins reached (%d) before target precision (est. %d spins needed)\n", cfg.maxSpins, info.TargetSpins)
+			break
+		}
+	}
+	return st, r, info, r.elapsed, nil
+}
+
+// stdOutPrecision prints the interval table that follows the StatReport in text mode.
+func stdOutPrecision(info *precisionInfo) {
+	p := message.NewPrinter(language.English)
+	p.Printf("rtp ci           : [%.4f%%, %.4f%%] @ %.4g%% (width %.4f%%)\n", 100*info.CI.Lo, 100*info.CI.Hi, 100*info.Confidence, 100*info.Width)
+	if info.Target > 0 {
+		p.Printf("target width     : %.4f%% (converged: %t, chunks: %d, est. spins: %d)\n", 100*info.Target, info.Converged, info.Chunks, info.TargetSpins)
+	}
+	p.Printf("std per spin     : %.4f\n", info.StdPerSpin)
+	p.Printf("volatility index : %.4f\n", info.VolatilityIndex)
+}
@@ -0,0 +1,328 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zintix-labs/problab/recorder"
+	"github.com/zintix-labs/problab/spec"
+)
+
+// Run states reported by the progress endpoint and file.
+const (
+	progressRunning     = "running"
+	progressDone        = "done"
+	progressInterrupted = "interrupted"
+)
+
+// progressCounts are the running totals of one stream of spins.
+type progressCounts struct {
+	spins    int
+	bet      int
+	win      int
+	hits     int
+	triggers int
+	modeWin  []int // win per game mode id; nil when not tracked
+}
+
+func (c *progressCounts) add(o progressCounts) {
+	c.spins += o.spins
+	c.bet += o.bet
+	c.win += o.win
+	c.hits += o.hits
+	c.triggers += o.triggers
+	for id, v := range o.modeWin {
+		for len(c.modeWin) <= id {
+			c.modeWin = append(c.modeWin, 0)
+		}
+		c.modeWin[id] += v
+	}
+}
+
+// recCounts reads the totals of a recorder. It must be called by the goroutine that
+// records into rec.
+func recCounts(rec *recorder.SpinRecorder) progressCounts {
+	b := rec.Basic
+	return progressCounts{
+		spins:    b.Rounds,
+		bet:      b.TotalBet,
+		win:      b.TotalWin,
+		hits:     b.Rounds - rec.Dist.TotalWinCollect[0],
+		triggers: b.Trigger,
+	}
+}
+
+// liveCounts is a copy of a worker's totals that other goroutines may read while the
+// worker keeps spinning. Workers publish into it every few thousand spins.
+type liveCounts struct {
+	mu sync.Mutex
+	c  progressCounts
+}
+
+func (l *liveCounts) set(c progressCounts) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.c.spins, l.c.bet, l.c.win, l.c.hits, l.c.triggers = c.spins, c.bet, c.win, c.hits, c.triggers
+	l.c.modeWin = append(l.c.modeWin[:0], c.modeWin...)
+}
+
+func (l *liveCounts) add(c progressCounts) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.c.add(c)
+}
+
+// get returns a copy of the published totals.
+func (l *liveCounts) get() progressCounts {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	c := l.c
+	c.modeWin = append([]int(nil), c.modeWin...)
+	return c
+}
+
+// progressStream is one (game, bet mode) being simulated.
+type progressStream struct {
+	game    string
+	gid     spec.GID
+	betMode int
+	planned int // spins; 0 when not known in advance
+	counts  func() progressCounts
+}
+
+// progressMode is the running state of one stream.
+type progressMode struct {
+	Game        string    `json:"game"`
+	GameID      spec.GID  `json:"game_id"`
+	BetMode     int       `json:"bet_mode"`
+	Spins       int       `json:"spins"`
+	Planned     int       `json:"planned,omitempty"`
+	RTP         float64   `json:"rtp"`
+	HitRate     float64   `json:"hit_rate"`
+	TriggerRate float64   `json:"trigger_rate"`
+	GameModeRTP []float64 `json:"game_mode_rtp,omitempty"` // RTP contributed by each game mode id
+}
+
+// progressReport is what the progress endpoint serves and each line of the progress file.
+type progressReport struct {
+	Status      string         `json:"status"` // running|done|interrupted
+	Time        time.Time      `json:"time"`
+	Unit        string         `json:"unit"` // spins, sessions or shards
+	Done        int            `json:"done"`
+	Planned     int            `json:"planned"`
+	Spins       int            `json:"spins"`
+	ElapsedSec  float64        `json:"elapsed_sec"`
+	SpinsPerSec float64        `json:"spins_per_sec"`
+	ETASec      *float64       `json:"eta_sec"` // null until the rate is known
+	ETA         *time.Time     `json:"eta"`
+	Modes       []progressMode `json:"modes"`
+}
+
+// progressTracker follows a run for -progress-addr and -progress-file. A nil tracker
+// ignores every call, so run modes report to it unconditionally.
+//
+// ETA is the remaining units over the rate of this process: spins for machine and batch
+// runs (for -target-precision, up to the -max-spins budget), sessions for player runs
+// and shards for a coordinator.
+type progressTracker struct {
+	mu      sync.Mutex
+	start   time.Time
+	unit    string
+	planned int
+	done    func() int // nil: units are the spins of all streams
+	streams []*progressStream
+	base    int // spins already done when tracking started (resumed runs)
+	status  string
+	final   chan struct{} // closed by finish
+	flushed chan struct{} // closed once the last line is written
+}
+
+// live is the tracker of this process; nil unless -progress-addr or -progress-file is set.
+var live *progressTracker
+
+// startProgress serves the progress of the run on -progress-addr and appends it to
+// -progress-file every -progress-every until finish.
+func startProgress(w io.Writer) (*progressTracker, error) {
+	if cfg.progressAddr == "" && cfg.progressFile == "" {
+		return nil, nil
+	}
+	t := &progressTracker{start: time.Now(), unit: "spins", status: progressRunning, final: make(chan struct{}), flushed: make(chan struct{})}
+	if cfg.progressAddr != "" {
+		ln, err := net.Listen("tcp", cfg.progressAddr)
+		if err != nil {
+			return nil, fmt.Errorf("progress endpoint: %w", err)
+		}
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /progress", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(t.report())
+		})
+		go (&http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}).Serve(ln)
+		fmt.Fprintf(w, "progress on http://%s/progress\n", ln.Addr())
+	}
+	if cfg.progressFile != "" {
+		f, err := os.OpenFile(cfg.progressFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("progress file: %w", err)
+		}
+		go t.writeLines(f, cfg.progressEvery)
+	}
+	return t, nil
+}
+
+// loopbackAddr resolves -progress-addr. The endpoint is unauthenticated, so it is served
+// to this machine only: a bare port (":5810" or "5810") listens on 127.0.0.1, and any
+// host other than localhost or a loopback IP is refused.
+func loopbackAddr(addr string) (string, error) {
+	resolved, loopback, err := listenAddr(addr)
+	if err != nil {
+		return "", err
+	}
+	if !loopback {
+		host, _, _ := net.SplitHostPort(resolved)
+		return "", fmt.Errorf("%s is not a loopback host: progress is served on 127.0.0.1, ::1 or localhost only", host)
+	}
+	return resolved, nil
+}
+
+// listenAddr resolves a listen address, a bare port (":5810" or "5810") listening on
+// 127.0.0.1, and reports whether it is reachable from this machine only.
+func listenAddr(addr string) (resolved string, loopback bool, err error) {
+	if _, err := strconv.Atoi(addr); err == nil {
+		addr = ":" + addr
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", false, err
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	ip := net.ParseIP(host)
+	return net.JoinHostPort(host, port), host == "localhost" || (ip != nil && ip.IsLoopback()), nil
+}
+
+// writeLines appends one JSON line every interval and a last one at finish.
+func (t *progressTracker) writeLines(f *os.File, every time.Duration) {
+	defer close(t.flushed)
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	tick := time.NewTicker(every)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			enc.Encode(t.report())
+		case <-t.final:
+			enc.Encode(t.report())
+			return
+		}
+	}
+}
+
+// plan sets the unit and planned amount of the run; done counts finished units when the
+// unit is not spins.
+func (t *progressTracker) plan(unit string, planned int, done func() int) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.unit, t.planned, t.done = unit, planned, done
+}
+
+// track adds a stream. Work the stream already holds (a resumed checkpoint) is left out
+// of the rate.
+func (t *progressTracker) track(game string, gid spec.GID, betMode int, planned int, counts func() progressCounts) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.streams = append(t.streams, &progressStream{game: game, gid: gid, betMode: betMode, planned: planned, counts: counts})
+	t.base += counts().spins
+}
+
+// finish records the final status and writes the last progress line. The endpoint keeps
+// serving the final state until the process exits.
+func (t *progressTracker) finish(interrupted bool) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	if t.status != progressRunning {
+		t.mu.Unlock()
+		return
+	}
+	t.status = progressDone
+	if interrupted {
+		t.status = progressInterrupted
+	}
+	t.mu.Unlock()
+	if cfg.progressFile != "" {
+		close(t.final)
+		<-t.flushed
+	}
+}
+
+func (t *progressTracker) report() *progressReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	rep := &progressReport{Status: t.status, Time: now, Unit: t.unit, Planned: t.planned, ElapsedSec: now.Sub(t.start).Seconds(), Modes: []progressMode{}}
+	for _, s := range t.streams {
+		c := s.counts()
+		rep.Spins += c.spins
+		m := progressMode{Game: s.game, GameID: s.gid, BetMode: s.betMode, Spins: c.spins, Planned: s.planned}
+		if c.spins > 0 {
+			m.HitRate = float64(c.hits) / float64(c.spins)
+			m.TriggerRate = float64(c.triggers) / float64(c.spins)
+		}
+		if c.bet > 0 {
+			m.RTP = float64(c.win) / float64(c.bet)
+			for _, v := range c.modeWin {
+				m.GameModeRTP = append(m.GameModeRTP, float64(v)/float64(c.bet))
+			}
+		}
+		rep.Modes = append(rep.Modes, m)
+	}
+	rep.Done = rep.Spins
+	if t.done != nil {
+		rep.Done = t.done()
+	}
+	if rep.ElapsedSec <= 0 {
+		return rep
+	}
+	rep.SpinsPerSec = float64(rep.Spins-t.base) / rep.ElapsedSec
+	base := t.base
+	if t.done != nil {
+		base = 0
+	}
+	if rate := float64(rep.Done-base) / rep.ElapsedSec; t.status == progressRunning && rate > 0 && rep.Planned > rep.Done {
+		eta := float64(rep.Planned-rep.Done) / rate
+		at := now.Add(time.Duration(eta * float64(time.Second))).Round(time.Second)
+		rep.ETASec, rep.ETA = &eta, &at
+	}
+	return rep
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+	"github.com/zintix-labs/problab/spec"
+)
+
+func TestProgressReport(t *testing.T) {
+	lab, err := engine.New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	r, err := newRunner(lab, spec.GID(0), 0, 42, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, _, err := r.run(context.Background(), 5000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the published totals of a finished run match its report
+	tr := &progressTracker{start: time.Now().Add(-10 * time.Second), status: progressRunning}
+	tr.plan("spins", 30000, nil)
+	tr.track("demo", spec.GID(0), 0, 30000, r.counts)
+	rep := tr.report()
+	m := rep.Modes[0]
+	if rep.Done != 10000 || m.Spins != 10000 || m.RTP != st.Rtp() || math.Abs(m.HitRate-st.Summary.HitRate) > 1e-12 || m.TriggerRate != st.Summary.TriggerRate {
+		t.Fatalf("progress %+v, report rtp %v hit %v trigger %v", m, st.Rtp(), st.Summary.HitRate, st.Summary.TriggerRate)
+	}
+	modeRTP := 0.0
+	for _, v := range m.GameModeRTP {
+		modeRTP += v
+	}
+	if math.Abs(modeRTP-m.RTP) > 1e-12 {
+		t.Fatalf("game mode rtp sums to %v, want %v", modeRTP, m.RTP)
+	}
+	// spins held before tracking started (a resumed run) do not count toward the rate
+	if rep.SpinsPerSec != 0 || rep.ETASec != nil {
+		t.Fatalf("resumed spins counted in the rate: %v spins/s, eta %v", rep.SpinsPerSec, rep.ETASec)
+	}
+
+	// 10 sessions in 10s with 30 planned: 20s to go
+	tr = &progressTracker{start: time.Now().Add(-10 * time.Second), status: progressRunning}
+	tr.plan("sessions", 30, func() int { return 10 })
+	tr.track("demo", spec.GID(0), 0, 0, r.counts)
+	if rep = tr.report(); rep.ETASec == nil || math.Abs(*rep.ETASec-20) > 0.5 || rep.Unit != "sessions" {
+		t.Fatalf("eta %v unit %s", rep.ETASec, rep.Unit)
+	}
+	tr.finish(true)
+	if rep = tr.report(); rep.Status != progressInterrupted || rep.ETASec != nil {
+		t.Fatalf("finished: status %s eta %v", rep.Status, rep.ETASec)
+	}
+
+	var none *progressTracker
+	none.plan("spins", 1, nil)
+	none.track("demo", spec.GID(0), 0, 1, r.counts)
+	none.finish(false)
+}
+
+func TestLoopbackAddr(t *testing.T) {
+	for addr, want := range map[string]string{
+		"5810":           "127.0.0.1:5810",
+		":5810":          "127.0.0.1:5810",
+		"127.0.0.1:5810": "127.0.0.1:5810",
+		"127.8.0.1:0":    "127.8.0.1:0",
+		"[::1]:5810":     "[::1]:5810",
+		"localhost:5810": "localhost:5810",
+	} {
+		if got, err := loopbackAddr(addr); err != nil || got != want {
+			t.Errorf("loopbackAddr(%q) = %q, %v; want %q", addr, got, err, want)
+		}
+	}
+	for _, addr := range []string{"0.0.0.0:5810", "[::]:5810", "10.1.2.3:5810", "example.com:5810", "5810:"} {
+		if got, err := loopbackAddr(addr); err == nil {
+			t.Errorf("loopbackAddr(%q) = %q, want an error", addr, got)
+		}
+	}
+	// the coordinator may listen elsewhere, with a token
+	for addr, want := range map[string]bool{":5809": true, "0.0.0.0:5809": false, "10.1.2.3:5809": false, "[::1]:5809": true} {
+		if _, loopback, err := listenAddr(addr); err != nil || loopback != want {
+			t.Errorf("listenAddr(%q): loopback %t, %v; want %t", addr, loopback, err, want)
+		}
+	}
+}
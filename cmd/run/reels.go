@@ -0,0 +1,258 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/zintix-labs/problab-scaffold/internal/reels"
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+	"github.com/zintix-labs/problab/spec"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// reelsConfig holds the flags of the `reels` subcommand.
+type reelsConfig struct {
+	id      spec.GID
+	config  string
+	out     string
+	outFile string
+}
+
+// reelsMode is the inspection of one game mode.
+type reelsMode struct {
+	GameMode      int    `json:"game_mode"         yaml:"game_mode"`
+	Skipped       string `json:"skipped,omitempty" yaml:"skipped,omitempty"` // why its reels are not inspected
+	*reels.Report `json:",inline,omitempty" yaml:",inline,omitempty"`
+}
+
+// reelsReport is what `reels` writes.
+type reelsReport struct {
+	Game      string      `json:"game"      yaml:"game"`
+	GameID    spec.GID    `json:"game_id"   yaml:"game_id"`
+	Config    string      `json:"config"    yaml:"config"`
+	SHA256    string      `json:"sha256"    yaml:"sha256"`
+	Modes     []reelsMode `json:"modes"     yaml:"modes"`
+	Anomalies int         `json:"anomalies" yaml:"anomalies"` // over every game mode
+}
+
+// runReels reports the reel strips of a config: per reel set and reel, the count and
+// weighted landing probability of every symbol, the probability that it shows in the
+// window of `rows` symbols, and the smallest spacing of identical symbols. Reels that
+// can show one scatter twice, are shorter than the window or hold symbols that never
+// show are flagged.
+func runReels(args []string) {
+	rc := new(reelsConfig)
+	fs := flag.NewFlagSet("reels", flag.ExitOnError)
+	fs.Var(gidFlag{&rc.id}, "game", "target game id")
+	fs.StringVar(&rc.config, "config", armEmbedded, `config: "embedded" or a .yaml/.json config file of -game`)
+	fs.StringVar(&rc.out, "out", "", "report format: text|json|csv|yaml (default text, or inferred from -o)")
+	fs.StringVar(&rc.outFile, "o", "", "write the report to this file instead of stdout")
+	fs.Parse(args)
+
+	oc := &config{out: rc.out, outFile: rc.outFile}
+	format, err := oc.outFormat()
+	if err != nil {
+		log.Fatal("value err : " + err.Error())
+	}
+	lab := engine.MustNew()
+	ent, ok := lab.EntryById(rc.id)
+	if !ok {
+		log.Fatalf("value err : game id not found: %d", rc.id)
+	}
+	arm, err := loadArm(rc.config, ent.ConfigName, rc.id, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+	rep := inspectReels(arm)
+	rep.Game, rep.GameID = ent.Name, rc.id
+
+	if format == outText && rc.outFile == "" {
+		stdOutReels(os.Stdout, rep)
+	} else if err := writeReport(rep, format, rc.outFile); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// inspectReels inspects the reels of every game mode of the config.
+func inspectReels(arm *compareArm) *reelsReport {
+	rep := &reelsReport{Config: arm.Source, SHA256: arm.SHA256}
+	for i := range arm.gs.GameModeSettings {
+		m := reelsMode{GameMode: i}
+		if r, err := reels.Inspect(&arm.gs.GameModeSettings[i]); err != nil {
+			m.Skipped = err.Error()
+		} else {
+			m.Report = r
+			rep.Anomalies += len(r.Anomalies)
+		}
+		rep.Modes = append(rep.Modes, m)
+	}
+	return rep
+}
+
+// stdOutReels prints the inspection in text mode: per reel set, the symbol counts and
+// the visibility of every symbol by reel, then the spacing of the special symbols.
+func stdOutReels(out io.Writer, rep *reelsReport) {
+	p := message.NewPrinter(language.English)
+	p.Fprintf(out, "config      : %s (sha256 %.12s)\n", rep.Config, rep.SHA256)
+	for _, m := range rep.Modes {
+		if m.Report == nil {
+			p.Fprintf(out, "\ngame mode %d : skipped (%s)\n", m.GameMode, m.Skipped)
+			continue
+		}
+		for _, set := range m.ReelSets {
+			p.Fprintf(out, "\ngame mode %d : reel set %d (weight %d), %d reels, window of %d rows\n", m.GameMode, set.Index, set.Weight, len(set.Reels), m.Rows)
+			tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+			header := "SYMBOL\t"
+			for _, r := range set.Reels {
+				header += fmt.Sprintf("R%d COUNT\tR%d VISIBLE\t", r.Index+1, r.Index+1)
+			}
+			fmt.Fprintln(tw, header)
+			for id, sym := range m.Symbols {
+				if !onReels(set, id) {
+					continue
+				}
+				row := sym + "\t"
+				for _, r := range set.Reels {
+					s := r.Symbols[id]
+					row += p.Sprintf("%d\t%.2f%%\t", s.Count, 100*s.Visible)
+				}
+				fmt.Fprintln(tw, row)
+			}
+			row := "STOPS\t"
+			for _, r := range set.Reels {
+				weighted := ""
+				if r.Weighted {
+					weighted = "w"
+				}
+				row += p.Sprintf("%d%s\t\t", r.Length, weighted)
+			}
+			fmt.Fprintln(tw, row)
+			tw.Flush()
+
+			tw = tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+			fmt.Fprintln(tw, "SPECIAL\tREEL\tCOUNT\tP(STOP)\tVISIBLE\tSTACKED\tMIN GAP\t")
+			for _, r := range set.Reels {
+				for _, s := range r.Symbols {
+					if !s.Special || s.Count == 0 {
+						continue
+					}
+					p.Fprintf(tw, "%s\t%d\t%d\t%.4f%%\t%.4f%%\t%.4f%%\t%d\t\n", s.Symbol, r.Index+1, s.Count, 100*s.P, 100*s.Visible, 100*s.Stacked, s.MinGap)
+				}
+			}
+			tw.Flush()
+		}
+	}
+	p.Fprintf(out, "\nanomalies   : %d\n", rep.Anomalies)
+	for _, m := range rep.Modes {
+		if m.Report == nil {
+			continue
+		}
+		for _, a := range m.Anomalies {
+			p.Fprintf(out, "\033[1;31m[%s]\033[0m game mode %d %s\n", a.Kind, m.GameMode, a)
+		}
+	}
+	p.Fprintf(out, "(VISIBLE: P(at least one in the window); STACKED: P(two or more); MIN GAP: closest two stops, 1 = adjacent; w: weighted stops)\n")
+}
+
+// onReels reports whether symbol id is on any reel of set.
+func onReels(set reels.ReelSet, id int) bool {
+	for _, r := range set.Reels {
+		if r.Symbols[id].Count > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// writeReelsCSV writes the inspection in long format (section,key,value); keys are
+// prefixed with the game mode, reel set and reel, e.g. m0.set0.reel2.C1.visible.
+func writeReelsCSV(w io.Writer, r *reelsReport) error {
+	cw := csv.NewWriter(w)
+	f := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	i := strconv.Itoa
+	rows := [][]string{
+		{"section", "key", "value"},
+		{"game", "game", r.Game},
+		{"game", "game_id", fmt.Sprint(uint(r.GameID))},
+		{"game", "config", r.Config},
+		{"game", "config_sha256", r.SHA256},
+		{"game", "anomalies", i(r.Anomalies)},
+	}
+	for _, m := range r.Modes {
+		k := "m" + i(m.GameMode) + "."
+		if m.Report == nil {
+			rows = append(rows, []string{"mode", k + "skipped", m.Skipped})
+			continue
+		}
+		rows = append(rows,
+			[]string{"mode", k + "screen", fmt.Sprintf("%dx%d", m.Columns, m.Rows)},
+			[]string{"mode", k + "reel_sets", i(len(m.ReelSets))},
+		)
+		for _, set := range m.ReelSets {
+			sk := k + "set" + i(set.Index) + "."
+			rows = append(rows, []string{"reel_set", sk + "weight", i(set.Weight)})
+			for _, reel := range set.Reels {
+				rk := sk + "reel" + i(reel.Index) + "."
+				rows = append(rows,
+					[]string{"reel", rk + "length", i(reel.Length)},
+					[]string{"reel", rk + "total_weight", i(reel.TotalWeight)},
+					[]string{"reel", rk + "weighted", strconv.FormatBool(reel.Weighted)},
+				)
+				for _, s := range reel.Symbols {
+					if s.Count == 0 {
+						continue
+					}
+					yk := rk + s.Symbol + "."
+					rows = append(rows,
+						[]string{"symbol", yk + "count", i(s.Count)},
+						[]string{"symbol", yk + "p", f(s.P)},
+						[]string{"symbol", yk + "visible", f(s.Visible)},
+						[]string{"symbol", yk + "stacked", f(s.Stacked)},
+						[]string{"symbol", yk + "min_gap", i(s.MinGap)},
+					)
+				}
+			}
+		}
+		for ai, a := range m.Anomalies {
+			ak := k + "a" + i(ai) + "."
+			stops := make([]string, len(a.Stops))
+			for j, s := range a.Stops {
+				stops[j] = i(s)
+			}
+			rows = append(rows,
+				[]string{"anomaly", ak + "kind", a.Kind},
+				[]string{"anomaly", ak + "reel_set", i(a.ReelSet)},
+				[]string{"anomaly", ak + "reel", i(a.Reel)},
+				[]string{"anomaly", ak + "symbol", a.Symbol},
+				[]string{"anomaly", ak + "stops", strings.Join(stops, " ")},
+				[]string{"anomaly", ak + "message", a.Message},
+			)
+		}
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
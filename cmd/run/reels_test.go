@@ -0,0 +1,52 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zintix-labs/problab-scaffold/internal/reels"
+	"github.com/zintix-labs/problab/spec"
+)
+
+func TestInspectReels(t *testing.T) {
+	arm, err := loadArm(armEmbedded, "demo_1.yaml", spec.GID(1), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep := inspectReels(arm)
+	if len(rep.Modes) != 2 || rep.Modes[0].Report == nil || rep.Anomalies != 1 {
+		t.Fatalf("report %+v", rep)
+	}
+	if a := rep.Modes[0].Anomalies[0]; a.Kind != reels.ScatterStack || a.ReelSet != 1 || a.Reel != 0 || a.Symbol != "C1" {
+		t.Fatalf("anomaly %+v", a)
+	}
+
+	var text, csv bytes.Buffer
+	stdOutReels(&text, rep)
+	if !strings.Contains(text.String(), "R3 VISIBLE") || !strings.Contains(text.String(), "[scatter_stack]") {
+		t.Fatalf("text:\n%s", text.String())
+	}
+	if err := writeReelsCSV(&csv, rep); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"symbol,m0.set0.reel3.C1.count,3", "symbol,m0.set0.reel2.C1.min_gap,10", "anomaly,m0.a0.kind,scatter_stack"} {
+		if !strings.Contains(csv.String(), want) {
+			t.Errorf("csv has no %q", want)
+		}
+	}
+}
@@ -0,0 +1,371 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/zintix-labs/problab"
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+	"github.com/zintix-labs/problab/dto"
+	"github.com/zintix-labs/problab/sdk/buf"
+	"github.com/zintix-labs/problab/spec"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// replayConfig holds the flags of the `replay` subcommand.
+type replayConfig struct {
+	id       spec.GID
+	betMode  int
+	seed     int64
+	stream   int
+	spin     int
+	state    string
+	dump     string
+	find     string
+	maxSpins int
+	json     bool
+}
+
+// replayResult is what `replay -json` prints.
+type replayResult struct {
+	Game   string         `json:"game"`
+	GameID spec.GID       `json:"game_id"`
+	Seed   int64          `json:"seed,omitempty"`
+	Stream int            `json:"stream"`
+	Spin   int            `json:"spin"`
+	State  string         `json:"state"` // base64 PRNG state right before the spin
+	Result dto.SpinResult `json:"result"`
+}
+
+// runReplay rebuilds one spin of a simulation and prints every act of it.
+//
+// A spin is addressed by (-game, -mode, -seed, -stream, -spin): stream k is worker k of a
+// `-worker`/`SimMP` run (or shard k of a distributed run) with that seed, and spin is the
+// 0-based index within that stream. Alternatively -state loads a PRNG state written by
+// -dump-state and replays the spin right after it.
+//
+// The machine is fast-forwarded in simulation mode, then its PRNG state is moved into a
+// non-simulation machine for the replayed spin, so ext snapshots that logic skips in
+// simulation are filled in.
+func runReplay(args []string) {
+	rc := new(replayConfig)
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	fs.Var(gidFlag{&rc.id}, "game", "target game id")
+	fs.IntVar(&rc.betMode, "mode", 0, "bet mode index")
+	fs.Int64Var(&rc.seed, "seed", 0, "seed of the simulation to replay")
+	fs.IntVar(&rc.stream, "stream", 0, "worker/shard index of the simulation (0 for -worker 1)")
+	fs.IntVar(&rc.spin, "spin", 0, "0-based spin index within the stream")
+	fs.StringVar(&rc.state, "state", "", "replay the spin right after this PRNG state dump instead of -seed/-spin")
+	fs.StringVar(&rc.dump, "dump-state", "", "write the PRNG state before the replayed spin to this file")
+	fs.StringVar(&rc.find, "find", "", `replay the first spin matching a predicate, e.g. "win>1000x", "trigger", "trigger,win>=50x"`)
+	fs.IntVar(&rc.maxSpins, "max-spins", 100000000, "give up -find after this many spins")
+	fs.BoolVar(&rc.json, "json", false, "print the spin as JSON")
+	fs.Parse(args)
+
+	if rc.state == "" && rc.seed < 1 {
+		log.Fatal("value err : replay needs -seed (>0) or -state")
+	}
+	if rc.stream < 0 || rc.spin < 0 {
+		log.Fatal("value err : stream and spin must >= 0")
+	}
+	var pred spinPredicate
+	if rc.find != "" {
+		var err error
+		if pred, err = parsePredicate(rc.find); err != nil {
+			log.Fatal("value err : " + err.Error())
+		}
+	}
+
+	lab := engine.MustNew()
+	ent, ok := lab.EntryById(rc.id)
+	if !ok {
+		log.Fatalf("value err : game id not found: %d", rc.id)
+	}
+	gs, err := engine.GameSetting(ent.ConfigName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if rc.betMode < 0 || rc.betMode >= len(gs.BetUnits) {
+		log.Fatal("value err : bet mode must >= 0 and < len(bet_units)")
+	}
+
+	p := message.NewPrinter(language.English)
+	state, spin, err := rc.locate(lab, pred)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if pred != nil {
+		p.Fprintf(os.Stderr, "first match of %q: stream %d spin %d\n", rc.find, rc.stream, spin)
+	}
+	if rc.dump != "" {
+		if err := os.WriteFile(rc.dump, []byte(base64.StdEncoding.EncodeToString(state)+"\n"), 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	sr, want, err := replaySpin(lab, rc.id, rc.betMode, state)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if sr.TotalWin != want {
+		p.Fprintf(os.Stderr, "warning: replayed win %d differs from the simulated win %d; the logic draws random numbers differently outside simulation\n", sr.TotalWin, want)
+	}
+
+	res := &replayResult{
+		Game:   ent.Name,
+		GameID: rc.id,
+		Stream: rc.stream,
+		Spin:   spin,
+		State:  base64.StdEncoding.EncodeToString(state),
+		Result: dto.NewSpinResultDTO(sr),
+	}
+	if rc.state == "" {
+		res.Seed = rc.seed
+	}
+	if rc.json {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(res); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	printSpin(os.Stdout, res, gs)
+}
+
+// locate returns the PRNG state right before the target spin and the spin index.
+func (rc *replayConfig) locate(lab *problab.Problab, pred spinPredicate) ([]byte, int, error) {
+	if rc.state != "" {
+		raw, err := os.ReadFile(rc.state)
+		if err != nil {
+			return nil, 0, err
+		}
+		// dumps are base64 text; raw snapshot bytes are accepted as well
+		if dec, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(raw))); err == nil {
+			raw = dec
+		}
+		if pred == nil {
+			return raw, 0, nil
+		}
+		m, err := lab.NewMachineWithSeed(rc.id, 1, true)
+		if err != nil {
+			return nil, 0, err
+		}
+		if err := m.RestoreCore(raw); err != nil {
+			return nil, 0, err
+		}
+		return rc.search(m, pred)
+	}
+
+	m, err := lab.NewMachineWithSeed(rc.id, shardSeeds(rc.seed, rc.stream+1)[rc.stream], true)
+	if err != nil {
+		return nil, 0, err
+	}
+	if pred != nil {
+		return rc.search(m, pred)
+	}
+	for range rc.spin {
+		m.SpinInternal(rc.betMode)
+	}
+	state, err := m.SnapshotCore()
+	return state, rc.spin, err
+}
+
+// search spins m until pred matches and returns the state before the matching spin.
+//
+// States are not kept per spin (that would slow the search down several times); instead
+// the matching spin is found first and the stream is then rewound to it once.
+func (rc *replayConfig) search(m *problab.Machine, pred spinPredicate) ([]byte, int, error) {
+	start, err := m.SnapshotCore()
+	if err != nil {
+		return nil, 0, err
+	}
+	hit := -1
+	for i := range rc.maxSpins {
+		if pred(m.SpinInternal(rc.betMode)) {
+			hit = i
+			break
+		}
+	}
+	if hit < 0 {
+		return nil, 0, fmt.Errorf("no spin matching %q within %d spins", rc.find, rc.maxSpins)
+	}
+	if err := m.RestoreCore(start); err != nil {
+		return nil, 0, err
+	}
+	for range hit {
+		m.SpinInternal(rc.betMode)
+	}
+	state, err := m.SnapshotCore()
+	return state, hit, err
+}
+
+// replaySpin runs one spin from state on a simulation machine (for the reference win) and
+// on a non-simulation machine (for the full result with ext snapshots).
+func replaySpin(lab *problab.Problab, id spec.GID, betMode int, state []byte) (*buf.SpinResult, int, error) {
+	sim, err := lab.NewMachineWithSeed(id, 1, true)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := sim.RestoreCore(state); err != nil {
+		return nil, 0, err
+	}
+	want := sim.SpinInternal(betMode).TotalWin
+
+	m, err := lab.NewMachineWithSeed(id, 1, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := m.RestoreCore(state); err != nil {
+		return nil, 0, err
+	}
+	return m.SpinInternal(betMode), want, nil
+}
+
+// spinPredicate selects spins for `replay -find`.
+type spinPredicate func(sr *buf.SpinResult) bool
+
+// parsePredicate parses a comma-separated list of conditions that must all hold:
+//
+//	trigger | free      a free game was triggered
+//	nowin               the spin paid nothing
+//	win<op><n>[x]       total win compared with n credits, or n times the bet with the x suffix
+//
+// where <op> is one of > >= < <= ==.
+func parsePredicate(s string) (spinPredicate, error) {
+	var preds []spinPredicate
+	for _, term := range strings.Split(s, ",") {
+		term = strings.ToLower(strings.ReplaceAll(term, " ", ""))
+		switch {
+		case term == "trigger" || term == "free":
+			preds = append(preds, func(sr *buf.SpinResult) bool { return sr.GameModeCount > 1 })
+		case term == "nowin":
+			preds = append(preds, func(sr *buf.SpinResult) bool { return sr.TotalWin == 0 })
+		case strings.HasPrefix(term, "win"):
+			p, err := parseWinTerm(strings.TrimPrefix(term, "win"))
+			if err != nil {
+				return nil, fmt.Errorf("bad predicate %q: %w", term, err)
+			}
+			preds = append(preds, p)
+		default:
+			return nil, fmt.Errorf("unknown predicate %q (use trigger, nowin or win>N[x])", term)
+		}
+	}
+	return func(sr *buf.SpinResult) bool {
+		for _, p := range preds {
+			if !p(sr) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func parseWinTerm(s string) (spinPredicate, error) {
+	var op string
+	for _, o := range []string{">=", "<=", "==", ">", "<"} {
+		if strings.HasPrefix(s, o) {
+			op = o
+			break
+		}
+	}
+	if op == "" {
+		return nil, fmt.Errorf("missing comparison operator")
+	}
+	num := strings.TrimPrefix(s, op)
+	mult := strings.HasSuffix(num, "x")
+	v, err := strconv.ParseFloat(strings.TrimSuffix(num, "x"), 64)
+	if err != nil {
+		return nil, err
+	}
+	cmp := map[string]func(a, b float64) bool{
+		">":  func(a, b float64) bool { return a > b },
+		">=": func(a, b float64) bool { return a >= b },
+		"<":  func(a, b float64) bool { return a < b },
+		"<=": func(a, b float64) bool { return a <= b },
+		"==": func(a, b float64) bool { return a == b },
+	}[op]
+	return func(sr *buf.SpinResult) bool {
+		w := float64(sr.TotalWin)
+		if mult {
+			w /= float64(sr.Bet)
+		}
+		return cmp(w, v)
+	}, nil
+}
+
+// printSpin prints a replayed spin act by act, with screens laid out as rows.
+func printSpin(out io.Writer, res *replayResult, gs *spec.GameSetting) {
+	p := message.NewPrinter(language.English)
+	sr := res.Result
+	p.Fprintf(out, "game   : %s (gid %d)\n", res.Game, res.GameID)
+	if res.Seed != 0 {
+		p.Fprintf(out, "spin   : seed %s, stream %d, spin %d\n", strconv.FormatInt(res.Seed, 10), res.Stream, res.Spin)
+	}
+	p.Fprintf(out, "state  : %s\n", res.State)
+	p.Fprintf(out, "bet    : %d (mode %d, mult %d)\n", sr.Bet, sr.BetMode, sr.BetMult)
+	p.Fprintf(out, "win    : %d (%.2fx)\n", sr.TotalWin, float64(sr.TotalWin)/float64(max(sr.Bet, 1)))
+
+	for i, gm := range sr.GameModes {
+		cols := 0
+		if gm.GameModeId >= 0 && gm.GameModeId < len(gs.GameModeSettings) {
+			cols = gs.GameModeSettings[gm.GameModeId].ScreenSetting.Columns
+		}
+		p.Fprintf(out, "\n=== game mode #%d (mode id %d)  win %d  trigger %d  end %t\n", i, gm.GameModeId, gm.TotalWin, gm.Trigger, gm.IsModeEnd)
+		for _, a := range gm.ActResults {
+			p.Fprintf(out, "act %-3d r%d s%d a%d  %-12s win %-8d step %-8d round %-8d total %d\n",
+				a.Id, a.RoundId, a.StepId, a.ActId, a.ActType, a.ActWin, a.StepAccWin, a.RoundAccWin, a.NowTotalWin)
+			if len(a.Screen) > 0 {
+				printScreen(out, a.Screen, cols)
+			}
+			for _, d := range a.Details {
+				p.Fprintf(out, "    symbol %d  count %d  line %d  comb %d  win %d  hits %v\n", d.SymbolID, d.Count, d.LineID, d.Combinations, d.Win, d.HitMap)
+			}
+			if a.ExtendResult != nil {
+				ext, err := json.Marshal(a.ExtendResult)
+				if err != nil {
+					ext = []byte(fmt.Sprintf("%+v", a.ExtendResult))
+				}
+				fmt.Fprintf(out, "    ext %s\n", ext)
+			}
+		}
+	}
+}
+
+// printScreen prints a row-major screen (index = row*cols + col).
+func printScreen(out io.Writer, screen []int16, cols int) {
+	if cols <= 0 || len(screen)%cols != 0 {
+		fmt.Fprintf(out, "    %v\n", screen)
+		return
+	}
+	for r := 0; r < len(screen)/cols; r++ {
+		fmt.Fprint(out, "   ")
+		for _, s := range screen[r*cols : (r+1)*cols] {
+			fmt.Fprintf(out, " %3d", s)
+		}
+		fmt.Fprintln(out)
+	}
+}
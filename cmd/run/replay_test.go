@@ -0,0 +1,85 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+	"github.com/zintix-labs/problab/sdk/buf"
+	"github.com/zintix-labs/problab/spec"
+)
+
+func TestParsePredicate(t *testing.T) {
+	sr := &buf.SpinResult{TotalWin: 4000, Bet: 40, GameModeCount: 2}
+	cases := map[string]bool{
+		"win>99x":         true,
+		"win>100x":        false,
+		"win>=100x":       true,
+		"win==4000":       true,
+		"win<4000":        false,
+		"trigger":         true,
+		"trigger,win>50x": true,
+		"trigger, nowin":  false,
+		"free,win<=4000":  true,
+	}
+	for expr, want := range cases {
+		pred, err := parsePredicate(expr)
+		if err != nil {
+			t.Fatalf("parsePredicate(%q) error: %v", expr, err)
+		}
+		if got := pred(sr); got != want {
+			t.Fatalf("%q on win 4000/bet 40/trigger = %v, want %v", expr, got, want)
+		}
+	}
+	for _, bad := range []string{"win", "win>abc", "scatter"} {
+		if _, err := parsePredicate(bad); err == nil {
+			t.Fatalf("parsePredicate(%q) should fail", bad)
+		}
+	}
+}
+
+func TestReplayFindsSimulatedSpin(t *testing.T) {
+	lab, err := engine.New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	pred, _ := parsePredicate("trigger")
+	rc := &replayConfig{id: spec.GID(1), seed: 42, stream: 1, find: "trigger", maxSpins: 100000}
+	state, spin, err := rc.locate(lab, pred)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the same stream spun plainly must hit its first trigger at the same index and win
+	m, err := lab.NewMachineWithSeed(rc.id, shardSeeds(rc.seed, 2)[1], true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range spin {
+		if m.SpinInternal(0).GameModeCount > 1 {
+			t.Fatalf("stream triggered at spin %d, before found spin %d", i, spin)
+		}
+	}
+	want := m.SpinInternal(0).TotalWin
+
+	sr, simWin, err := replaySpin(lab, rc.id, 0, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sr.TotalWin != want || simWin != want || sr.GameModeCount < 2 {
+		t.Fatalf("replayed win %d (sim %d, modes %d), want %d with a trigger", sr.TotalWin, simWin, sr.GameModeCount, want)
+	}
+}
@@ -0,0 +1,393 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zintix-labs/problab-scaffold/internal/session"
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+	"github.com/zintix-labs/problab/spec"
+	"github.com/zintix-labs/problab/stats"
+	"gopkg.in/yaml.v3"
+)
+
+// Report formats accepted by -out.
+const (
+	outText = "text"
+	outJSON = "json"
+	outCSV  = "csv"
+	outYAML = "yaml"
+)
+
+// simReport is the machine-readable form of one cmd/run execution.
+//
+// Field names are kept stable so math can diff runs in scripts and archive
+// reports next to each config revision.
+type simReport struct {
+	Game        string                  `json:"game"                  yaml:"game"`
+	GameID      spec.GID                `json:"game_id"               yaml:"game_id"`
+	Config      string                  `json:"config"                yaml:"config"`
+	ConfigHash  string                  `json:"config_sha256"         yaml:"config_sha256"`
+	Seed        int64                   `json:"seed"                  yaml:"seed"`
+	PRNG        string                  `json:"prng"                  yaml:"prng"`
+	BetMode     int                     `json:"bet_mode"              yaml:"bet_mode"`
+	BetUnit     int                     `json:"bet_unit"              yaml:"bet_unit"`
+	Workers     int                     `json:"workers"               yaml:"workers"`
+	Players     int                     `json:"players"               yaml:"players"`
+	Spins       int                     `json:"spins"                 yaml:"spins"`
+	ElapsedSec  float64                 `json:"elapsed_sec"           yaml:"elapsed_sec"`
+	TotalBet    int                     `json:"total_bet"             yaml:"total_bet"`
+	TotalWin    int                     `json:"total_win"             yaml:"total_win"`
+	RTP         float64                 `json:"rtp"                   yaml:"rtp"`
+	RtpCI       stats.CI                `json:"rtp_ci95"              yaml:"rtp_ci95"`
+	Modes       []modeRTP               `json:"modes"                 yaml:"modes"`
+	HitRate     float64                 `json:"hit_rate"              yaml:"hit_rate"`
+	Trigger     int                     `json:"trigger"               yaml:"trigger"`
+	TriggerRate float64                 `json:"trigger_rate"          yaml:"trigger_rate"`
+	Std         float64                 `json:"std"                   yaml:"std"`
+	Cv          float64                 `json:"cv"                    yaml:"cv"`
+	Dist        []bucketRow             `json:"win_distribution"      yaml:"win_distribution"`
+	Precision   *precisionInfo          `json:"precision,omitempty"   yaml:"precision,omitempty"`
+	Wins        *winStats               `json:"win_stats,omitempty"   yaml:"win_stats,omitempty"`
+	Player      *stats.EstimatorPlayers `json:"player_exp,omitempty"  yaml:"player_exp,omitempty"`
+	Sessions    *session.Report         `json:"sessions,omitempty"    yaml:"sessions,omitempty"`
+	Limits      *limitsReport           `json:"limits,omitempty"      yaml:"limits,omitempty"`
+	Partial     *partialInfo            `json:"partial,omitempty"     yaml:"partial,omitempty"`
+}
+
+// modeRTP is the RTP contribution of one game mode (base game / free game).
+type modeRTP struct {
+	Mode string  `json:"mode" yaml:"mode"`
+	Win  int     `json:"win"  yaml:"win"`
+	RTP  float64 `json:"rtp"  yaml:"rtp"`
+}
+
+// bucketRow is one win-multiple bucket of the distribution report.
+type bucketRow struct {
+	Bucket    string  `json:"bucket"     yaml:"bucket"`
+	Total     int     `json:"total"      yaml:"total"`
+	TotalProb float64 `json:"total_prob" yaml:"total_prob"`
+	Base      int     `json:"base"       yaml:"base"`
+	BaseProb  float64 `json:"base_prob"  yaml:"base_prob"`
+	Free      int     `json:"free"       yaml:"free"`
+	FreeProb  float64 `json:"free_prob"  yaml:"free_prob"`
+}
+
+// newSimReport flattens a finished StatReport into a simReport.
+func newSimReport(configName string, st *stats.StatReport, est *stats.EstimatorPlayers, used time.Duration, workers int) *simReport {
+	st.Done()
+	sum := st.Summary
+	r := &simReport{
+		Game:        sum.GameName,
+		GameID:      sum.GameId,
+		Config:      configName,
+		Seed:        cfg.seed,
+		PRNG:        engine.PRNG(),
+		BetMode:     sum.BetMode,
+		BetUnit:     sum.BetUnit,
+		Workers:     workers,
+		Players:     cfg.player,
+		Spins:       sum.Rounds,
+		ElapsedSec:  used.Seconds(),
+		TotalBet:    sum.TotalBet,
+		TotalWin:    sum.TotalWin,
+		RTP:         sum.RTP,
+		RtpCI:       sum.RtpCI,
+		HitRate:     sum.HitRate,
+		Trigger:     sum.Trigger,
+		TriggerRate: sum.TriggerRate,
+		Std:         sum.Std,
+		Cv:          sum.Cv,
+		Player:      est,
+	}
+	r.Modes = []modeRTP{
+		{Mode: "base", Win: sum.BaseWin, RTP: ratio(sum.BaseWin, sum.TotalBet)},
+		{Mode: "free", Win: sum.FreeWin, RTP: ratio(sum.FreeWin, sum.TotalBet)},
+	}
+	d := st.Dist
+	r.Dist = make([]bucketRow, len(d.WinBucket))
+	for i, b := range d.WinBucket {
+		r.Dist[i] = bucketRow{
+			Bucket:    b,
+			Total:     d.TotalWinCollect[i],
+			TotalProb: d.TotalWinDist[i],
+			Base:      d.BaseWinCollect[i],
+			BaseProb:  d.BaseWinDist[i],
+			Free:      d.FreeWinCollect[i],
+			FreeProb:  d.FreeWinDist[i],
+		}
+	}
+	return r
+}
+
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
+
+// outFormat resolves the effective report format from -out and -o.
+//
+// When -out is omitted but -o names a file, the format is taken from the file extension.
+func (cfg *config) outFormat() (string, error) {
+	f := strings.ToLower(strings.TrimSpace(cfg.out))
+	if f == "" && cfg.outFile != "" {
+		switch strings.ToLower(filepath.Ext(cfg.outFile)) {
+		case ".json":
+			f = outJSON
+		case ".csv":
+			f = outCSV
+		case ".yaml", ".yml":
+			f = outYAML
+		default:
+			return "", fmt.Errorf("cannot infer report format from %q, use -out json|csv|yaml", cfg.outFile)
+		}
+	}
+	switch f {
+	case "", outText:
+		return outText, nil
+	case outJSON, outCSV, outYAML:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown report format %q, use -out json|csv|yaml", cfg.out)
+	}
+}
+
+// msgOut is where banners go. Machine-readable reports written to stdout must
+// not be mixed with human text, so banners move to stderr in that case.
+func (cfg *config) msgOut() io.Writer {
+	if f, _ := cfg.outFormat(); f != outText && cfg.outFile == "" {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// writeReport writes r (a *simReport, *batchReport or *compareReport) in the requested format to -o (or stdout).
+func writeReport(r any, format string, path string) error {
+	if path == "" {
+		return encodeReport(os.Stdout, r, format)
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := encodeReport(f, r, format); err != nil {
+		f.Close()
+		return err
+	}
+	// a report is only written once the file closes without error
+	return f.Close()
+}
+
+// encodeReport writes r in the requested format to w.
+func encodeReport(w io.Writer, r any, format string) error {
+	switch format {
+	case outJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case outYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+		return enc.Close()
+	case outCSV:
+		switch r := r.(type) {
+		case *simReport:
+			return writeReportCSV(w, r)
+		case *batchReport:
+			return writeBatchCSV(w, r)
+		case *compareReport:
+			return writeCompareCSV(w, r)
+		case *analyzeReport:
+			return writeAnalyzeCSV(w, r)
+		case *reelsReport:
+			return writeReelsCSV(w, r)
+		case *rngReport:
+			return writeRngCSV(w, r)
+		case *picksReport:
+			return writePicksCSV(w, r)
+		}
+		return fmt.Errorf("unsupported csv report: %T", r)
+	default:
+		return fmt.Errorf("unsupported report format: %s", format)
+	}
+}
+
+// writeReportCSV writes the report in long format (section,key,value) so that
+// rows stay stable across games with different bucket counts.
+//
+// The player experience estimator is only available in json/yaml; sessions are
+// flattened into the "session" section.
+func writeReportCSV(w io.Writer, r *simReport) error {
+	cw := csv.NewWriter(w)
+	f := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	i := strconv.Itoa
+	rows := [][]string{
+		{"section", "key", "value"},
+		{"run", "game", r.Game},
+		{"run", "game_id", fmt.Sprint(uint(r.GameID))},
+		{"run", "config", r.Config},
+		{"run", "config_sha256", r.ConfigHash},
+		{"run", "seed", strconv.FormatInt(r.Seed, 10)},
+		{"run", "prng", r.PRNG},
+		{"run", "bet_mode", i(r.BetMode)},
+		{"run", "bet_unit", i(r.BetUnit)},
+		{"run", "workers", i(r.Workers)},
+		{"run", "players", i(r.Players)},
+		{"run", "spins", i(r.Spins)},
+		{"run", "elapsed_sec", f(r.ElapsedSec)},
+		{"summary", "total_bet", i(r.TotalBet)},
+		{"summary", "total_win", i(r.TotalWin)},
+		{"summary", "rtp", f(r.RTP)},
+		{"summary", "rtp_ci95_lo", f(r.RtpCI.Lo)},
+		{"summary", "rtp_ci95_hi", f(r.RtpCI.Hi)},
+		{"summary", "hit_rate", f(r.HitRate)},
+		{"summary", "trigger", i(r.Trigger)},
+		{"summary", "trigger_rate", f(r.TriggerRate)},
+		{"summary", "std", f(r.Std)},
+		{"summary", "cv", f(r.Cv)},
+	}
+	if pt := r.Partial; pt != nil {
+		rows = append(rows,
+			[]string{"partial", "done", i(pt.Done)},
+			[]string{"partial", "planned", i(pt.Planned)},
+			[]string{"partial", "unit", pt.Unit},
+		)
+	}
+	if pi := r.Precision; pi != nil {
+		rows = append(rows,
+			[]string{"precision", "confidence", f(pi.Confidence)},
+			[]string{"precision", "rtp_ci_lo", f(pi.CI.Lo)},
+			[]string{"precision", "rtp_ci_hi", f(pi.CI.Hi)},
+			[]string{"precision", "ci_width", f(pi.Width)},
+			[]string{"precision", "target_width", f(pi.Target)},
+			[]string{"precision", "target_spins", i(pi.TargetSpins)},
+			[]string{"precision", "converged", strconv.FormatBool(pi.Converged)},
+			[]string{"precision", "std_per_spin", f(pi.StdPerSpin)},
+			[]string{"precision", "volatility_index", f(pi.VolatilityIndex)},
+		)
+	}
+	for _, m := range r.Modes {
+		rows = append(rows,
+			[]string{"mode", m.Mode + ".win", i(m.Win)},
+			[]string{"mode", m.Mode + ".rtp", f(m.RTP)},
+		)
+	}
+	for _, b := range r.Dist {
+		rows = append(rows,
+			[]string{"dist", b.Bucket + ".total", i(b.Total)},
+			[]string{"dist", b.Bucket + ".total_prob", f(b.TotalProb)},
+			[]string{"dist", b.Bucket + ".base", i(b.Base)},
+			[]string{"dist", b.Bucket + ".base_prob", f(b.BaseProb)},
+			[]string{"dist", b.Bucket + ".free", i(b.Free)},
+			[]string{"dist", b.Bucket + ".free_prob", f(b.FreeProb)},
+		)
+	}
+	if ws := r.Wins; ws != nil {
+		rows = append(rows,
+			[]string{"win_stats", "max_win", i(ws.MaxWin)},
+			[]string{"win_stats", "max_win_x", f(ws.MaxWinX)},
+			[]string{"win_stats", "cap_x", f(ws.CapX)},
+			[]string{"win_stats", "cap_hits", i(ws.CapHits)},
+			[]string{"win_stats", "cap_one_in", f(ws.CapOneIn)},
+		)
+		for _, b := range ws.Bands {
+			rows = append(rows,
+				[]string{"band", b.Label + ".spins", i(b.Spins)},
+				[]string{"band", b.Label + ".prob", f(b.Prob)},
+				[]string{"band", b.Label + ".one_in", f(b.OneIn)},
+				[]string{"band", b.Label + ".rtp_contrib", f(b.RTP)},
+			)
+		}
+		for _, pc := range ws.Percentiles {
+			rows = append(rows,
+				[]string{"percentile", pc.Label + ".win", i(pc.Win)},
+				[]string{"percentile", pc.Label + ".win_x", f(pc.WinX)},
+				[]string{"percentile", pc.Label + ".one_in", f(pc.OneIn)},
+			)
+		}
+		for _, m := range ws.GameModes {
+			rows = append(rows,
+				[]string{"game_mode", i(m.ID) + ".spins", i(m.Spins)},
+				[]string{"game_mode", i(m.ID) + ".one_in", f(m.OneIn)},
+				[]string{"game_mode", i(m.ID) + ".win", i(m.Win)},
+				[]string{"game_mode", i(m.ID) + ".rtp", f(m.RTP)},
+				[]string{"game_mode", i(m.ID) + ".share", f(m.Share)},
+			)
+		}
+	}
+	if l := r.Limits; l != nil {
+		rows = append(rows,
+			[]string{"limits", "name", l.Name},
+			[]string{"limits", "source", l.Source},
+			[]string{"limits", "max_players", i(l.MaxPlayers)},
+			[]string{"limits", "max_player_spins", i(l.MaxPlayerSpins)},
+			[]string{"limits", "on_exceed", l.OnExceed},
+		)
+		for k, v := range l.Violations {
+			rows = append(rows, []string{"limits", "violation." + i(k), v})
+		}
+	}
+	if sr := r.Sessions; sr != nil {
+		rows = append(rows,
+			[]string{"session", "strategy", sr.Strategy},
+			[]string{"session", "sessions", i(sr.Sessions)},
+			[]string{"session", "buy_in", i(sr.BuyIn)},
+			[]string{"session", "winning_sessions", f(sr.Winning)},
+			[]string{"session", "rtp", f(sr.RTP)},
+			[]string{"session", "avg_stake_x", f(sr.AvgStakeX)},
+			[]string{"session", "mean_length", f(sr.MeanLength)},
+			[]string{"session", "mean_minutes", f(sr.MeanMinutes)},
+		)
+		for _, e := range sr.Ends {
+			rows = append(rows, []string{"session", "end." + e.End, i(e.Sessions)})
+		}
+		for k, q := range sr.Length {
+			p := "p" + f(100*q.P)
+			rows = append(rows,
+				[]string{"session", "length." + p, f(q.Value)},
+				[]string{"session", "net_buyins." + p, f(sr.Net[k].Value)},
+			)
+		}
+		for _, b := range sr.LengthDist {
+			rows = append(rows, []string{"session", "length_dist." + b.Label(), i(b.Sessions)})
+		}
+		for _, s := range sr.Survival {
+			rows = append(rows, []string{"session", "survival." + i(s.Spins), f(s.Alive)})
+		}
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
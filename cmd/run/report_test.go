@@ -0,0 +1,134 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+	"gopkg.in/yaml.v3"
+)
+
+func TestWriteReportRoundTrip(t *testing.T) {
+	lab, err := engine.New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	r, err := newRunner(lab, 0, 0, 7, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, used, err := r.run(context.Background(), 500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ent, _ := lab.EntryById(0)
+	rep := newSimReport(ent.ConfigName, st, nil, used, 2)
+	rep.ConfigHash, _ = engine.ConfigSHA256(ent.ConfigName)
+	rep.Precision = newPrecisionInfo(st, 0.95)
+
+	dir := t.TempDir()
+	for _, tt := range []struct {
+		file   string
+		format string
+	}{
+		{"report.json", outJSON},
+		{"sub/report.yml", outYAML},
+		{"REPORT.CSV", outCSV},
+	} {
+		path := filepath.Join(dir, tt.file)
+		format, err := (&config{outFile: path}).outFormat()
+		if err != nil || format != tt.format {
+			t.Fatalf("-o %s: format %q, %v; want %q", tt.file, format, err, tt.format)
+		}
+		if err := writeReport(rep, format, path); err != nil {
+			t.Fatal(err)
+		}
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		switch format {
+		case outJSON, outYAML:
+			got := new(simReport)
+			if format == outJSON {
+				err = json.Unmarshal(raw, got)
+			} else {
+				err = yaml.Unmarshal(raw, got)
+			}
+			if err != nil {
+				t.Fatalf("%s: %v", tt.file, err)
+			}
+			if !reflect.DeepEqual(got, rep) {
+				t.Fatalf("%s round trip:\ngot  %+v\nwant %+v", tt.file, got, rep)
+			}
+		case outCSV:
+			rows, err := csv.NewReader(bytes.NewReader(raw)).ReadAll() // every row has the same columns
+			if err != nil {
+				t.Fatalf("%s: %v", tt.file, err)
+			}
+			if !reflect.DeepEqual(rows[0], []string{"section", "key", "value"}) {
+				t.Fatalf("csv header %v", rows[0])
+			}
+			cells := map[string]string{}
+			for _, row := range rows[1:] {
+				cells[row[0]+"/"+row[1]] = row[2]
+			}
+			f := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+			bucket := "dist/" + rep.Dist[0].Bucket + ".total"
+			for key, want := range map[string]string{
+				"run/game":            rep.Game,
+				"run/config_sha256":   rep.ConfigHash,
+				"run/seed":            strconv.FormatInt(rep.Seed, 10),
+				"run/workers":         "2",
+				"run/spins":           "1000",
+				"summary/total_win":   strconv.Itoa(rep.TotalWin),
+				"summary/rtp":         f(rep.RTP),
+				"summary/rtp_ci95_hi": f(rep.RtpCI.Hi),
+				"precision/ci_width":  f(rep.Precision.Width),
+				"mode/free.win":       strconv.Itoa(rep.Modes[1].Win),
+				bucket:                strconv.Itoa(rep.Dist[0].Total),
+			} {
+				if cells[key] != want {
+					t.Errorf("csv %s = %q, want %q", key, cells[key], want)
+				}
+			}
+			if n := len(rows) - 1; n != len(cells) {
+				t.Fatalf("csv has %d rows for %d keys", n, len(cells))
+			}
+		}
+	}
+
+	// -out wins over the extension; an unknown extension is refused
+	if format, err := (&config{out: "csv", outFile: "x.json"}).outFormat(); err != nil || format != outCSV {
+		t.Fatalf("-out csv -o x.json: %q, %v", format, err)
+	}
+	if _, err := (&config{outFile: "x.txt"}).outFormat(); err == nil {
+		t.Fatal("-o x.txt should not infer a format")
+	}
+	// a report that cannot be created is an error
+	if err := writeReport(rep, outJSON, filepath.Join(dir, "report.json", "x.json")); err == nil {
+		t.Fatal("writing under a file succeeded")
+	}
+}
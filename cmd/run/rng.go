@@ -0,0 +1,198 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/zintix-labs/problab-scaffold/internal/prng"
+	"github.com/zintix-labs/problab-scaffold/internal/rngtest"
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+	"github.com/zintix-labs/problab/sdk/core"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// rngConfig holds the flags of the `rng` subcommand.
+type rngConfig struct {
+	prng     string
+	seed     int64
+	draws    int
+	alpha    float64
+	out      string
+	outFile  string
+	dump     string
+	dumpSize int64
+}
+
+// rngReport is what `rng` writes.
+type rngReport struct {
+	PRNG       string           `json:"prng"        yaml:"prng"`
+	Seed       int64            `json:"seed"        yaml:"seed"`
+	Draws      int              `json:"draws"       yaml:"draws"` // per test
+	Alpha      float64          `json:"alpha"       yaml:"alpha"`
+	ElapsedSec float64          `json:"elapsed_sec" yaml:"elapsed_sec"`
+	Tests      []rngtest.Result `json:"tests"       yaml:"tests"`
+	Failed     int              `json:"failed"      yaml:"failed"`
+	Pass       bool             `json:"pass"        yaml:"pass"`
+}
+
+// runRng runs the statistical test battery of internal/rngtest on the PRNG this binary
+// is built with (or another built-in one with -prng) and reports the p-value of every
+// test; it exits with status 1 when a test fails. With -dump it writes the raw stream
+// for external suites instead.
+func runRng(args []string) {
+	rc := new(rngConfig)
+	fs := flag.NewFlagSet("rng", flag.ExitOnError)
+	fs.StringVar(&rc.prng, "prng", "", "built-in PRNG to test: "+prngNames()+" (default the one this binary is built with)")
+	fs.Int64Var(&rc.seed, "seed", -1, "int64 seed of the tested streams (< 1: random, reported)")
+	fs.IntVar(&rc.draws, "n", 10_000_000, "draws per test")
+	fs.Float64Var(&rc.alpha, "alpha", 0.001, "a test fails when its p-value is below alpha or above 1-alpha")
+	fs.StringVar(&rc.out, "out", "", "report format: text|json|csv|yaml (default text, or inferred from -o)")
+	fs.StringVar(&rc.outFile, "o", "", "write the report to this file instead of stdout")
+	fs.StringVar(&rc.dump, "dump", "", `write the raw Uint64 stream (little-endian) to this file, "-" for stdout, and exit`)
+	fs.Int64Var(&rc.dumpSize, "dump-size", 0, "bytes to -dump (0: until the reader stops, stdout only)")
+	fs.Parse(args)
+
+	f, name := engine.PRNGFactory(), engine.PRNG()
+	if rc.prng != "" {
+		p, ok := prng.Lookup(rc.prng)
+		if !ok {
+			log.Fatalf("value err : unknown -prng %q, want %s", rc.prng, prngNames())
+		}
+		f, name = p, p.Name()
+	}
+	if rc.seed < 1 {
+		seed, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt64))
+		if err != nil {
+			log.Fatal(err)
+		}
+		rc.seed = seed.Int64()
+	}
+	if rc.dump != "" {
+		if err := dumpRng(f.New(rc.seed), rc.dump, rc.dumpSize); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	oc := &config{out: rc.out, outFile: rc.outFile}
+	format, err := oc.outFormat()
+	if err != nil {
+		log.Fatal("value err : " + err.Error())
+	}
+	start := time.Now()
+	res, err := rngtest.Run(f, rc.seed, rngtest.Options{N: rc.draws, Alpha: rc.alpha})
+	if err != nil {
+		log.Fatal("value err : " + err.Error())
+	}
+	rep := &rngReport{PRNG: name, Seed: rc.seed, Draws: rc.draws, Alpha: rc.alpha, ElapsedSec: time.Since(start).Seconds(), Tests: res}
+	for _, r := range res {
+		if !r.Pass {
+			rep.Failed++
+		}
+	}
+	rep.Pass = rep.Failed == 0
+
+	if format == outText && rc.outFile == "" {
+		stdOutRng(os.Stdout, rep)
+	} else if err := writeReport(rep, format, rc.outFile); err != nil {
+		log.Fatal(err)
+	}
+	if !rep.Pass {
+		os.Exit(1)
+	}
+}
+
+// dumpRng writes size bytes of p to path ("-": stdout).
+func dumpRng(p core.PRNG, path string, size int64) error {
+	if path != "-" && size <= 0 {
+		return fmt.Errorf("value err : -dump %s needs -dump-size", path)
+	}
+	var w io.Writer = os.Stdout
+	if path != "-" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	bw := bufio.NewWriterSize(w, 1<<20)
+	if _, err := rngtest.Dump(bw, p, size); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// stdOutRng prints the battery in text mode, one test per row.
+func stdOutRng(out io.Writer, rep *rngReport) {
+	p := message.NewPrinter(language.English)
+	p.Fprintf(out, "prng        : %s (seed %d)\n", rep.PRNG, rep.Seed)
+	p.Fprintf(out, "draws       : %d per test, alpha %g, %.1fs\n\n", rep.Draws, rep.Alpha, rep.ElapsedSec)
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TEST\tPARAMS\tSTATISTIC\tDF\tP-VALUE\tRESULT")
+	for _, r := range rep.Tests {
+		result := "pass"
+		if !r.Pass {
+			result = "FAIL"
+		}
+		df := "z"
+		if r.DF > 0 {
+			df = strconv.Itoa(r.DF)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%.4f\t%s\t%.4f\t%s\n", r.Test, r.Params, r.Stat, df, r.P, result)
+	}
+	tw.Flush()
+	if rep.Pass {
+		p.Fprintf(out, "\nPASS: %d/%d tests\n", len(rep.Tests), len(rep.Tests))
+	} else {
+		p.Fprintf(out, "\nFAIL: %d of %d tests\n", rep.Failed, len(rep.Tests))
+	}
+}
+
+// writeRngCSV writes one row per test.
+func writeRngCSV(w io.Writer, r *rngReport) error {
+	cw := csv.NewWriter(w)
+	f := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	cw.Write([]string{"prng", "seed", "test", "params", "samples", "statistic", "df", "p_value", "pass"})
+	for _, t := range r.Tests {
+		cw.Write([]string{r.PRNG, strconv.FormatInt(r.Seed, 10), t.Test, t.Params, strconv.Itoa(t.Samples), f(t.Stat), strconv.Itoa(t.DF), f(t.P), strconv.FormatBool(t.Pass)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// prngNames lists the built-in PRNGs for flag help and errors.
+func prngNames() string {
+	var names []string
+	for _, f := range prng.All() {
+		names = append(names, f.Name())
+	}
+	return strings.Join(names, "|")
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zintix-labs/problab-scaffold/internal/prng"
+	"github.com/zintix-labs/problab-scaffold/internal/rngtest"
+)
+
+func TestRngReport(t *testing.T) {
+	rep := &rngReport{PRNG: "pcg64", Seed: 9, Draws: 100_000, Alpha: 0.001, Failed: 1, Tests: []rngtest.Result{
+		{Test: "range", Params: "LUT.Pick over 2 stops", Samples: 100_000, Stat: 0.5, DF: 1, P: 0.48, Pass: true},
+		{Test: "serial", Params: "lag-1 correlation of Float64", Samples: 100_000, Stat: 4.2, P: 0.00003},
+	}}
+	var out bytes.Buffer
+	stdOutRng(&out, rep)
+	s := out.String()
+	if !strings.Contains(s, "pcg64 (seed 9)") || !strings.Contains(s, "4.2000     z   0.0000   FAIL") || !strings.HasSuffix(s, "FAIL: 1 of 2 tests\n") {
+		t.Fatalf("rng table:\n%s", s)
+	}
+
+	out.Reset()
+	if err := writeRngCSV(&out, rep); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil || len(rows) != 3 || rows[2][2] != "serial" || rows[2][8] != "false" {
+		t.Fatalf("rng csv %v, %v", rows, err)
+	}
+}
+
+func TestDumpRng(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stream.bin")
+	if err := dumpRng(prng.ChaCha20.New(1), path, 0); err == nil {
+		t.Fatal("-dump to a file without -dump-size accepted")
+	}
+	if err := dumpRng(prng.ChaCha20.New(1), path, 1000); err != nil {
+		t.Fatal(err)
+	}
+	if st, err := os.Stat(path); err != nil || st.Size() != 1000 {
+		t.Fatalf("dump file: %v, %v", st, err)
+	}
+}
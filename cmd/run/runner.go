@@ -0,0 +1,291 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cheggaaa/pb/v3"
+	"github.com/zintix-labs/problab"
+	"github.com/zintix-labs/problab-scaffold/internal/simstat"
+	"github.com/zintix-labs/problab/recorder"
+	"github.com/zintix-labs/problab/sdk/buf"
+	"github.com/zintix-labs/problab/spec"
+	"github.com/zintix-labs/problab/stats"
+)
+
+// runner drives machines directly instead of going through problab.Simulator,
+// so cmd/run can observe every SpinResult (max win, and anything else a mode needs).
+//
+// Worker seeds are derived exactly like upstream Sim/SimMP: worker 0 uses the base
+// seed and worker i>0 takes the i-th value of the simulator seed sequence. A runner
+// with the same seed and worker count therefore reproduces `Sim`/`SimMP` spin for spin.
+type runner struct {
+	gid     spec.GID
+	betMode int
+	seed    int64
+	workers []*runWorker
+	elapsed time.Duration // wall time of all run calls, including before a resume
+	showpb  bool          // draw a progress bar while running
+	stop    atomic.Bool
+}
+
+// runWorker is one machine with its own recorder and spin counter.
+type runWorker struct {
+	m         *problab.Machine
+	rec       *recorder.SpinRecorder
+	seed      int64
+	done      int                // spins recorded so far
+	maxWin    int                // largest TotalWin observed
+	wins      *simstat.WinCounts // exact per-spin win table
+	modeWin   []int              // win per game mode id
+	modeSpins []int              // spins that entered each game mode id
+	live      liveCounts         // totals published for progress reporting
+}
+
+func newRunner(lab *problab.Problab, gid spec.GID, betMode int, seed int64, workers int) (*runner, error) {
+	return newRunnerSeeds(lab, gid, betMode, shardSeeds(seed, workers))
+}
+
+// newRunnerSeeds builds a runner with one worker per explicit seed.
+func newRunnerSeeds(lab *problab.Problab, gid spec.GID, betMode int, seeds []int64) (*runner, error) {
+	ent, ok := lab.EntryById(gid)
+	if !ok {
+		return nil, fmt.Errorf("game id not found: %d", gid)
+	}
+	if len(seeds) == 0 {
+		return nil, fmt.Errorf("runner needs at least one worker")
+	}
+	r := &runner{gid: gid, betMode: betMode, seed: seeds[0], workers: make([]*runWorker, len(seeds))}
+	for i, ws := range seeds {
+		m, err := lab.NewMachineWithSeed(gid, ws, true)
+		if err != nil {
+			return nil, err
+		}
+		if betMode < 0 || betMode >= len(m.BetUnits) {
+			return nil, fmt.Errorf("bet mode err: must >= 0 and < len(betunits)")
+		}
+		rec, err := recorder.NewSpinRecorder(ent.Name, gid, m.BetUnits, 0, betMode)
+		if err != nil {
+			return nil, err
+		}
+		r.workers[i] = &runWorker{m: m, rec: rec, seed: ws, wins: simstat.NewWinCounts()}
+	}
+	return r, nil
+}
+
+// shardSeeds returns the seeds of n parallel streams derived from seed: the seed itself,
+// then the simulator seed sequence. Stream i here is worker i of `SimMP` with the same seed.
+func shardSeeds(seed int64, n int) []int64 {
+	seeds := make([]int64, n)
+	sm := newSeedMaker(seed)
+	for i := range seeds {
+		if i == 0 {
+			seeds[i] = seed
+			continue
+		}
+		seeds[i] = sm.next()
+	}
+	return seeds
+}
+
+// stopCheck is how many spins a worker runs between looks at the stop flag.
+const stopCheck = 1024
+
+// run spins every worker until it has recorded `spins` spins in total and returns the
+// merged report plus the wall time spent in this call.
+//
+// When ctx is cancelled the workers stop at the next spin boundary and run returns the
+// partial report; callers tell the two cases apart with finished.
+func (r *runner) run(ctx context.Context, spins int) (*stats.StatReport, time.Duration, error) {
+	start := time.Now()
+	done, watched := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(watched)
+		select {
+		case <-ctx.Done():
+			r.stop.Store(true)
+		case <-done:
+		}
+	}()
+	bar := pb.New(max(spins*len(r.workers)-r.spinsDone(), 0))
+	bar.Set(pb.CleanOnFinish, true)
+	if !r.showpb {
+		bar.SetWriter(io.Discard)
+	}
+	bar.Start()
+	wg := new(sync.WaitGroup)
+	wg.Add(len(r.workers))
+	for _, w := range r.workers {
+		go func(w *runWorker) {
+			defer wg.Done()
+			from := w.done
+			for ; w.done < spins; w.done++ {
+				if w.done%stopCheck == 0 {
+					if r.stop.Load() {
+						break
+					}
+					bar.Add(w.done - from)
+					from = w.done
+					w.publish()
+				}
+				w.record(w.m.SpinInternal(r.betMode))
+			}
+			bar.Add(w.done - from)
+			w.publish()
+		}(w)
+	}
+	wg.Wait()
+	bar.Finish()
+	close(done)
+	<-watched
+	r.stop.Store(false)
+	used := time.Since(start)
+	r.elapsed += used
+	st, err := r.report()
+	return st, used, err
+}
+
+// record adds one spin to every statistic the worker keeps.
+func (w *runWorker) record(sr *buf.SpinResult) {
+	w.rec.Record(sr)
+	w.wins.Add(sr.TotalWin)
+	if sr.TotalWin > w.maxWin {
+		w.maxWin = sr.TotalWin
+	}
+	for _, gm := range sr.GameModeList {
+		for len(w.modeWin) <= gm.GameModeId {
+			w.modeWin = append(w.modeWin, 0)
+			w.modeSpins = append(w.modeSpins, 0)
+		}
+		w.modeWin[gm.GameModeId] += gm.TotalWin
+		w.modeSpins[gm.GameModeId]++
+	}
+}
+
+// publish copies the worker totals to w.live. Only the worker goroutine may call it
+// while run is active.
+func (w *runWorker) publish() {
+	c := recCounts(w.rec)
+	c.modeWin = w.modeWin
+	w.live.set(c)
+}
+
+// counts sums the totals last published by the workers; it is safe during run.
+func (r *runner) counts() progressCounts {
+	var c progressCounts
+	for _, w := range r.workers {
+		c.add(w.live.get())
+	}
+	return c
+}
+
+// finished reports whether every worker has recorded `spins` spins.
+func (r *runner) finished(spins int) bool {
+	for _, w := range r.workers {
+		if w.done < spins {
+			return false
+		}
+	}
+	return true
+}
+
+// spinsDone is the number of spins recorded by all workers.
+func (r *runner) spinsDone() int {
+	n := 0
+	for _, w := range r.workers {
+		n += w.done
+	}
+	return n
+}
+
+// report merges the worker recorders into one finished StatReport.
+func (r *runner) report() (*stats.StatReport, error) {
+	recs := make([]*recorder.SpinRecorder, len(r.workers))
+	for i, w := range r.workers {
+		recs[i] = w.rec
+	}
+	merged, err := recorder.MergeSpinRecorder(recs)
+	if err != nil {
+		return nil, err
+	}
+	st := merged.Done()
+	st.Done()
+	return st, nil
+}
+
+// wins merges the per-spin win tables of all workers.
+func (r *runner) wins() *simstat.WinCounts {
+	c := simstat.NewWinCounts()
+	for _, w := range r.workers {
+		c.Merge(w.wins)
+	}
+	return c
+}
+
+// modes returns the win and the number of spins entering each game mode id, over all workers.
+func (r *runner) modes() (win []int, spins []int) {
+	for _, w := range r.workers {
+		for id := range w.modeWin {
+			for len(win) <= id {
+				win, spins = append(win, 0), append(spins, 0)
+			}
+			win[id] += w.modeWin[id]
+			spins[id] += w.modeSpins[id]
+		}
+	}
+	return win, spins
+}
+
+// maxWin returns the largest single-spin TotalWin seen by any worker.
+func (r *runner) maxWin() int {
+	mw := 0
+	for _, w := range r.workers {
+		mw = max(mw, w.maxWin)
+	}
+	return mw
+}
+
+const mask63 = uint64(1<<63) - 1
+
+// seedMaker mirrors the upstream simulator seed sequence (full-period LCG mod 2^63
+// followed by an invertible mix), so scaffold runners derive the same worker seeds.
+type seedMaker struct {
+	state uint64
+}
+
+func newSeedMaker(seed int64) *seedMaker {
+	return &seedMaker{state: uint64(seed) & mask63}
+}
+
+func (s *seedMaker) next() int64 {
+	s.state = (s.state*6364136223846793005 + 1442695040888963407) & mask63
+	return int64(mix63(s.state))
+}
+
+func mix63(x uint64) uint64 {
+	x &= mask63
+	x ^= x >> 30
+	x = (x * 0xBF58476D1CE4E5B9) & mask63
+	x ^= x >> 27
+	x = (x * 0x94D049BB133111EB) & mask63
+	x ^= x >> 31
+	return x & mask63
+}
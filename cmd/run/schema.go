@@ -0,0 +1,51 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/zintix-labs/problab-scaffold/internal/lint"
+)
+
+// runSchema writes the JSON Schema of game configs, with the `fixed:` block of every
+// registered logic, to stdout or to -o. Point an editor at it for completion and
+// inline checks, e.g. `# yaml-language-server: $schema=<file>` on top of a config.
+func runSchema(args []string) {
+	var outFile string
+	fs := flag.NewFlagSet("schema", flag.ExitOnError)
+	fs.StringVar(&outFile, "o", "", "write the schema to this file instead of stdout")
+	fs.Parse(args)
+
+	b, err := json.MarshalIndent(lint.JSONSchema(), "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	b = append(b, '\n')
+	if outFile == "" {
+		os.Stdout.Write(b)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(outFile), 0o755); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(outFile, b, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
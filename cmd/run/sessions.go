@@ -0,0 +1,199 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cheggaaa/pb/v3"
+	"github.com/zintix-labs/problab"
+	"github.com/zintix-labs/problab-scaffold/internal/session"
+	"github.com/zintix-labs/problab/recorder"
+	"github.com/zintix-labs/problab/stats"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// runSessions plays -player sessions of strategy s, -bets bets each, on -worker machines.
+// It returns the machine report of all base-mode spins, the upstream player estimator
+// and the session report.
+//
+// Player i is played by worker i mod -worker on the stream seeded like worker i of a
+// machine run, so a session run is reproducible from its seed regardless of scheduling.
+// Cancelling ctx stops every worker after its current session; only finished sessions
+// are reported.
+func runSessions(ctx context.Context, lab *problab.Problab, s *session.Strategy, showpb bool) (*stats.StatReport, *stats.EstimatorPlayers, *session.Report, time.Duration, error) {
+	ent, _ := lab.EntryById(cfg.id)
+	seeds := shardSeeds(cfg.seed, cfg.worker)
+	machines := make([]*problab.Machine, len(seeds))
+	for i, seed := range seeds {
+		m, err := lab.NewMachineWithSeed(cfg.id, seed, true)
+		if err != nil {
+			return nil, nil, nil, 0, err
+		}
+		machines[i] = m
+	}
+	units := machines[0].BetUnits
+	if cfg.betMode < 0 || cfg.betMode >= len(units) {
+		return nil, nil, nil, 0, fmt.Errorf("bet mode err: must >= 0 and < len(betunits)")
+	}
+	if err := s.Check(units); err != nil {
+		return nil, nil, nil, 0, err
+	}
+	buyIn := cfg.bets * units[cfg.betMode]
+
+	recs := make([]*recorder.SpinRecorder, cfg.player)
+	for i := range recs {
+		rec, err := recorder.NewSpinRecorder(ent.Name, cfg.id, units, cfg.bets, cfg.betMode)
+		if err != nil {
+			return nil, nil, nil, 0, err
+		}
+		recs[i] = rec
+	}
+	cols := make([]*session.Collector, len(machines))
+	played := make([]bool, cfg.player)
+	lives := make([]liveCounts, len(machines))
+	var sessions atomic.Int64
+	live.plan("sessions", cfg.player, func() int { return int(sessions.Load()) })
+	live.track(ent.Name, cfg.id, cfg.betMode, 0, func() progressCounts {
+		var c progressCounts
+		for i := range lives {
+			c.add(lives[i].get())
+		}
+		return c
+	})
+
+	bar := pb.StartNew(cfg.player)
+	bar.Set(pb.CleanOnFinish, true)
+	if !showpb {
+		bar.SetWriter(io.Discard)
+	}
+	wg := new(sync.WaitGroup)
+	wg.Add(len(machines))
+	for wi, m := range machines {
+		go func() {
+			defer wg.Done()
+			col := new(session.Collector)
+			for i := wi; i < cfg.player && ctx.Err() == nil; i += len(machines) {
+				col.Add(playSession(m, s, units, buyIn, recs[i]))
+				played[i] = true
+				lives[wi].add(recCounts(recs[i]))
+				sessions.Add(1)
+				bar.Increment()
+			}
+			cols[wi] = col
+		}()
+	}
+	wg.Wait()
+	used := time.Since(bar.StartTime())
+	bar.Finish()
+
+	all := new(session.Collector)
+	for _, c := range cols {
+		all.Merge(c)
+	}
+	done := make([]*recorder.SpinRecorder, 0, len(recs))
+	for i, rec := range recs {
+		if played[i] {
+			done = append(done, rec)
+		}
+	}
+	merged, err := recorder.MergeSpinRecorder(done)
+	if err != nil {
+		return nil, nil, nil, 0, err
+	}
+	st := merged.Done()
+	st.Done()
+
+	// players whose every spin was in another bet mode have no base-mode report
+	sts := make([]*stats.StatReport, 0, len(done))
+	for _, rec := range done {
+		if rec.Basic.Rounds == 0 {
+			continue
+		}
+		ps := rec.Done()
+		ps.Done()
+		sts = append(sts, ps)
+	}
+	return st, stats.EstimatorPlayerExp(sts), all.Report(s, buyIn, units[cfg.betMode], cfg.spins), used, nil
+}
+
+// playSession plays one session to its end. Base-mode spins also go to rec, whose player
+// record is overwritten with the session outcome for the upstream estimator.
+func playSession(m *problab.Machine, s *session.Strategy, units []int, buyIn int, rec *recorder.SpinRecorder) *session.Session {
+	p := session.New(s, units, cfg.betMode, buyIn, cfg.spins)
+	for {
+		mode, _, ok := p.Next()
+		if !ok {
+			break
+		}
+		sr := m.SpinInternal(mode)
+		if mode == cfg.betMode {
+			rec.Record(sr)
+		}
+		p.Settle(sr.TotalWin)
+	}
+	pr := rec.Player
+	pr.InitBalance, pr.Balance = p.BuyIn, p.Balance
+	pr.MaxBalance, pr.MinBalance = p.MaxBalance, p.MinBalance
+	pr.Bust = p.End == session.EndBust
+	pr.Cashout = p.End == session.EndStopWin
+	pr.Alive = p.End == session.EndLimit
+	return p
+}
+
+// stdOutSessions prints the session tables that follow the estimator in text mode.
+func stdOutSessions(out io.Writer, rep *session.Report) {
+	p := message.NewPrinter(language.English)
+	p.Fprintf(out, "strategy         : %s\n", rep.Strategy)
+	p.Fprintf(out, "sessions         : %d (buy-in %d)\n", rep.Sessions, rep.BuyIn)
+	p.Fprintf(out, "winning sessions : %.2f%%\n", 100*rep.Winning)
+	p.Fprintf(out, "session rtp      : %.4f%% (avg stake %.2fx base bet)\n", 100*rep.RTP, rep.AvgStakeX)
+	p.Fprintf(out, "mean length      : %.1f spins (%.1f min)\n", rep.MeanLength, rep.MeanMinutes)
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "END\tSESSIONS\tSHARE\t")
+	for _, e := range rep.Ends {
+		p.Fprintf(tw, "%s\t%d\t%.2f%%\t\n", e.End, e.Sessions, 100*e.Share)
+	}
+	tw.Flush()
+
+	tw = tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "PERCENTILE\tLENGTH (spins)\tNET (buy-ins)\t")
+	for i, q := range rep.Length {
+		p.Fprintf(tw, "p%g\t%.0f\t%+.3f\t\n", 100*q.P, q.Value, rep.Net[i].Value)
+	}
+	tw.Flush()
+
+	tw = tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "LENGTH (spins)\tSESSIONS\tSHARE\t")
+	for _, b := range rep.LengthDist {
+		p.Fprintf(tw, "%s\t%d\t%.2f%%\t\n", b.Label(), b.Sessions, 100*b.Share)
+	}
+	tw.Flush()
+
+	tw = tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "SPIN\tMINUTES\tSTILL PLAYING\t")
+	for _, s := range rep.Survival {
+		p.Fprintf(tw, "%d\t%.1f\t%.2f%%\t\n", s.Spins, s.Minutes, 100*s.Alive)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,277 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/zintix-labs/problab-scaffold/internal/lint"
+	"github.com/zintix-labs/problab-scaffold/internal/sheet"
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+	"github.com/zintix-labs/problab/spec"
+	"gopkg.in/yaml.v3"
+)
+
+// sheetDir is where `export` writes and `import` reads the CSV files of a config
+// unless -dir is given.
+const sheetDir = "build/sheets"
+
+// sheetFile is one CSV file of a config: the reels of a reel set, or the pay table of
+// a game mode when set is -1.
+type sheetFile struct {
+	mode, set int
+	path      string
+}
+
+// name is the file name of f within its folder.
+func (f sheetFile) name() string {
+	if f.set < 0 {
+		return fmt.Sprintf("mode%d_pays.csv", f.mode)
+	}
+	return fmt.Sprintf("mode%d_set%d_reels.csv", f.mode, f.set)
+}
+
+// runExport writes the reel strips and pay tables of a config as CSV files for
+// spreadsheets: mode<M>_set<S>_reels.csv for every reel set and mode<M>_pays.csv for
+// every game mode, symbols by name. `import` reads them back.
+func runExport(args []string) {
+	var (
+		id     spec.GID
+		config string
+		dir    string
+	)
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	fs.Var(gidFlag{&id}, "game", "target game id")
+	fs.StringVar(&config, "config", armEmbedded, `config: "embedded" or a .yaml/.json config file of -game`)
+	fs.StringVar(&dir, "dir", "", "output folder (default "+sheetDir+"/<config name>)")
+	fs.Parse(args)
+
+	lab := engine.MustNew()
+	ent, ok := lab.EntryById(id)
+	if !ok {
+		log.Fatalf("value err : game id not found: %d", id)
+	}
+	// the config as written: initialized settings fill in the weights a reel omits
+	var raw []byte
+	var err error
+	if config == armEmbedded {
+		raw, err = engine.ReadConfig(ent.ConfigName)
+		config = ent.ConfigName
+	} else {
+		raw, err = os.ReadFile(config)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	var gs spec.GameSetting
+	if err := yaml.Unmarshal(raw, &gs); err != nil {
+		log.Fatalf("%s: %v", config, err)
+	}
+	if spec.GID(gs.GameID) != id {
+		log.Fatalf("%s is game %d, not -game %d", config, gs.GameID, id)
+	}
+	if dir == "" {
+		dir = defaultSheetDir(config)
+	}
+	files, err := exportSheets(&gs, dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, f := range files {
+		fmt.Println(f)
+	}
+}
+
+// exportSheets writes the CSV files of gs into dir and returns their paths.
+func exportSheets(gs *spec.GameSetting, dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	var paths []string
+	write := func(f sheetFile, fill func(b *bytes.Buffer) error) error {
+		var b bytes.Buffer
+		if err := fill(&b); err != nil {
+			return err
+		}
+		p := filepath.Join(dir, f.name())
+		paths = append(paths, p)
+		return os.WriteFile(p, b.Bytes(), 0o644)
+	}
+	for m, gm := range gs.GameModeSettings {
+		symbols := gm.SymbolSetting.SymbolUsedStr
+		for s, set := range gm.GenScreenSetting.ReelSetGroup {
+			if err := write(sheetFile{mode: m, set: s}, func(b *bytes.Buffer) error { return sheet.WriteReels(b, set.Reels, symbols) }); err != nil {
+				return nil, err
+			}
+		}
+		if err := write(sheetFile{mode: m, set: -1}, func(b *bytes.Buffer) error {
+			return sheet.WritePayTable(b, gm.SymbolSetting.PayTable, symbols)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+// runImport writes reel strips and pay tables from CSV files into a config file: the
+// ones given by -reels/-pays, or every mode<M>_set<S>_reels.csv and mode<M>_pays.csv
+// of -dir. Only those values are rewritten; comments and layout elsewhere are kept. The
+// result is linted first and not written when it has errors.
+func runImport(args []string) {
+	var (
+		config, out, dir, reels, pays string
+		mode, set                     int
+	)
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	fs.StringVar(&config, "config", "", "the .yaml/.json config file to write into")
+	fs.StringVar(&out, "o", "", "write the config to this file instead of -config")
+	fs.StringVar(&dir, "dir", "", "folder of the CSV files written by export (default "+sheetDir+"/<config name>)")
+	fs.StringVar(&reels, "reels", "", "one reels CSV, for reel set -set of game mode -mode")
+	fs.StringVar(&pays, "pays", "", "one pay table CSV, for game mode -mode")
+	fs.IntVar(&mode, "mode", 0, "game mode index of -reels/-pays")
+	fs.IntVar(&set, "set", 0, "reel set index of -reels")
+	fs.Parse(args)
+	if config == "" {
+		log.Fatal("value err : -config is required")
+	}
+	if out == "" {
+		out = config
+	}
+
+	var files []sheetFile
+	if reels != "" {
+		files = append(files, sheetFile{mode: mode, set: set, path: reels})
+	}
+	if pays != "" {
+		files = append(files, sheetFile{mode: mode, set: -1, path: pays})
+	}
+	if len(files) == 0 {
+		if dir == "" {
+			dir = defaultSheetDir(config)
+		}
+		var err error
+		if files, err = sheetFiles(dir); err != nil {
+			log.Fatal(err)
+		}
+	}
+	raw, err := os.ReadFile(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if raw, err = importSheets(raw, strings.EqualFold(filepath.Ext(config), ".json"), files); err != nil {
+		log.Fatalf("%s: %v", config, err)
+	}
+
+	diags := lint.Lint([]lint.Source{{File: out, Raw: raw}})
+	if errs, _ := lint.Count(diags); errs > 0 {
+		for _, d := range diags {
+			fmt.Fprintln(os.Stderr, d)
+		}
+		log.Fatalf("%s not written: the imported config has %d errors", out, errs)
+	}
+	if err := os.WriteFile(out, raw, 0o644); err != nil {
+		log.Fatal(err)
+	}
+	for _, f := range files {
+		fmt.Printf("%s -> %s\n", f.path, out)
+	}
+}
+
+// importSheets writes the CSV files into the config raw. Symbol names are looked up in
+// the symbol_used of each game mode.
+func importSheets(raw []byte, isJSON bool, files []sheetFile) ([]byte, error) {
+	var gs spec.GameSetting
+	if err := yaml.Unmarshal(raw, &gs); err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if f.mode < 0 || f.mode >= len(gs.GameModeSettings) {
+			return nil, fmt.Errorf("%s: the config has no game mode %d", f.path, f.mode)
+		}
+		symbols := gs.GameModeSettings[f.mode].SymbolSetting.SymbolUsedStr
+		in, err := os.Open(f.path)
+		if err != nil {
+			return nil, err
+		}
+		var (
+			p string
+			v *yaml.Node
+		)
+		if f.set < 0 {
+			table, rerr := sheet.ReadPayTable(in, symbols)
+			p, v, err = sheet.PayTablePath(f.mode), sheet.PayTableNode(table), rerr
+		} else {
+			reels, rerr := sheet.ReadReels(in, symbols)
+			p, v, err = sheet.ReelsPath(f.mode, f.set), sheet.ReelsNode(reels), rerr
+		}
+		in.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.path, err)
+		}
+		if raw, err = sheet.Set(raw, isJSON, p, v); err != nil {
+			return nil, fmt.Errorf("%s: %w", f.path, err)
+		}
+	}
+	return raw, nil
+}
+
+// sheetFiles returns the CSV files of dir that export writes, pay tables after reels.
+func sheetFiles(dir string) ([]sheetFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []sheetFile
+	for _, e := range entries {
+		f := sheetFile{path: filepath.Join(dir, e.Name())}
+		if _, err := fmt.Sscanf(e.Name(), "mode%d_set%d_reels.csv", &f.mode, &f.set); err == nil && e.Name() == f.name() {
+			files = append(files, f)
+			continue
+		}
+		f.set = -1
+		if _, err := fmt.Sscanf(e.Name(), "mode%d_pays.csv", &f.mode); err == nil && e.Name() == f.name() {
+			files = append(files, f)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%s has no mode<M>_set<S>_reels.csv or mode<M>_pays.csv", dir)
+	}
+	slices.SortFunc(files, func(a, b sheetFile) int {
+		if (a.set < 0) != (b.set < 0) {
+			if a.set < 0 {
+				return 1
+			}
+			return -1
+		}
+		if a.mode != b.mode {
+			return a.mode - b.mode
+		}
+		return a.set - b.set
+	})
+	return files, nil
+}
+
+// defaultSheetDir is the CSV folder of a config: build/sheets/<file name without ext>.
+func defaultSheetDir(config string) string {
+	base := filepath.Base(config)
+	return filepath.Join(sheetDir, strings.TrimSuffix(base, filepath.Ext(base)))
+}
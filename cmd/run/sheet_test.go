@@ -0,0 +1,81 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+	"github.com/zintix-labs/problab/spec"
+	"gopkg.in/yaml.v3"
+)
+
+func TestExportImport(t *testing.T) {
+	raw, err := engine.ReadConfig("demo_1.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gs spec.GameSetting
+	if err := yaml.Unmarshal(raw, &gs); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if _, err := exportSheets(&gs, dir); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "notes.csv"), []byte("not a sheet\n"), 0o644)
+
+	files, err := sheetFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, filepath.Base(f.path))
+	}
+	want := []string{"mode0_set0_reels.csv", "mode0_set1_reels.csv", "mode1_set0_reels.csv", "mode1_set1_reels.csv", "mode0_pays.csv", "mode1_pays.csv"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("files %v, want %v", names, want)
+	}
+
+	// a spreadsheet edit: the last stop of reel 1 of the base game becomes W1
+	p := filepath.Join(dir, "mode0_set0_reels.csv")
+	b, _ := os.ReadFile(p)
+	rows := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	n := len(gs.GameModeSettings[0].GenScreenSetting.ReelSetGroup[0].Reels[0].ReelSymbols)
+	rows[n] = "W1" + rows[n][strings.IndexByte(rows[n], ','):]
+	os.WriteFile(p, []byte(strings.Join(rows, "\n")+"\n"), 0o644)
+
+	out, err := importSheets(raw, false, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got spec.GameSetting
+	if err := yaml.Unmarshal(out, &got); err != nil {
+		t.Fatal(err)
+	}
+	reel := got.GameModeSettings[0].GenScreenSetting.ReelSetGroup[0].Reels[0].ReelSymbols
+	if reel[n-1] != 2 {
+		t.Fatalf("last stop %d, want W1 (2)", reel[n-1])
+	}
+	reel[n-1] = gs.GameModeSettings[0].GenScreenSetting.ReelSetGroup[0].Reels[0].ReelSymbols[n-1]
+	if !reflect.DeepEqual(got, gs) {
+		t.Fatal("import changed more than the edited stop")
+	}
+}
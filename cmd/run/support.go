@@ -15,16 +15,23 @@
 package main
 
 import (
+	"context"
 	"crypto/rand"
 	"flag"
 	"fmt"
 	"log"
 	"math"
 	"math/big"
+	"os"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/zintix-labs/problab-scaffold/internal/session"
+	"github.com/zintix-labs/problab-scaffold/internal/simstat"
 	"github.com/zintix-labs/problab-scaffold/pkg/engine"
 	"github.com/zintix-labs/problab/spec"
+	"github.com/zintix-labs/problab/stats"
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
 )
@@ -41,6 +48,47 @@ type config struct {
 	betMode   int
 	seed      int64
 	pprofmode string
+	out       string // report format: text|json|csv|yaml
+	outFile   string // report destination; empty means stdout
+
+	precision  float64 // target RTP CI width; 0 disables precision mode
+	confidence float64 // confidence level of the RTP interval
+	chunk      int     // spins per worker per precision chunk
+	maxSpins   int     // total spin budget of a precision run
+
+	all bool // simulate every game and bet mode in the catalog
+
+	checkpoint      string        // checkpoint file; empty disables checkpointing
+	checkpointEvery time.Duration // interval between periodic checkpoints
+	resume          string        // checkpoint file to continue from
+
+	coordinator string        // listen address of the shard coordinator
+	shards      int           // number of shards handed out by the coordinator
+	lease       time.Duration // time before an unreported shard is handed out again
+	join        string        // coordinator URL to run shards for
+	joinWait    time.Duration // how long a worker keeps retrying an unreachable coordinator
+	token       string        // shared secret of a coordinator and its workers
+
+	bands     string    // win band edges in bet multiples, comma-separated
+	bandEdges []float64 // parsed bands
+
+	strategy    string            // player session strategy: preset name or strategy file
+	stopWin     float64           // overrides the strategy's stop_win when set
+	stopLoss    float64           // overrides the strategy's stop_loss when set
+	sessionTime time.Duration     // overrides the strategy's max session duration when set
+	strat       *session.Strategy // resolved strategy
+
+	limitsFile     string        // limits policy file; empty uses defaultLimits
+	maxPlayers     int           // overrides the policy's max_players when set
+	maxPlayerSpins int           // overrides the policy's max_player_spins when set
+	onExceed       string        // overrides the policy's on_exceed when set
+	limits         *limitsReport // applied policy of a player run
+
+	progressAddr  string        // loopback address serving GET /progress; empty disables
+	progressFile  string        // file receiving one JSON progress line per progressEvery
+	progressEvery time.Duration // interval between progress lines
+
+	set map[string]bool // flags given on the command line
 }
 
 type gidFlag struct{ p *spec.GID }
@@ -60,12 +108,43 @@ func bindVar() {
 	flag.IntVar(&cfg.worker, "worker", 1, "number of workers")
 	flag.IntVar(&cfg.player, "player", 1, "number of players")
 	flag.IntVar(&cfg.bets, "bets", 200, "initial bets")
-	flag.IntVar(&cfg.spins, "spins", 10000000, "spins per player")
+	flag.IntVar(&cfg.spins, "spins", 10000000, "spins per worker, or per player with -player > 1 (default there: the limits policy max_player_spins)")
 	flag.IntVar(&cfg.betMode, "mode", 0, "bet mode index")
 	flag.Int64Var(&cfg.seed, "seed", -1, "int64 seed for random number generator")
 	flag.StringVar(&cfg.pprofmode, "p", "", "pprof: '', cpu, heap, allocs")
+	flag.StringVar(&cfg.out, "out", "", "report format: text|json|csv|yaml (default text, or inferred from -o)")
+	flag.StringVar(&cfg.outFile, "o", "", "write the report to this file instead of stdout")
+	flag.Float64Var(&cfg.precision, "target-precision", 0, "run in chunks until the RTP CI width is <= this value (e.g. 0.002 = 0.2%); 0 disables")
+	flag.Float64Var(&cfg.confidence, "confidence", 0.95, "confidence level of the RTP interval, in (0,1)")
+	flag.IntVar(&cfg.chunk, "chunk", 1000000, "spins per worker per chunk in -target-precision mode")
+	flag.IntVar(&cfg.maxSpins, "max-spins", 10000000000, "total spin budget in -target-precision mode")
+	flag.BoolVar(&cfg.all, "all", false, "simulate every registered game and bet mode (ignores -game/-mode)")
+	flag.StringVar(&cfg.checkpoint, "checkpoint", "", "save progress to this file periodically and on SIGINT/SIGTERM")
+	flag.DurationVar(&cfg.checkpointEvery, "checkpoint-every", 5*time.Minute, "interval between checkpoints")
+	flag.StringVar(&cfg.resume, "resume", "", "continue the run saved in this checkpoint file (game/mode/seed/worker/spins come from the file)")
+	flag.StringVar(&cfg.coordinator, "coordinator", "", "run as shard coordinator listening on this address; it merges the counters any client posts, so a bare port (e.g. :5809) listens on 127.0.0.1 and any other host needs -token")
+	flag.IntVar(&cfg.shards, "shards", 0, "number of shards of -spins spins each in -coordinator mode")
+	flag.DurationVar(&cfg.lease, "lease", 30*time.Minute, "re-issue a shard that is not reported back within this time")
+	flag.StringVar(&cfg.join, "join", "", "run as shard worker for this coordinator URL (e.g. http://10.0.0.5:5809)")
+	flag.DurationVar(&cfg.joinWait, "join-wait", time.Minute, "how long a shard worker retries an unreachable coordinator")
+	flag.StringVar(&cfg.token, "token", "", "shared secret a -join worker must present to the -coordinator (default $"+shardTokenEnv+")")
+	flag.StringVar(&cfg.bands, "bands", defaultBands, "win band edges of the distribution table, in bet multiples (bands above max_win_limit are dropped)")
+
+	flag.StringVar(&cfg.strategy, "strategy", session.DefaultStrategy, "player session strategy: "+strings.Join(session.Presets(), "|")+" or a .yaml/.json strategy file (needs -player > 1)")
+	flag.Float64Var(&cfg.stopWin, "stop-win", 0, "leave a session when the balance reaches this many buy-ins (overrides the strategy; 0 never)")
+	flag.Float64Var(&cfg.stopLoss, "stop-loss", 0, "leave a session after losing this fraction of the buy-in (overrides the strategy; 0 play until broke)")
+	flag.DurationVar(&cfg.sessionTime, "session-time", 0, "maximum session duration at 3s per spin unless the strategy sets spin_seconds (overrides the strategy)")
+	flag.StringVar(&cfg.limitsFile, "limits", "", "player simulation limits policy file (.yaml/.json); default: 100k players, 15k spins per player, refuse")
+	flag.IntVar(&cfg.maxPlayers, "max-players", defaultLimits.MaxPlayers, "maximum -player (overrides the limits policy)")
+	flag.IntVar(&cfg.maxPlayerSpins, "max-player-spins", defaultLimits.MaxPlayerSpins, "maximum -spins per player (overrides the limits policy)")
+	flag.StringVar(&cfg.onExceed, "on-exceed", defaultLimits.OnExceed, "when a run exceeds a limit: refuse|warn|clamp (overrides the limits policy)")
+	flag.StringVar(&cfg.progressAddr, "progress-addr", "", "serve live progress as JSON on GET /progress at this loopback address (e.g. 127.0.0.1:5810, or a bare port)")
+	flag.StringVar(&cfg.progressFile, "progress-file", "", "append live progress as JSON lines to this file")
+	flag.DurationVar(&cfg.progressEvery, "progress-every", 10*time.Second, "interval between -progress-file lines")
 
 	flag.Parse()
+	cfg.set = map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { cfg.set[f.Name] = true })
 
 	// given seed illeagel -> default seed
 	if cfg.seed < 1 {
@@ -82,41 +161,186 @@ func executeSimulator() {
 
 	lab := engine.MustNew()
 
-	s, err := lab.NewSimulatorWithSeed(cfg.id, cfg.seed)
-	if err != nil {
+	// first Ctrl-C stops the workers and reports what was simulated so far
+	ctx, stop := interruptible()
+	defer stop()
+
+	var err error
+	if live, err = startProgress(cfg.msgOut()); err != nil {
 		log.Fatal(err)
 	}
-	ent, _ := lab.EntryById(cfg.id)
+
+	if cfg.all {
+		runAll(ctx, lab)
+		return
+	}
+
+	if cfg.join != "" {
+		if err := runShardWorker(ctx, lab, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		if ctx.Err() != nil {
+			fmt.Fprintln(os.Stderr, "interrupted: stopped leasing shards; unfinished shards are leased again by the coordinator")
+			os.Exit(exitInterrupted)
+		}
+		return
+	}
+
+	var cp *checkpoint
+	if cfg.resume != "" {
+		var err error
+		if cp, err = loadCheckpoint(cfg.resume); err != nil {
+			log.Fatal(err)
+		}
+		cfg.useCheckpoint(cp)
+	}
+
+	ent, ok := lab.EntryById(cfg.id)
+	if !ok {
+		log.Fatalf("value err : game id not found: %d", cfg.id)
+	}
 	cfg.name = ent.Name
 	// able to execute
 	green := "\033[1;32m"
 	reset := "\033[0m"
 	p := message.NewPrinter(language.English)
+	w := cfg.msgOut()
 
-	if cfg.player == 1 { // sim machine
+	var (
+		st   *stats.StatReport
+		est  *stats.EstimatorPlayers
+		pi   *precisionInfo
+		sr   *session.Report
+		r    *runner // set by machine runs; source of the win distribution
+		used time.Duration
+	)
+	if cfg.coordinator != "" { // sim machine in shards on other processes
+		p.Fprintf(w, "%s[SHARDS:%d] [GAME:%s] [PLAYMODE:%d] [SPINS:%d]%s\n", green, cfg.shards, cfg.name, cfg.betMode, cfg.shards*cfg.spins, reset)
+		st, r, used, err = runCoordinator(ctx, lab, w)
+	} else if cfg.checkpoint != "" { // sim machine with checkpoints
+		p.Fprintf(w, "%s[WORKERS:%d] [GAME:%s] [PLAYMODE:%d] [SPINS:%d] [CHECKPOINT:%s every %v]%s\n", green, cfg.worker, cfg.name, cfg.betMode, cfg.worker*cfg.spins, cfg.checkpoint, cfg.checkpointEvery, reset)
+		st, r, used, err = runCheckpointed(ctx, lab, cp, w)
+	} else if cfg.precision > 0 { // sim machine until the RTP CI is narrow enough
+		p.Fprintf(w, "%s[WORKERS:%d] [GAME:%s] [PLAYMODE:%d] [TARGET CI WIDTH:%.4f%% @ %.4g%%]%s\n", green, cfg.worker, cfg.name, cfg.betMode, 100*cfg.precision, 100*cfg.confidence, reset)
+		st, r, pi, used, err = runPrecision(ctx, lab, w)
+	} else if cfg.player == 1 { // sim machine
+		// same streams as Sim (1 worker) / SimMP (n workers), observed spin by spin
 		if cfg.worker == 1 {
-			// pure sim singo core
-			p.Printf("%s[GAME:%s] [PLAYMODE:%d] [SPINS:%d]%s\n", green, cfg.name, cfg.betMode, cfg.spins, reset)
-			st, used, _ := s.Sim(cfg.betMode, cfg.spins, true)
-			st.StdOut(used)
+			p.Fprintf(w, "%s[GAME:%s] [PLAYMODE:%d] [SPINS:%d]%s\n", green, cfg.name, cfg.betMode, cfg.spins, reset)
 		} else {
-			// pure sim multi core
-			p.Printf("%s[WORKERS:%d] [GAME:%s] [PLAYMODE:%d] [SPINS:%d]%s\n", green, cfg.worker, cfg.name, cfg.betMode, cfg.worker*cfg.spins, reset)
-			st, used, _ := s.SimMP(cfg.betMode, cfg.spins, cfg.worker, true) // 併發
-			st.StdOut(used)
+			p.Fprintf(w, "%s[WORKERS:%d] [GAME:%s] [PLAYMODE:%d] [SPINS:%d]%s\n", green, cfg.worker, cfg.name, cfg.betMode, cfg.worker*cfg.spins, reset)
+		}
+		if r, err = newRunner(lab, cfg.id, cfg.betMode, cfg.seed, cfg.worker); err == nil {
+			r.showpb = true
+			live.plan("spins", cfg.worker*cfg.spins, nil)
+			live.track(cfg.name, cfg.id, cfg.betMode, cfg.worker*cfg.spins, r.counts)
+			st, used, err = r.run(ctx, cfg.spins)
 		}
 	} else {
 		// sim by player's experenece statemant
-		p.Printf("%s[WORKERS:%d] [GAME:%s] [PLAYERS:%d BALANCE:%d PLAYMODE:%d SPINS:%d]%s\n", green, cfg.worker, cfg.name, cfg.player, cfg.bets, cfg.betMode, cfg.spins, reset)
-		st, est, used, _ := s.SimPlayers(cfg.worker, cfg.player, cfg.bets, cfg.betMode, cfg.spins, true)
+		p.Fprintf(w, "%s[WORKERS:%d] [GAME:%s] [PLAYERS:%d BALANCE:%d PLAYMODE:%d SPINS:%d]%s\n", green, cfg.worker, cfg.name, cfg.player, cfg.bets, cfg.betMode, cfg.spins, reset)
+		p.Fprintf(w, "%s[STRATEGY:%s]%s\n", green, cfg.strat, reset)
+		st, est, sr, used, err = runSessions(ctx, lab, cfg.strat, true)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	part := cfg.partial(ctx, st, r, pi, sr)
+	live.finish(part != nil)
+	if part != nil && st.Summary.Rounds == 0 {
+		log.Fatal("interrupted before the first spin: nothing to report")
+	}
+
+	if pi == nil && cfg.player == 1 {
+		pi = newPrecisionInfo(st, cfg.confidence)
+	}
+
+	var ws *winStats
+	if r != nil {
+		gs, err := engine.GameSetting(ent.ConfigName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		ws = newWinStats(r, gs, gs.BetUnits[cfg.betMode], cfg.bandEdges)
+	}
+
+	format, _ := cfg.outFormat()
+	if format == outText && cfg.outFile == "" {
+		if part != nil {
+			p.Printf("\033[1;31m%s\033[0m\n", part)
+		}
 		st.StdOut(used)
-		est.Out()
+		if cfg.precision > 0 {
+			stdOutPrecision(pi)
+		}
+		if ws != nil {
+			stdOutWinStats(os.Stdout, ws)
+		}
+		if est != nil {
+			est.Out()
+		}
+		if sr != nil {
+			stdOutSessions(os.Stdout, sr)
+		}
+		if cfg.limits != nil {
+			p.Printf("limits           : %s\n", cfg.limits)
+		}
+		if part != nil {
+			p.Printf("\033[1;31m%s\033[0m\n", part)
+			os.Exit(exitInterrupted)
+		}
+		return
+	}
+	streams := cfg.worker
+	if r != nil {
+		streams = len(r.workers) // a coordinator merges one stream per reported shard
+	}
+	rep := newSimReport(ent.ConfigName, st, est, used, streams)
+	rep.Precision = pi
+	rep.Wins = ws
+	rep.Sessions = sr
+	rep.Limits = cfg.limits
+	rep.Partial = part
+	if rep.ConfigHash, err = engine.ConfigSHA256(ent.ConfigName); err != nil {
+		log.Fatal(err)
+	}
+	if err := writeReport(rep, format, cfg.outFile); err != nil {
+		log.Fatal(err)
+	}
+	if cfg.outFile != "" {
+		p.Printf("report written: %s (%s)\n", cfg.outFile, format)
+	}
+	if part != nil {
+		p.Fprintf(os.Stderr, "%s\n", part)
+		os.Exit(exitInterrupted)
 	}
 }
 
-func (cfg *config) valid() {
-	p := message.NewPrinter(language.English)
+// partial describes how far an interrupted run got; it is nil when the run completed.
+func (cfg *config) partial(ctx context.Context, st *stats.StatReport, r *runner, pi *precisionInfo, sr *session.Report) *partialInfo {
+	if ctx.Err() == nil {
+		return nil
+	}
+	var part *partialInfo
+	switch {
+	case sr != nil:
+		part = &partialInfo{Done: sr.Sessions, Planned: cfg.player, Unit: "sessions"}
+	case cfg.coordinator != "":
+		part = &partialInfo{Done: len(r.workers), Planned: cfg.shards, Unit: "shards"}
+	case pi != nil && pi.Converged:
+		return nil
+	case cfg.precision > 0:
+		part = &partialInfo{Done: st.Summary.Rounds, Planned: cfg.maxSpins, Unit: "spins"}
+	default:
+		part = &partialInfo{Done: st.Summary.Rounds, Planned: cfg.worker * cfg.spins, Unit: "spins"}
+	}
+	if part.Done >= part.Planned {
+		return nil
+	}
+	return part
+}
 
+func (cfg *config) valid() {
 	if cfg.worker < 1 {
 		log.Fatal("value err : workers must > 0")
 	}
@@ -124,12 +348,6 @@ func (cfg *config) valid() {
 	if cfg.player < 1 {
 		log.Fatal("value err : player must > 0")
 	}
-	// resize players
-	if cfg.player > 100000 {
-		p.Printf("too much players: %d resized to 100k players\n", cfg.player)
-		cfg.player = 100000
-	}
-
 	if cfg.player > 1 && cfg.bets < 1 {
 		log.Fatal("value err : balance must >= 1")
 	}
@@ -138,16 +356,123 @@ func (cfg *config) valid() {
 		log.Fatal("value err : spins must > 0")
 	}
 
-	// When simulating player-based sessions, cap spins per player to 15,000.
-	// This is an intentional business constraint rather than a technical limit.
-	//
-	// Even under aggressive turbo play (≈2–3 seconds per spin), a single player
-	// can only reach ~1,200–1,800 spins per hour. 15,000 spins already represent
-	// more than 10 hours of continuous high-intensity gameplay, which exceeds
-	// what can be reasonably modeled as a short-term player experience.
-	// For long-horizon behavior, machine-level simulation should be used instead.
-	if cfg.player > 1 && cfg.spins > 15000 {
-		p.Printf("too much spins for each players : %d resized to 15k spins for each player\n", cfg.spins)
-		cfg.spins = 15000
+	if cfg.confidence <= 0 || cfg.confidence >= 1 {
+		log.Fatal("value err : confidence must be in (0,1)")
+	}
+
+	if cfg.precision < 0 {
+		log.Fatal("value err : target-precision must >= 0")
+	}
+
+	if cfg.precision > 0 {
+		if cfg.player > 1 {
+			log.Fatal("value err : target-precision is a machine simulation; use -player 1")
+		}
+		if cfg.chunk < 1 {
+			log.Fatal("value err : chunk must > 0")
+		}
+		if cfg.maxSpins < cfg.chunk*cfg.worker {
+			log.Fatal("value err : max-spins must >= chunk * worker")
+		}
+	}
+
+	if cfg.all && (cfg.player > 1 || cfg.precision > 0) {
+		log.Fatal("value err : -all is a machine simulation; use -player 1 without -target-precision")
+	}
+
+	if cfg.checkpoint != "" || cfg.resume != "" {
+		if cfg.all || cfg.player > 1 || cfg.precision > 0 {
+			log.Fatal("value err : -checkpoint/-resume support fixed-spin machine runs only; use -player 1 without -all/-target-precision")
+		}
+		if cfg.checkpointEvery <= 0 {
+			log.Fatal("value err : checkpoint-every must > 0")
+		}
+	}
+
+	if cfg.coordinator != "" || cfg.join != "" {
+		if cfg.coordinator != "" && cfg.join != "" {
+			log.Fatal("value err : -coordinator and -join are exclusive")
+		}
+		if cfg.all || cfg.player > 1 || cfg.precision > 0 || cfg.checkpoint != "" || cfg.resume != "" {
+			log.Fatal("value err : distributed runs support fixed-spin machine runs only; use -player 1 without -all/-target-precision/-checkpoint")
+		}
+		if cfg.coordinator != "" && cfg.shards < 1 {
+			log.Fatal("value err : shards must > 0")
+		}
+		if cfg.lease <= 0 {
+			log.Fatal("value err : lease must > 0")
+		}
+		if cfg.token == "" {
+			cfg.token = os.Getenv(shardTokenEnv)
+		}
+		if cfg.coordinator != "" {
+			addr, loopback, err := listenAddr(cfg.coordinator)
+			if err != nil {
+				log.Fatal("value err : -coordinator: " + err.Error())
+			}
+			if !loopback && cfg.token == "" {
+				log.Fatal("value err : -coordinator " + addr + " is reachable from other hosts: set -token (or $" + shardTokenEnv + ") on the coordinator and its workers")
+			}
+			cfg.coordinator = addr
+		}
+		cfg.join = strings.TrimRight(cfg.join, "/")
+	}
+
+	if cfg.progressAddr != "" || cfg.progressFile != "" {
+		if cfg.join != "" {
+			log.Fatal("value err : -progress-addr/-progress-file report on the coordinator, not on -join workers")
+		}
+		if cfg.progressEvery <= 0 {
+			log.Fatal("value err : progress-every must > 0")
+		}
+	}
+	if cfg.progressAddr != "" {
+		addr, err := loopbackAddr(cfg.progressAddr)
+		if err != nil {
+			log.Fatal("value err : -progress-addr: " + err.Error())
+		}
+		cfg.progressAddr = addr
+	}
+
+	if cfg.player == 1 && (cfg.set["strategy"] || cfg.set["stop-win"] || cfg.set["stop-loss"] || cfg.set["session-time"]) {
+		log.Fatal("value err : session strategies need -player > 1")
+	}
+	if cfg.player > 1 {
+		strat, err := session.Load(cfg.strategy)
+		if err != nil {
+			log.Fatal("value err : " + err.Error())
+		}
+		if cfg.set["stop-win"] {
+			strat.StopWin = cfg.stopWin
+		}
+		if cfg.set["stop-loss"] {
+			strat.StopLoss = cfg.stopLoss
+		}
+		if cfg.set["session-time"] {
+			strat.MaxMinutes = cfg.sessionTime.Minutes()
+		}
+		if err := strat.Validate(); err != nil {
+			log.Fatal("value err : strategy: " + err.Error())
+		}
+		cfg.strat = strat
+	}
+
+	edges, err := simstat.ParseEdges(cfg.bands)
+	if err != nil {
+		log.Fatal("value err : bands: " + err.Error())
+	}
+	cfg.bandEdges = edges
+
+	if f, err := cfg.outFormat(); err != nil {
+		log.Fatal("value err : " + err.Error())
+	} else if f == outText && cfg.outFile != "" {
+		log.Fatal("value err : -o requires a machine-readable format: -out json|csv|yaml")
+	}
+
+	// player and spin caps come from the limits policy (see limits.go)
+	if cfg.player > 1 {
+		if err := cfg.applyLimits(); err != nil {
+			log.Fatal("value err : " + err.Error())
+		}
 	}
 }
@@ -0,0 +1,154 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/cheggaaa/pb/v3"
+	"github.com/zintix-labs/problab"
+	"github.com/zintix-labs/problab/sdk/buf"
+	"github.com/zintix-labs/problab/spec"
+)
+
+// screenSums accumulates the simulated rounds of one game mode.
+type screenSums struct {
+	n, hits int
+	sum     float64 // round wins
+	sumSq   float64
+	win     float64 // mode wins, including any paid outside a round
+}
+
+// spinTally accumulates spins per game mode, for the reports that need more than the
+// upstream recorder keeps (per-mode rounds and wins beyond base/free).
+type spinTally struct {
+	spins    int
+	hits     int
+	triggers int
+	maxWin   int
+	win      float64
+	winSq    float64
+	modes    []screenSums // by game mode id
+}
+
+func (t *spinTally) add(sr *buf.SpinResult) {
+	t.spins++
+	t.maxWin = max(t.maxWin, sr.TotalWin)
+	win := float64(sr.TotalWin)
+	t.win += win
+	t.winSq += win * win
+	if sr.TotalWin > 0 {
+		t.hits++
+	}
+	if sr.GameModeCount > 1 {
+		t.triggers++
+	}
+	for _, gmr := range sr.GameModeList {
+		if gmr.GameModeId < 0 || gmr.GameModeId >= len(t.modes) {
+			continue
+		}
+		ms := &t.modes[gmr.GameModeId]
+		ms.win += float64(gmr.TotalWin)
+		for _, act := range gmr.ActResults {
+			if !act.IsRoundEnd {
+				continue
+			}
+			win := float64(act.RoundAccWin)
+			ms.n++
+			ms.sum += win
+			ms.sumSq += win * win
+			if act.RoundAccWin > 0 {
+				ms.hits++
+			}
+		}
+	}
+}
+
+func (t *spinTally) merge(o *spinTally) {
+	t.spins += o.spins
+	t.hits += o.hits
+	t.triggers += o.triggers
+	t.maxWin = max(t.maxWin, o.maxWin)
+	t.win += o.win
+	t.winSq += o.winSq
+	for len(t.modes) < len(o.modes) {
+		t.modes = append(t.modes, screenSums{})
+	}
+	for i, s := range o.modes {
+		m := &t.modes[i]
+		m.n += s.n
+		m.hits += s.hits
+		m.sum += s.sum
+		m.sumSq += s.sumSq
+		m.win += s.win
+	}
+}
+
+// tally spins `spins` times on each of `workers` machines of arm, seeded like a plain
+// run, and merges the tallies of all workers. Cancelling ctx stops every worker at the
+// next check; the tally then holds the spins done so far.
+func tally(ctx context.Context, lab *problab.Problab, arm *compareArm, id spec.GID, betMode int, seed int64, workers, spins int, showpb bool) (*spinTally, time.Duration, error) {
+	seeds := shardSeeds(seed, workers)
+	machines := make([]*problab.Machine, len(seeds))
+	for i, seed := range seeds {
+		m, err := arm.machine(lab, id, seed)
+		if err != nil {
+			return nil, 0, err
+		}
+		machines[i] = m
+	}
+	modes := len(arm.gs.GameModeSettings)
+	tallies := make([]*spinTally, len(machines))
+
+	bar := pb.New(spins * len(machines))
+	bar.Set(pb.CleanOnFinish, true)
+	if !showpb {
+		bar.SetWriter(io.Discard)
+	}
+	bar.Start()
+	wg := new(sync.WaitGroup)
+	wg.Add(len(machines))
+	for wi, m := range machines {
+		go func() {
+			defer wg.Done()
+			t := &spinTally{modes: make([]screenSums, modes)}
+			from := 0
+			for n := 0; n < spins; n++ {
+				if n%stopCheck == 0 {
+					if ctx.Err() != nil {
+						break
+					}
+					bar.Add(n - from)
+					from = n
+				}
+				t.add(m.SpinInternal(betMode))
+			}
+			bar.Add(t.spins - from)
+			tallies[wi] = t
+		}()
+	}
+	wg.Wait()
+	used := time.Since(bar.StartTime())
+	bar.Finish()
+
+	all := &spinTally{modes: make([]screenSums, modes)}
+	for _, t := range tallies {
+		all.merge(t)
+	}
+	return all, used, nil
+}
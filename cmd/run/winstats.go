@@ -0,0 +1,153 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/zintix-labs/problab-scaffold/internal/simstat"
+	"github.com/zintix-labs/problab/spec"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// defaultBands are the -bands edges in bet multiples.
+const defaultBands = "1,5,10,20,50,100,250,500,1000,2500,5000"
+
+// percentiles reported in the tail table.
+var percentiles = []struct {
+	q     float64
+	label string
+}{
+	{0.5, "p50"}, {0.9, "p90"}, {0.99, "p99"}, {0.999, "p99.9"}, {0.9999, "p99.99"},
+}
+
+// winStats is the exact per-spin win distribution of a machine run: win bands up to the
+// game's max_win_limit, tail percentiles and the RTP contributed by each game mode.
+type winStats struct {
+	MaxWin      int             `json:"max_win"          yaml:"max_win"`
+	MaxWinX     float64         `json:"max_win_x"        yaml:"max_win_x"`
+	CapX        float64         `json:"cap_x"            yaml:"cap_x"` // max_win_limit in bet multiples; 0 if unset
+	CapHits     int             `json:"cap_hits"         yaml:"cap_hits"`
+	CapOneIn    float64         `json:"cap_one_in"       yaml:"cap_one_in"` // 0 when the cap was never reached
+	Bands       []simstat.Band  `json:"bands"            yaml:"bands"`
+	Percentiles []percentileRow `json:"percentiles"      yaml:"percentiles"`
+	GameModes   []gameModeRow   `json:"game_modes"       yaml:"game_modes"`
+}
+
+// percentileRow is one quantile of the per-spin win.
+type percentileRow struct {
+	P     float64 `json:"p"      yaml:"p"`
+	Label string  `json:"label"  yaml:"label"`
+	Win   int     `json:"win"    yaml:"win"`
+	WinX  float64 `json:"win_x"  yaml:"win_x"`
+	OneIn float64 `json:"one_in" yaml:"one_in"` // spins per win of at least Win
+}
+
+// gameModeRow is the share of the total RTP paid out inside one game mode
+// (0 = base game, 1 = free game, ...).
+type gameModeRow struct {
+	ID    int     `json:"id"     yaml:"id"`
+	Spins int     `json:"spins"  yaml:"spins"` // spins that entered the mode
+	OneIn float64 `json:"one_in" yaml:"one_in"`
+	Win   int     `json:"win"    yaml:"win"`
+	RTP   float64 `json:"rtp"    yaml:"rtp"`
+	Share float64 `json:"share"  yaml:"share"` // of the total win
+}
+
+// newWinStats builds the distribution tables of a finished runner. bet is the bet of
+// one spin in credits.
+func newWinStats(r *runner, gs *spec.GameSetting, bet int, edges []float64) *winStats {
+	c := r.wins()
+	n := c.Spins()
+	x := func(w int) float64 { return float64(w) / float64(bet) }
+	oneIn := func(k int) float64 {
+		if k == 0 {
+			return 0
+		}
+		return float64(n) / float64(k)
+	}
+
+	ws := &winStats{MaxWin: r.maxWin()}
+	ws.MaxWinX = x(ws.MaxWin)
+	if gs.MaxWinLimit > 0 {
+		ws.CapX = x(gs.MaxWinLimit)
+		ws.CapHits = c.AtLeast(gs.MaxWinLimit)
+		ws.CapOneIn = oneIn(ws.CapHits)
+	}
+	ws.Bands = c.Bands(bet, edges, ws.CapX)
+
+	for _, pc := range percentiles {
+		w := c.Quantile(pc.q)
+		ws.Percentiles = append(ws.Percentiles, percentileRow{P: pc.q, Label: pc.label, Win: w, WinX: x(w), OneIn: oneIn(c.AtLeast(w))})
+	}
+
+	win, spins := r.modes()
+	total := 0
+	for _, w := range win {
+		total += w
+	}
+	for id := range win {
+		ws.GameModes = append(ws.GameModes, gameModeRow{
+			ID:    id,
+			Spins: spins[id],
+			OneIn: oneIn(spins[id]),
+			Win:   win[id],
+			RTP:   ratio(win[id], n*bet),
+			Share: ratio(win[id], total),
+		})
+	}
+	return ws
+}
+
+// stdOutWinStats prints the tables that follow the StatReport in text mode.
+func stdOutWinStats(out io.Writer, ws *winStats) {
+	p := message.NewPrinter(language.English)
+	oneIn := func(v float64) string {
+		if v == 0 {
+			return "-"
+		}
+		return p.Sprintf("%.1f", v)
+	}
+
+	p.Fprintf(out, "max win          : %d (%.2fx)\n", ws.MaxWin, ws.MaxWinX)
+	if ws.CapX > 0 {
+		p.Fprintf(out, "max win cap      : %sx hit %d times (1 in %s)\n", strconv.FormatFloat(ws.CapX, 'g', -1, 64), ws.CapHits, oneIn(ws.CapOneIn))
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "BAND\tSPINS\tPROB\t1 IN N\tRTP\t")
+	for _, b := range ws.Bands {
+		p.Fprintf(tw, "%s\t%d\t%.6f%%\t%s\t%.4f%%\t\n", b.Label, b.Spins, 100*b.Prob, oneIn(b.OneIn), 100*b.RTP)
+	}
+	tw.Flush()
+
+	tw = tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "PERCENTILE\tWIN\tWIN (x)\t1 IN N\t")
+	for _, pc := range ws.Percentiles {
+		p.Fprintf(tw, "%s\t%d\t%.2fx\t%s\t\n", pc.Label, pc.Win, pc.WinX, oneIn(pc.OneIn))
+	}
+	tw.Flush()
+
+	tw = tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "GAME MODE\tSPINS\t1 IN N\tWIN\tRTP\tSHARE\t")
+	for _, m := range ws.GameModes {
+		p.Fprintf(tw, "%d\t%d\t%s\t%d\t%.4f%%\t%.2f%%\t\n", m.ID, m.Spins, oneIn(m.OneIn), m.Win, 100*m.RTP, 100*m.Share)
+	}
+	tw.Flush()
+}
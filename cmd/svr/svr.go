@@ -24,12 +24,15 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
+	"net/http"
 
 	"github.com/zintix-labs/problab-scaffold/pkg/engine"
 	"github.com/zintix-labs/problab/server"
 	"github.com/zintix-labs/problab/server/logger"
+	"github.com/zintix-labs/problab/server/netsvr"
 	"github.com/zintix-labs/problab/server/svrcfg"
 )
 
@@ -43,7 +46,31 @@ func main() {
 		fmt.Println(err)
 		return
 	}
-	server.Run(cfg)
+	server.RunWithSvr(cfg, &manifestSvr{netsvr.NewChiServerDefault()})
+}
+
+// manifestSvr is the default upstream server plus GET /manifest, which serves the build
+// manifest (engine.Manifest) in both modes, so an operator can check what a deployment
+// runs with `go run ./cmd/run manifest -url http://<host>/manifest -verify <certified>`.
+type manifestSvr struct {
+	*netsvr.ChiAdapter
+}
+
+// Run adds the route once the upstream routes and middleware are registered (the
+// router takes no middleware after a route), then serves.
+func (s *manifestSvr) Run() error {
+	s.Get("/manifest", serveManifest)
+	return s.ChiAdapter.Run()
+}
+
+func serveManifest(w http.ResponseWriter, r *http.Request) {
+	m, err := engine.Manifest()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(m)
 }
 
 // config holds CLI flag values.
@@ -91,6 +118,9 @@ func loadConfigFromFlags() (*svrcfg.SvrCfg, error) {
 	// This is the main value of the scaffold: users add YAML configs and logic builders,
 	// and the rest is assembled for them.
 	pb := engine.MustNew()
+	if m, err := engine.Manifest(); err == nil {
+		fmt.Printf("[scaffold][manifest] sha256=%s prng=%s\n", m.SHA256, m.PRNG)
+	}
 
 	// Assemble the server configuration used by `problab/server`.
 	sCfg := &svrcfg.SvrCfg{
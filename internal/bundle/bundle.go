@@ -0,0 +1,381 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bundle reads and writes signed config bundles: approved configs shipped
+// outside the binary, whose integrity the engine proves before mounting them.
+//
+// A bundle is a zip archive of
+//
+//	manifest.json   the format, creation time, note, and the path, size and SHA-256 of every config
+//	manifest.sig    the hex Ed25519 signature of manifest.json (absent until signed)
+//	configs/...     the config files, in their folders
+//
+// Open accepts a bundle only when the signature of manifest.json verifies with the
+// given public key and the archive holds exactly the files of the manifest, each with
+// its size and SHA-256. Anything else in the archive is an error.
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"testing/fstest"
+	"time"
+)
+
+// Format is the format of manifest.json; a bundle of another format is refused.
+const Format = "problab-config-bundle/1"
+
+const (
+	manifestName  = "manifest.json"
+	signatureName = "manifest.sig"
+	configsDir    = "configs"
+)
+
+// Size limits of a bundle, checked before an entry is read so that an archive cannot
+// make Read allocate more than its manifest allows.
+const (
+	maxManifestSize  = 1 << 20  // manifest.json
+	maxSignatureSize = 1 << 10  // manifest.sig
+	maxFileSize      = 16 << 20 // one config
+	maxTotalSize     = 64 << 20 // all configs
+)
+
+// Manifest is manifest.json: what the signature covers.
+type Manifest struct {
+	Format  string    `json:"format"`
+	Created time.Time `json:"created"`
+	Note    string    `json:"note,omitempty"`
+	Files   []File    `json:"files"` // sorted by path
+}
+
+// File is one config of a bundle; Path is relative to configs/.
+type File struct {
+	Path   string `json:"path"`
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Bundle is a read bundle whose files match its manifest.
+type Bundle struct {
+	Manifest *Manifest
+	// Signature is the Ed25519 signature of manifest.json; nil when unsigned.
+	Signature []byte
+	// SHA256 is the digest of the whole archive.
+	SHA256 string
+
+	rawManifest []byte
+	files       map[string][]byte // by path under configs/
+}
+
+// FS returns the configs of b: the files checked against the manifest, by their path
+// under configs/. It serves those bytes, never the archive again.
+func (b *Bundle) FS() fs.FS {
+	tree := make(fstest.MapFS, len(b.files))
+	for p, data := range b.files {
+		tree[p] = &fstest.MapFile{Data: data, Mode: 0o444, ModTime: b.Manifest.Created}
+	}
+	return tree
+}
+
+// Create bundles the configs of src: every .yaml, .yml and .json file, skipping files and
+// folders starting with "." or "_" like configs.Mount. It signs the bundle with key
+// unless key is nil.
+func Create(w io.Writer, src fs.FS, note string, created time.Time, key ed25519.PrivateKey) (*Manifest, error) {
+	b := &Bundle{files: map[string][]byte{}}
+	err := fs.WalkDir(src, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != "." && (strings.HasPrefix(d.Name(), ".") || strings.HasPrefix(d.Name(), "_")) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		switch strings.ToLower(path.Ext(p)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		raw, err := fs.ReadFile(src, p)
+		if err != nil {
+			return err
+		}
+		if len(raw) > maxFileSize {
+			return fmt.Errorf("%s: %d bytes, more than the %d bytes a bundled config may have", p, len(raw), maxFileSize)
+		}
+		b.files[p] = raw
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(b.files) == 0 {
+		return nil, errors.New("no config to bundle")
+	}
+	total := 0
+	for _, raw := range b.files {
+		total += len(raw)
+	}
+	if total > maxTotalSize {
+		return nil, fmt.Errorf("%d bytes of configs, more than the %d bytes a bundle may have", total, maxTotalSize)
+	}
+	m := &Manifest{Format: Format, Created: created.UTC().Truncate(time.Second), Note: note}
+	for _, p := range slices.Sorted(maps.Keys(b.files)) {
+		m.Files = append(m.Files, File{Path: p, Size: len(b.files[p]), SHA256: digest(b.files[p])})
+	}
+	if b.rawManifest, err = json.MarshalIndent(m, "", "  "); err != nil {
+		return nil, err
+	}
+	b.Manifest = m
+	if key != nil {
+		b.Signature = ed25519.Sign(key, b.rawManifest)
+	}
+	return m, b.write(w)
+}
+
+// Sign returns the bundle raw signed with key, replacing any signature. The files must
+// match the manifest.
+func Sign(raw []byte, key ed25519.PrivateKey) ([]byte, error) {
+	b, err := Read(raw)
+	if err != nil {
+		return nil, err
+	}
+	b.Signature = ed25519.Sign(key, b.rawManifest)
+	var out bytes.Buffer
+	if err := b.write(&out); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// Open reads the bundle raw and verifies it with the public key pub: it fails when the
+// bundle is unsigned, the signature does not verify, or any file differs from the
+// manifest. The signature is verified before any config is read.
+func Open(raw []byte, pub ed25519.PublicKey) (*Bundle, error) {
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("bundle: public key of %d bytes, want %d", len(pub), ed25519.PublicKeySize)
+	}
+	return read(raw, pub)
+}
+
+// Read reads the bundle raw and checks its files against the manifest, without
+// verifying the signature.
+func Read(raw []byte) (*Bundle, error) {
+	return read(raw, nil)
+}
+
+// read reads manifest.json and manifest.sig first, verifies the signature when pub is
+// set, then reads only the configs the manifest lists, each bounded by its listed size.
+func read(raw []byte, pub ed25519.PublicKey) (*Bundle, error) {
+	zr, err := zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
+	if err != nil {
+		return nil, fmt.Errorf("bundle: %w", err)
+	}
+	b := &Bundle{SHA256: digest(raw), files: map[string][]byte{}}
+	entries := map[string]*zip.File{}
+	for _, f := range zr.File {
+		if entries[f.Name] != nil {
+			return nil, fmt.Errorf("bundle: %s is in the archive twice", f.Name)
+		}
+		entries[f.Name] = f
+		p, inConfigs := strings.CutPrefix(f.Name, configsDir+"/")
+		if !f.Mode().IsRegular() || (inConfigs && !fs.ValidPath(p)) || (!inConfigs && f.Name != manifestName && f.Name != signatureName) {
+			return nil, fmt.Errorf("bundle: unexpected entry %s", f.Name)
+		}
+	}
+
+	mf := entries[manifestName]
+	if mf == nil {
+		return nil, fmt.Errorf("bundle: no %s", manifestName)
+	}
+	if b.rawManifest, err = readEntry(mf, maxManifestSize); err != nil {
+		return nil, fmt.Errorf("bundle: %s: %w", manifestName, err)
+	}
+	if sf := entries[signatureName]; sf != nil {
+		data, err := readEntry(sf, maxSignatureSize)
+		if err != nil {
+			return nil, fmt.Errorf("bundle: %s: %w", signatureName, err)
+		}
+		if b.Signature, err = hex.DecodeString(strings.TrimSpace(string(data))); err != nil || len(b.Signature) != ed25519.SignatureSize {
+			return nil, fmt.Errorf("bundle: %s is not a hex Ed25519 signature", signatureName)
+		}
+	}
+	if pub != nil {
+		if b.Signature == nil {
+			return nil, errors.New("bundle: not signed")
+		}
+		if !ed25519.Verify(pub, b.rawManifest, b.Signature) {
+			return nil, errors.New("bundle: the signature does not verify with the public key")
+		}
+	}
+
+	m := new(Manifest)
+	dec := json.NewDecoder(bytes.NewReader(b.rawManifest))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(m); err != nil {
+		return nil, fmt.Errorf("bundle: %s: %w", manifestName, err)
+	}
+	if m.Format != Format {
+		return nil, fmt.Errorf("bundle: format %q, want %q", m.Format, Format)
+	}
+	total := 0
+	for _, f := range m.Files {
+		if f.Size < 0 || f.Size > maxFileSize {
+			return nil, fmt.Errorf("bundle: %s: size %d, a bundled config may have %d bytes at most", f.Path, f.Size, maxFileSize)
+		}
+		if total += f.Size; total > maxTotalSize {
+			return nil, fmt.Errorf("bundle: the manifest lists more than %d bytes of configs", maxTotalSize)
+		}
+	}
+	for _, f := range m.Files {
+		if _, ok := b.files[f.Path]; ok {
+			return nil, fmt.Errorf("bundle: %s is in the manifest twice", f.Path)
+		}
+		zf := entries[configsDir+"/"+f.Path]
+		if zf == nil {
+			return nil, fmt.Errorf("bundle: %s is in the manifest but not in the archive", f.Path)
+		}
+		if zf.UncompressedSize64 != uint64(f.Size) {
+			return nil, fmt.Errorf("bundle: %s does not match the manifest (size %d; manifest: size %d)", f.Path, zf.UncompressedSize64, f.Size)
+		}
+		data, err := readEntry(zf, int64(f.Size))
+		if err != nil {
+			return nil, fmt.Errorf("bundle: %s: %w", zf.Name, err)
+		}
+		if digest(data) != f.SHA256 {
+			return nil, fmt.Errorf("bundle: %s does not match the manifest (sha256 %s; manifest: sha256 %s)", f.Path, digest(data), f.SHA256)
+		}
+		b.files[f.Path] = data
+	}
+	for name := range entries {
+		if p, ok := strings.CutPrefix(name, configsDir+"/"); ok {
+			if _, listed := b.files[p]; !listed {
+				return nil, fmt.Errorf("bundle: %s is not in the manifest", p)
+			}
+		}
+	}
+	b.Manifest = m
+	return b, nil
+}
+
+// write writes b as a zip archive. Entries are in a fixed order and dated at the
+// creation time, so the same bundle is written to the same bytes.
+func (b *Bundle) write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	add := func(name string, data []byte) error {
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: b.Manifest.Created})
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		return err
+	}
+	if err := add(manifestName, b.rawManifest); err != nil {
+		return err
+	}
+	if b.Signature != nil {
+		if err := add(signatureName, []byte(hex.EncodeToString(b.Signature)+"\n")); err != nil {
+			return err
+		}
+	}
+	for _, f := range b.Manifest.Files {
+		if err := add(configsDir+"/"+f.Path, b.files[f.Path]); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// readEntry reads a zip entry of at most limit bytes, refusing more bytes than it
+// declares. The declared size is checked before anything is read.
+func readEntry(f *zip.File, limit int64) ([]byte, error) {
+	if f.UncompressedSize64 > uint64(limit) {
+		return nil, fmt.Errorf("%d bytes, more than the %d bytes allowed", f.UncompressedSize64, limit)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, int64(f.UncompressedSize64)+1))
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(data)) != f.UncompressedSize64 {
+		return nil, errors.New("size differs from its header")
+	}
+	return data, nil
+}
+
+// GenerateKey returns a new signing key pair, the private key as PKCS #8 PEM.
+func GenerateKey(rand io.Reader) (pub ed25519.PublicKey, privPEM []byte, err error) {
+	pub, priv, err := ed25519.GenerateKey(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pub, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// ParsePrivateKey parses a PKCS #8 PEM Ed25519 private key.
+func ParsePrivateKey(privPEM []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(privPEM)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, errors.New("not a PEM private key")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("a %T private key, want Ed25519", key)
+	}
+	return priv, nil
+}
+
+// ParsePublicKey parses a hex Ed25519 public key, as printed by `bundle keygen`.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	pub, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("not a hex Ed25519 public key of %d bytes", ed25519.PublicKeySize)
+	}
+	return pub, nil
+}
+
+func digest(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
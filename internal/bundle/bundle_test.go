@@ -0,0 +1,287 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+var created = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func testTree() fstest.MapFS {
+	return fstest.MapFS{
+		"studio/a/a.yaml":      {Data: []byte("game_id: 1\n")},
+		"studio/b/b.json":      {Data: []byte(`{"game_id": 2}`)},
+		"studio/b/notes.txt":   {Data: []byte("not a config")},
+		"studio/_base/x.yaml":  {Data: []byte("game_id: 3\n")},
+		"studio/.hidden.yaml":  {Data: []byte("game_id: 4\n")},
+		"studio/a/README.md":   {Data: []byte("# a")},
+		"studio/a/_draft.yaml": {Data: []byte("game_id: 5\n")},
+	}
+}
+
+func testKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	pub, privPEM, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := ParsePrivateKey(privPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pub, priv
+}
+
+func create(t *testing.T, key ed25519.PrivateKey) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if _, err := Create(&buf, testTree(), "release 1", created, key); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// rewrite copies the archive raw through edit, which may change or drop (nil) an entry
+// and returns extra entries to append.
+func rewrite(t *testing.T, raw []byte, edit func(name string, data []byte) []byte, extra map[string][]byte) []byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	write := func(name string, data []byte) {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(data)
+	}
+	for _, f := range zr.File {
+		data, err := readEntry(f, int64(f.UncompressedSize64))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data = edit(f.Name, data); data != nil {
+			write(f.Name, data)
+		}
+	}
+	for name, data := range extra {
+		write(name, data)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func keep(_ string, data []byte) []byte { return data }
+
+func TestCreateOpen(t *testing.T) {
+	pub, priv := testKey(t)
+	raw := create(t, priv)
+	if again := create(t, priv); !bytes.Equal(raw, again) {
+		t.Fatal("the same bundle is written to different bytes")
+	}
+
+	b, err := Open(raw, pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := b.Manifest
+	if m.Format != Format || !m.Created.Equal(created) || m.Note != "release 1" {
+		t.Fatalf("manifest = %+v", m)
+	}
+	var paths []string
+	for _, f := range m.Files {
+		paths = append(paths, f.Path)
+	}
+	if got := strings.Join(paths, " "); got != "studio/a/a.yaml studio/b/b.json" {
+		t.Fatalf("files = %s", got)
+	}
+	if err := fstest.TestFS(b.FS(), "studio/a/a.yaml", "studio/b/b.json"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := fs.ReadFile(b.FS(), "studio/b/b.json")
+	if err != nil || string(data) != `{"game_id": 2}` {
+		t.Fatalf("b.json = %q, %v", data, err)
+	}
+
+	// FS serves the verified bytes, not the archive: changing it afterwards changes nothing
+	clear(raw)
+	data, err = fs.ReadFile(b.FS(), "studio/b/b.json")
+	if err != nil || string(data) != `{"game_id": 2}` {
+		t.Fatalf("b.json after the archive changed = %q, %v", data, err)
+	}
+}
+
+func TestSign(t *testing.T) {
+	pub, priv := testKey(t)
+	unsigned := create(t, nil)
+	if _, err := Open(unsigned, pub); err == nil || !strings.Contains(err.Error(), "not signed") {
+		t.Fatalf("unsigned bundle: %v", err)
+	}
+	if b, err := Read(unsigned); err != nil || b.Signature != nil {
+		t.Fatalf("Read unsigned = %v", err)
+	}
+	signed, err := Sign(unsigned, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(signed, create(t, priv)) {
+		t.Fatal("Sign differs from Create with the key")
+	}
+	if _, err := Open(signed, pub); err != nil {
+		t.Fatal(err)
+	}
+
+	other, otherPriv := testKey(t)
+	if _, err := Open(signed, other); err == nil || !strings.Contains(err.Error(), "does not verify") {
+		t.Fatalf("wrong key: %v", err)
+	}
+	resigned, err := Sign(signed, otherPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(resigned, other); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(resigned, pub); err == nil {
+		t.Fatal("the old key verifies a bundle signed again")
+	}
+	if _, err := Open(signed, pub[:16]); err == nil {
+		t.Fatal("Open accepts a short key")
+	}
+}
+
+func TestOpenRejects(t *testing.T) {
+	pub, priv := testKey(t)
+	raw := create(t, priv)
+	tests := []struct {
+		name  string
+		raw   []byte
+		error string
+	}{
+		{"tampered file", rewrite(t, raw, func(name string, data []byte) []byte {
+			if name == "configs/studio/a/a.yaml" {
+				return []byte("game_id: 9\n")
+			}
+			return data
+		}, nil), "does not match the manifest"},
+		{"tampered manifest", rewrite(t, raw, func(name string, data []byte) []byte {
+			if name == manifestName {
+				return bytes.Replace(data, []byte("release 1"), []byte("release 2"), 1)
+			}
+			return data
+		}, nil), "does not verify"},
+		{"missing file", rewrite(t, raw, func(name string, data []byte) []byte {
+			if name == "configs/studio/b/b.json" {
+				return nil
+			}
+			return data
+		}, nil), "not in the archive"},
+		{"extra file", rewrite(t, raw, keep, map[string][]byte{"configs/studio/c.yaml": []byte("game_id: 7\n")}), "not in the manifest"},
+		{"unexpected entry", rewrite(t, raw, keep, map[string][]byte{"README": []byte("hi")}), "unexpected entry"},
+		{"escaping path", rewrite(t, raw, keep, map[string][]byte{"configs/../x.yaml": []byte("game_id: 7\n")}), "unexpected entry"},
+		{"duplicate entry", rewrite(t, raw, keep, map[string][]byte{"configs/studio/a/a.yaml": []byte("game_id: 1\n")}), "twice"},
+		{"bad signature", rewrite(t, raw, func(name string, data []byte) []byte {
+			if name == signatureName {
+				return []byte(hex.EncodeToString(make([]byte, 10)))
+			}
+			return data
+		}, nil), "not a hex Ed25519 signature"},
+		{"no manifest", rewrite(t, raw, func(name string, data []byte) []byte {
+			if name == manifestName {
+				return nil
+			}
+			return data
+		}, nil), "no manifest.json"},
+		{"not a zip", []byte("game_id: 1\n"), "zip"},
+		{"large manifest", rewrite(t, raw, func(name string, data []byte) []byte {
+			if name == manifestName {
+				return append(bytes.Repeat([]byte(" "), maxManifestSize), data...)
+			}
+			return data
+		}, nil), "more than"},
+		// the signature is checked before a config is read: a forged manifest never
+		// gets to list the large entry
+		{"forged manifest, large file", rewrite(t, raw, func(name string, data []byte) []byte {
+			if name == manifestName {
+				return bytes.Replace(data, []byte("release 1"), []byte("release 2"), 1)
+			}
+			return data
+		}, map[string][]byte{"configs/studio/big.yaml": make([]byte, maxFileSize+1)}), "does not verify"},
+		{"file larger than listed", rewrite(t, raw, func(name string, data []byte) []byte {
+			if name == "configs/studio/a/a.yaml" {
+				return append(data, '\n')
+			}
+			return data
+		}, nil), "does not match the manifest"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Open(tt.raw, pub)
+			if err == nil || !strings.Contains(err.Error(), tt.error) {
+				t.Fatalf("Open = %v, want an error containing %q", err, tt.error)
+			}
+		})
+	}
+}
+
+func TestCreateLimits(t *testing.T) {
+	src := fstest.MapFS{"big.yaml": {Data: make([]byte, maxFileSize+1)}}
+	if _, err := Create(io.Discard, src, "", created, nil); err == nil || !strings.Contains(err.Error(), "more than") {
+		t.Fatalf("Create with a large config: %v", err)
+	}
+	src = fstest.MapFS{}
+	for i := range maxTotalSize/maxFileSize + 1 {
+		src[fmt.Sprintf("c%d.yaml", i)] = &fstest.MapFile{Data: make([]byte, maxFileSize)}
+	}
+	if _, err := Create(io.Discard, src, "", created, nil); err == nil || !strings.Contains(err.Error(), "more than") {
+		t.Fatalf("Create with large configs: %v", err)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	pub, priv := testKey(t)
+	got, err := ParsePublicKey(" " + hex.EncodeToString(pub) + "\n")
+	if err != nil || !got.Equal(pub) {
+		t.Fatalf("ParsePublicKey = %x, %v", got, err)
+	}
+	if !priv.Public().(ed25519.PublicKey).Equal(pub) {
+		t.Fatal("the private key does not match the public key")
+	}
+	for _, bad := range []string{"", "zz", hex.EncodeToString(pub[:31])} {
+		if _, err := ParsePublicKey(bad); err == nil {
+			t.Errorf("ParsePublicKey(%q) succeeds", bad)
+		}
+	}
+	if _, err := ParsePrivateKey([]byte("not pem")); err == nil {
+		t.Error("ParsePrivateKey accepts a non-PEM key")
+	}
+}
@@ -16,21 +16,28 @@ package configs
 
 import (
 	"embed"
+	"io/fs"
 )
 
-// FS provides embedded default config YAMLs for this scaffold.
+//go:embed games
+var games embed.FS
+
+// FS provides the embedded default config tree of this scaffold: the `games` folder
+// next to this file.
 //
 // Important:
 //   - Do NOT delete or move this file unless you also update engine wiring.
-//   - Problab-scaffold expects a *flat* config FS layout by default:
-//   - YAML files are addressed by filename only (no nested paths)
-//   - configs are embedded from the same folder as this file
-//
-// If you want to organize configs into subfolders, you must also adjust:
-//   - the embed pattern(s) below
-//   - the engine/config loading logic that consumes this FS
-//
-// Embed all YAML files in this directory (flat layout).
+//   - Configs may be organised in subfolders, e.g. games/<studio>/<game>/*.yaml; files
+//     and folders starting with "." or "_" are skipped.
+//   - The engine reads configs by file name only (the catalog ConfigName), so a file
+//     name must be unique across all folders; see Mount.
 //
-//go:embed *.yaml
-var FS embed.FS
+// The engine does not read FS directly: it mounts it (and any override sources) with
+// Mount into one flat view.
+var FS = func() fs.FS {
+	sub, err := fs.Sub(games, "games")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}()
@@ -0,0 +1,363 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zintix-labs/problab/spec"
+	"gopkg.in/yaml.v3"
+)
+
+// Source is a config tree to mount, such as FS or an os.DirFS override directory.
+type Source struct {
+	Name string // shown in errors, e.g. "embedded" or the directory
+	FS   fs.FS
+	// Override lets the games of this source replace the games with the same game_id
+	// mounted from earlier sources. Without it, such a game_id is an error.
+	Override bool
+}
+
+// Mounted is the flat, read-only view of mounted sources that the engine reads: every
+// config at the root under its file name, as the upstream catalog requires. Files are
+// read once at mount, so the engine parses and hashes the same bytes.
+//
+// It implements fs.FS, fs.ReadDirFS and fs.ReadFileFS.
+type Mounted struct {
+	files map[string]*mountedFile // by file name
+	names []string                // sorted
+}
+
+// mountedFile is one config of a source.
+type mountedFile struct {
+	name    string // file name, the catalog ConfigName
+	origin  string // source name and path, e.g. embedded:zintix/demo_0/demo_0.yaml
+	id      spec.GID
+	game    string
+	base    string   // base file name of a variant
+	src     []byte   // as read
+	raw     []byte   // resolved: src, or the variant overlaid on its base
+	lineage []string // origins from the file to its root base; nil until resolved
+}
+
+// Mount walks the sources in order and mounts every .yaml/.yml/.json config of their
+// trees. Files and folders starting with "." or "_" are skipped.
+//
+// The rules:
+//   - a game_id may appear once per source;
+//   - a later source replaces the game of an earlier one with the same game_id only if
+//     it is mounted with Override, and the replaced file leaves the view;
+//   - game names (case-insensitive) and file names must be unique among the mounted
+//     games, whichever folders and sources they come from;
+//   - a variant (see VariantBase) names a mounted file as its base and is served
+//     resolved, in the format of its own file.
+//
+// Any violation fails the mount with an error naming both files.
+func Mount(sources ...Source) (*Mounted, error) {
+	if len(sources) == 0 {
+		return nil, errors.New("no config source to mount")
+	}
+	byID := make(map[spec.GID]*mountedFile)
+	for _, src := range sources {
+		if src.FS == nil {
+			return nil, fmt.Errorf("config source %q has no fs", src.Name)
+		}
+		inSource := make(map[spec.GID]*mountedFile)
+		err := fs.WalkDir(src.FS, ".", func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if p != "." && (strings.HasPrefix(d.Name(), ".") || strings.HasPrefix(d.Name(), "_")) {
+				if d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() || !isConfig(p) {
+				return nil
+			}
+			f, err := readHead(src, p)
+			if err != nil {
+				return err
+			}
+			if prev, ok := inSource[f.id]; ok {
+				return fmt.Errorf("duplicate game_id %d: %s and %s", f.id, prev.origin, f.origin)
+			}
+			inSource[f.id] = f
+			if prev, ok := byID[f.id]; ok && !src.Override {
+				return fmt.Errorf("game_id %d of %s is already mounted from %s: mount %q with Override to replace it", f.id, f.origin, prev.origin, src.Name)
+			}
+			byID[f.id] = f
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("mount config source %q: %w", src.Name, err)
+		}
+	}
+
+	m := &Mounted{files: make(map[string]*mountedFile, len(byID))}
+	games := make(map[string]*mountedFile, len(byID))
+	ids := slices.Sorted(func(yield func(spec.GID) bool) {
+		for id := range byID {
+			if !yield(id) {
+				return
+			}
+		}
+	})
+	for _, id := range ids {
+		f := byID[id]
+		if key := strings.ToLower(strings.TrimSpace(f.game)); key != "" {
+			if prev, ok := games[key]; ok {
+				return nil, fmt.Errorf("duplicate game name %q: %s (game_id %d) and %s (game_id %d)", f.game, prev.origin, prev.id, f.origin, f.id)
+			}
+			games[key] = f
+		}
+		if prev, ok := m.files[f.name]; ok {
+			return nil, fmt.Errorf("duplicate config file name %q: %s and %s; the engine reads configs by file name, rename one", f.name, prev.origin, f.origin)
+		}
+		m.files[f.name] = f
+		m.names = append(m.names, f.name)
+	}
+	slices.Sort(m.names)
+	for _, name := range m.names {
+		if err := m.resolve(m.files[name], nil); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// resolve resolves the raw bytes and lineage of f; chain holds the variants being
+// resolved, to catch cycles.
+func (m *Mounted) resolve(f *mountedFile, chain []string) error {
+	if f.lineage != nil {
+		return nil
+	}
+	if f.base == "" {
+		f.raw, f.lineage = f.src, []string{f.origin}
+		return nil
+	}
+	if slices.Contains(chain, f.name) {
+		return fmt.Errorf("variant cycle: %s -> %s", strings.Join(chain, " -> "), f.name)
+	}
+	base, ok := m.files[f.base]
+	if !ok {
+		return fmt.Errorf("%s: base %q is not a mounted config", f.origin, f.base)
+	}
+	if err := m.resolve(base, append(chain, f.name)); err != nil {
+		return err
+	}
+	root, err := parseRoot(base.raw)
+	if err != nil {
+		return fmt.Errorf("%s: base %s: %w", f.origin, base.origin, err)
+	}
+	variant, err := parseRoot(f.src)
+	if err != nil {
+		return fmt.Errorf("%s: %w", f.origin, err)
+	}
+	if err := Overlay(root, variant); err != nil {
+		return fmt.Errorf("%s: %w", f.origin, err)
+	}
+	if f.raw, err = encode(root, f.name); err != nil {
+		return fmt.Errorf("%s: %w", f.origin, err)
+	}
+	f.lineage = append([]string{f.origin}, base.lineage...)
+	return nil
+}
+
+// parseRoot parses a config and returns its root mapping.
+func parseRoot(raw []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("a config must be a mapping")
+	}
+	return doc.Content[0], nil
+}
+
+// encode writes a resolved config in the format of the file name.
+func encode(root *yaml.Node, name string) ([]byte, error) {
+	if strings.EqualFold(path.Ext(name), ".json") {
+		var v any
+		if err := root.Decode(&v); err != nil {
+			return nil, err
+		}
+		return json.MarshalIndent(v, "", "  ")
+	}
+	return yaml.Marshal(root)
+}
+
+// readHead reads the config at p and the game_id and game_name it declares.
+func readHead(src Source, p string) (*mountedFile, error) {
+	f := &mountedFile{name: path.Base(p), origin: src.Name + ":" + p}
+	raw, err := fs.ReadFile(src.FS, p)
+	if err != nil {
+		return nil, err
+	}
+	var head struct {
+		GameID   *spec.GID `yaml:"game_id"`
+		GameName string    `yaml:"game_name"`
+		Base     string    `yaml:"base"`
+	}
+	if err := yaml.Unmarshal(raw, &head); err != nil {
+		return nil, fmt.Errorf("%s: %w", f.origin, err)
+	}
+	if head.GameID == nil {
+		return nil, fmt.Errorf("%s: game_id is missing", f.origin)
+	}
+	if head.Base != "" && strings.TrimSpace(head.GameName) == "" {
+		return nil, fmt.Errorf("%s: a variant must set its own game_id and game_name", f.origin)
+	}
+	f.id, f.game, f.base, f.src = *head.GameID, head.GameName, head.Base, raw
+	return f, nil
+}
+
+func isConfig(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// Origin returns the source and path the mounted config name comes from, e.g.
+// "embedded:zintix/demo_0/demo_0.yaml".
+func (m *Mounted) Origin(name string) (string, bool) {
+	f, ok := m.files[name]
+	if !ok {
+		return "", false
+	}
+	return f.origin, true
+}
+
+// Lineage returns the origins of the config and of its bases, up to the root base: only
+// its own origin when it is not a variant.
+func (m *Mounted) Lineage(name string) ([]string, bool) {
+	f, ok := m.files[name]
+	if !ok {
+		return nil, false
+	}
+	return slices.Clone(f.lineage), true
+}
+
+// Open implements fs.FS.
+func (m *Mounted) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		entries, _ := m.ReadDir(".")
+		return &mountedDir{entries: entries}, nil
+	}
+	f, ok := m.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &openFile{Reader: bytes.NewReader(f.raw), info: fileInfo{name: f.name, size: int64(len(f.raw))}}, nil
+}
+
+// ReadDir implements fs.ReadDirFS; only the root is a directory.
+func (m *Mounted) ReadDir(name string) ([]fs.DirEntry, error) {
+	if name != "." {
+		if _, ok := m.files[name]; ok || !fs.ValidPath(name) {
+			return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+		}
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	entries := make([]fs.DirEntry, len(m.names))
+	for i, n := range m.names {
+		entries[i] = fileInfo{name: n, size: int64(len(m.files[n].raw))}
+	}
+	return entries, nil
+}
+
+// ReadFile implements fs.ReadFileFS.
+func (m *Mounted) ReadFile(name string) ([]byte, error) {
+	f, ok := m.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: fs.ErrNotExist}
+	}
+	return bytes.Clone(f.raw), nil
+}
+
+// fileInfo is the fs.FileInfo and fs.DirEntry of a mounted config or of the root.
+type fileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i fileInfo) Name() string       { return i.name }
+func (i fileInfo) Size() int64        { return i.size }
+func (i fileInfo) ModTime() time.Time { return time.Time{} }
+func (i fileInfo) IsDir() bool        { return i.dir }
+func (i fileInfo) Sys() any           { return nil }
+func (i fileInfo) Type() fs.FileMode  { return i.Mode().Type() }
+
+func (i fileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+
+func (i fileInfo) Info() (fs.FileInfo, error) { return i, nil }
+
+// openFile is an open mounted config.
+type openFile struct {
+	*bytes.Reader
+	info fileInfo
+}
+
+func (f *openFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *openFile) Close() error               { return nil }
+
+// mountedDir is the open root.
+type mountedDir struct {
+	entries []fs.DirEntry
+	off     int
+}
+
+func (d *mountedDir) Stat() (fs.FileInfo, error) { return fileInfo{name: ".", dir: true}, nil }
+func (d *mountedDir) Close() error               { return nil }
+
+func (d *mountedDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: ".", Err: errors.New("is a directory")}
+}
+
+func (d *mountedDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.off:]
+	if n <= 0 {
+		d.off = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	rest = rest[:min(n, len(rest))]
+	d.off += len(rest)
+	return rest, nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configs
+
+import (
+	"io/fs"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func config(id, name string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte("game_id: " + id + "\ngame_name: " + name + "\n")}
+}
+
+func TestMountEmbedded(t *testing.T) {
+	m, err := Mount(Source{Name: "embedded", FS: FS})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fstest.TestFS(m, "demo_0.yaml", "demo_1.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	if o, _ := m.Origin("demo_0.yaml"); o != "embedded:zintix/demo_0/demo_0.yaml" {
+		t.Fatalf("origin %q", o)
+	}
+}
+
+func TestMountOverride(t *testing.T) {
+	base := fstest.MapFS{
+		"studio_a/alpha/alpha.yaml": config("1", "alpha"),
+		"studio_a/beta/beta.yaml":   config("2", "beta"),
+		"studio_b/gamma.json":       {Data: []byte(`{"game_id": 3, "game_name": "gamma"}`)},
+		"studio_b/readme.md":        {Data: []byte("not a config")},
+		"_drafts/delta.yaml":        config("1", "delta"),
+		".hidden.yaml":              config("1", "hidden"),
+	}
+	local := fstest.MapFS{
+		"beta_hot.yaml": config("2", "beta"),
+	}
+	m, err := Mount(Source{Name: "embedded", FS: base}, Source{Name: "local", FS: local, Override: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := fs.ReadDir(m, ".")
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if want := []string{"alpha.yaml", "beta_hot.yaml", "gamma.json"}; !slices.Equal(names, want) {
+		t.Fatalf("mounted %v, want %v", names, want)
+	}
+	if o, _ := m.Origin("beta_hot.yaml"); o != "local:beta_hot.yaml" {
+		t.Fatalf("origin %q", o)
+	}
+	if err := fstest.TestFS(m, "alpha.yaml", "beta_hot.yaml", "gamma.json"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMountErrors(t *testing.T) {
+	base := fstest.MapFS{
+		"a/alpha/alpha.yaml": config("1", "alpha"),
+	}
+	cases := []struct {
+		name    string
+		sources []Source
+		want    string
+	}{
+		{"id across sources", []Source{{Name: "embedded", FS: base}, {Name: "local", FS: fstest.MapFS{"x.yaml": config("1", "x")}}},
+			`game_id 1 of local:x.yaml is already mounted from embedded:a/alpha/alpha.yaml: mount "local" with Override`},
+		{"id within source", []Source{{Name: "embedded", FS: fstest.MapFS{"a/x.yaml": config("1", "x"), "b/y.yaml": config("1", "y")}}},
+			"duplicate game_id 1: embedded:a/x.yaml and embedded:b/y.yaml"},
+		{"name across sources", []Source{{Name: "embedded", FS: base}, {Name: "local", FS: fstest.MapFS{"x.yaml": config("2", "Alpha")}, Override: true}},
+			`duplicate game name "Alpha": embedded:a/alpha/alpha.yaml (game_id 1) and local:x.yaml (game_id 2)`},
+		{"file name", []Source{{Name: "embedded", FS: fstest.MapFS{"a/game.yaml": config("1", "x"), "b/game.yaml": config("2", "y")}}},
+			`duplicate config file name "game.yaml": embedded:a/game.yaml and embedded:b/game.yaml`},
+		{"no game_id", []Source{{Name: "embedded", FS: fstest.MapFS{"x.yaml": {Data: []byte("game_name: x\n")}}}},
+			"embedded:x.yaml: game_id is missing"},
+		{"no source", nil, "no config source"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := Mount(c.sources...)
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Fatalf("got %v, want %q", err, c.want)
+			}
+		})
+	}
+}
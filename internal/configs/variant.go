@@ -0,0 +1,203 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// A variant is a config that declares the file name of a base config and changes only
+// some of it, e.g. the reel weights of another RTP point:
+//
+//	base: demo_0.yaml
+//	game_id: 100
+//	game_name: demo_normal_94
+//	overrides:
+//	  game_mode_settings[0].gen_screen_setting.reel_set_group[0].reels[2].weights: [3, 1, 2]
+//
+// Its other top-level keys replace those of the base, and each overrides entry replaces
+// the value at its path; lists are replaced whole. A variant must set its own game_id
+// and game_name, and its base may itself be a variant.
+const (
+	BaseKey      = "base"
+	OverridesKey = "overrides"
+)
+
+// VariantBase returns the base file name declared by the config root, if it is a variant.
+func VariantBase(root *yaml.Node) (string, bool) {
+	if root == nil || root.Kind != yaml.MappingNode {
+		return "", false
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == BaseKey {
+			return root.Content[i+1].Value, true
+		}
+	}
+	return "", false
+}
+
+// OverlayError is an override of a variant that cannot be applied.
+type OverlayError struct {
+	Node *yaml.Node // the key of the override in the variant
+	Path string
+	Msg  string
+}
+
+func (e *OverlayError) Error() string {
+	return fmt.Sprintf("line %d: override %s: %s", e.Node.Line, e.Path, e.Msg)
+}
+
+// Overlay applies the variant root onto the resolved base root, in place. The value nodes
+// of variant are moved into base, so they keep their lines and columns in the variant.
+func Overlay(base, variant *yaml.Node) error {
+	if base.Kind != yaml.MappingNode || variant.Kind != yaml.MappingNode {
+		return &OverlayError{Node: variant, Path: ".", Msg: "base and variant must be mappings"}
+	}
+	for i := 0; i+1 < len(variant.Content); i += 2 {
+		k, v := variant.Content[i], variant.Content[i+1]
+		switch k.Value {
+		case BaseKey:
+		case OverridesKey:
+			if v.Kind != yaml.MappingNode {
+				return &OverlayError{Node: k, Path: OverridesKey, Msg: "must be a mapping of path: value"}
+			}
+			for j := 0; j+1 < len(v.Content); j += 2 {
+				pk, pv := v.Content[j], v.Content[j+1]
+				p, err := ParsePath(pk.Value)
+				if err != nil {
+					return &OverlayError{Node: pk, Path: pk.Value, Msg: err.Error()}
+				}
+				switch p[0] {
+				case "game_id", "game_name", BaseKey, OverridesKey:
+					return &OverlayError{Node: pk, Path: pk.Value, Msg: fmt.Sprintf("set %s at the top level of the variant", p[0])}
+				}
+				if msg := set(base, p, pv); msg != "" {
+					return &OverlayError{Node: pk, Path: pk.Value, Msg: msg}
+				}
+			}
+		default:
+			set(base, []any{k.Value}, v)
+		}
+	}
+	return nil
+}
+
+// set replaces the value at p under root with v. Every step but the last must exist; the
+// last may be a new key of a mapping.
+func set(root *yaml.Node, p []any, v *yaml.Node) string {
+	n := root
+	for i, e := range p {
+		last := i == len(p)-1
+		switch e := e.(type) {
+		case string:
+			if n.Kind != yaml.MappingNode {
+				return fmt.Sprintf("%s is not a mapping", prefix(p[:i]))
+			}
+			j := keyIndex(n, e)
+			switch {
+			case j < 0 && last:
+				n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: e}, v)
+				return ""
+			case j < 0:
+				return fmt.Sprintf("%s has no key %q in the base", prefix(p[:i]), e)
+			case last:
+				n.Content[j+1] = v
+				return ""
+			}
+			n = n.Content[j+1]
+		case int:
+			if n.Kind != yaml.SequenceNode {
+				return fmt.Sprintf("%s is not a list", prefix(p[:i]))
+			}
+			if e >= len(n.Content) {
+				return fmt.Sprintf("%s has %d items in the base, no index %d", prefix(p[:i]), len(n.Content), e)
+			}
+			if last {
+				n.Content[e] = v
+				return ""
+			}
+			n = n.Content[e]
+		}
+		if n.Kind == yaml.AliasNode && n.Alias != nil {
+			n = n.Alias
+		}
+	}
+	return ""
+}
+
+// keyIndex returns the index of key k in the mapping n, or -1.
+func keyIndex(n *yaml.Node, k string) int {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == k {
+			return i
+		}
+	}
+	return -1
+}
+
+// ParsePath parses an override path: mapping keys joined by dots, list indexes in
+// brackets, e.g. game_mode_settings[0].symbol_setting.pay_table[3].
+func ParsePath(s string) ([]any, error) {
+	var p []any
+	for _, part := range strings.Split(s, ".") {
+		key, rest, _ := strings.Cut(part, "[")
+		if key == "" && (len(p) == 0 || rest == "") {
+			return nil, fmt.Errorf("empty key in path %q", s)
+		}
+		if key != "" {
+			p = append(p, key)
+		}
+		for rest != "" {
+			idx, after, ok := strings.Cut(rest, "]")
+			i, err := strconv.Atoi(idx)
+			if !ok || err != nil || i < 0 {
+				return nil, fmt.Errorf("bad index [%s in path %q", rest, s)
+			}
+			p = append(p, i)
+			if after == "" {
+				break
+			}
+			if !strings.HasPrefix(after, "[") {
+				return nil, fmt.Errorf("bad path %q: expected [ or . after ]", s)
+			}
+			rest = after[1:]
+		}
+	}
+	return p, nil
+}
+
+// prefix formats the first steps of a path for a message.
+func prefix(p []any) string {
+	if len(p) == 0 {
+		return "the config"
+	}
+	var b strings.Builder
+	for _, e := range p {
+		switch e := e.(type) {
+		case string:
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(e)
+		case int:
+			fmt.Fprintf(&b, "[%d]", e)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,211 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"gopkg.in/yaml.v3"
+)
+
+const baseYAML = `game_id: 1
+game_name: alpha
+logic_key: demo_normal
+bet_units: [40]
+modes:
+  - reels:
+      - symbols: [1, 2, 3]
+        weights: [1, 1, 1]
+      - symbols: [4, 5, 6]
+`
+
+func TestParsePath(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []any
+	}{
+		{"bet_units", []any{"bet_units"}},
+		{"modes[0].reels[1].weights", []any{"modes", 0, "reels", 1, "weights"}},
+		{"grid[1][2]", []any{"grid", 1, 2}},
+	}
+	for _, c := range cases {
+		p, err := ParsePath(c.in)
+		if err != nil || !reflect.DeepEqual(p, c.want) {
+			t.Fatalf("ParsePath(%q) = %v, %v; want %v", c.in, p, err, c.want)
+		}
+	}
+	for _, in := range []string{"", "a..b", "a[x]", "a[1", "a[-1]", "a[0]b", "[0]"} {
+		if _, err := ParsePath(in); err == nil {
+			t.Fatalf("ParsePath(%q) accepted", in)
+		}
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	cases := []struct {
+		name    string
+		variant string
+		want    string // the resolved value at path, as JSON
+		path    string
+		err     string
+	}{
+		{"top level", "bet_units: [20]", "[20]", "bet_units", ""},
+		{"weights", "overrides:\n  modes[0].reels[0].weights: [3, 2, 1]", "[3,2,1]", "modes[0].reels[0].weights", ""},
+		{"new key", "overrides:\n  modes[0].reels[1].weights: [1, 1, 9]", "[1,1,9]", "modes[0].reels[1].weights", ""},
+		{"missing key", "overrides:\n  modes[0].strips[0]: [1]", "", "", `modes[0] has no key "strips" in the base`},
+		{"out of range", "overrides:\n  modes[0].reels[5].weights: [1]", "", "", "modes[0].reels has 2 items in the base, no index 5"},
+		{"not a list", "overrides:\n  bet_units[0][1]: 1", "", "", "bet_units[0] is not a list"},
+		{"game id", "overrides:\n  game_id: 3", "", "", "set game_id at the top level of the variant"},
+		{"overrides list", "overrides: [1]", "", "", "must be a mapping of path: value"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			base, _ := parseRoot([]byte(baseYAML))
+			variant, err := parseRoot([]byte("base: alpha.yaml\n" + c.variant))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = Overlay(base, variant)
+			if c.err != "" {
+				var oe *OverlayError
+				if err == nil || !strings.Contains(err.Error(), c.err) || !errors.As(err, &oe) || oe.Node.Line < 2 {
+					t.Fatalf("got %v, want %q at a variant line", err, c.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := valueAt(t, base, c.path); got != c.want {
+				t.Fatalf("%s = %s, want %s", c.path, got, c.want)
+			}
+		})
+	}
+}
+
+// valueAt returns the value at the override path p under root, as compact JSON.
+func valueAt(t *testing.T, root *yaml.Node, p string) string {
+	t.Helper()
+	var v any
+	if err := root.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	steps, err := ParsePath(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range steps {
+		switch s := s.(type) {
+		case string:
+			v = v.(map[string]any)[s]
+		case int:
+			v = v.([]any)[s]
+		}
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func TestMountVariants(t *testing.T) {
+	src := fstest.MapFS{
+		"alpha/alpha.yaml": {Data: []byte(baseYAML)},
+		"alpha/alpha_94.yaml": {Data: []byte(`base: alpha.yaml
+game_id: 2
+game_name: alpha_94
+overrides:
+  modes[0].reels[0].weights: [3, 2, 1]
+`)},
+		"alpha/alpha_94_cheap.json": {Data: []byte(`{"base": "alpha_94.yaml", "game_id": 3, "game_name": "alpha_94_cheap", "bet_units": [20]}`)},
+	}
+	m, err := Mount(Source{Name: "embedded", FS: src})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fstest.TestFS(m, "alpha.yaml", "alpha_94.yaml", "alpha_94_cheap.json"); err != nil {
+		t.Fatal(err)
+	}
+	lineage, _ := m.Lineage("alpha_94_cheap.json")
+	want := []string{"embedded:alpha/alpha_94_cheap.json", "embedded:alpha/alpha_94.yaml", "embedded:alpha/alpha.yaml"}
+	if !slices.Equal(lineage, want) {
+		t.Fatalf("lineage %v, want %v", lineage, want)
+	}
+	if lineage, _ := m.Lineage("alpha.yaml"); !slices.Equal(lineage, []string{"embedded:alpha/alpha.yaml"}) {
+		t.Fatalf("base lineage %v", lineage)
+	}
+
+	raw, err := fs.ReadFile(m, "alpha_94_cheap.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		GameID   int    `json:"game_id"`
+		GameName string `json:"game_name"`
+		Logic    string `json:"logic_key"`
+		BetUnits []int  `json:"bet_units"`
+		Modes    []struct {
+			Reels []struct {
+				Weights []int `json:"weights"`
+			} `json:"reels"`
+		} `json:"modes"`
+		Base *string `json:"base"`
+	}
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatalf("resolved json variant: %v\n%s", err, raw)
+	}
+	if got.GameID != 3 || got.GameName != "alpha_94_cheap" || got.Logic != "demo_normal" || got.Base != nil ||
+		!slices.Equal(got.BetUnits, []int{20}) || !slices.Equal(got.Modes[0].Reels[0].Weights, []int{3, 2, 1}) {
+		t.Fatalf("resolved %s", raw)
+	}
+	raw, _ = fs.ReadFile(m, "alpha.yaml")
+	if string(raw) != baseYAML {
+		t.Fatalf("base changed by its variants:\n%s", raw)
+	}
+}
+
+func TestMountVariantErrors(t *testing.T) {
+	variant := func(id int, base string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(fmt.Sprintf("base: %s\ngame_id: %d\ngame_name: v%d\n", base, id, id))}
+	}
+	cases := []struct {
+		name string
+		fsys fstest.MapFS
+		want string
+	}{
+		{"cycle", fstest.MapFS{"a.yaml": variant(1, "b.yaml"), "b.yaml": variant(2, "a.yaml")},
+			"variant cycle: a.yaml -> b.yaml -> a.yaml"},
+		{"missing base", fstest.MapFS{"a.yaml": variant(1, "gone.yaml")},
+			`embedded:a.yaml: base "gone.yaml" is not a mounted config`},
+		{"no game name", fstest.MapFS{"alpha.yaml": {Data: []byte(baseYAML)}, "v.yaml": {Data: []byte("base: alpha.yaml\ngame_id: 2\n")}},
+			"embedded:v.yaml: a variant must set its own game_id and game_name"},
+		{"bad override", fstest.MapFS{"alpha.yaml": {Data: []byte(baseYAML)}, "v.yaml": {Data: []byte("base: alpha.yaml\ngame_id: 2\ngame_name: v\noverrides:\n  reels[0]: [1]\n")}},
+			`embedded:v.yaml: line 5: override reels[0]: the config has no key "reels" in the base`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := Mount(Source{Name: "embedded", FS: c.fsys})
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Fatalf("got %v, want %q", err, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,504 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package exact computes the statistics of one screen of a line game exactly, by
+// enumerating reel stops instead of sampling.
+//
+// It covers game modes whose screens are drawn with `GenReelByReelIdx` and paid with
+// a `line_*` bet type, evaluated with the same rules as the upstream line calculator
+// (wild prefix against the first paying symbol, the larger of the two wins). What a
+// logic does around the screen (triggers, multipliers, cascades) is not modelled: the
+// results are those of one generated and paid screen.
+package exact
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/zintix-labs/problab/spec"
+)
+
+// Result is the exact outcome of one screen of a game mode. Wins are in credits at bet
+// multiplier 1, like the pay table.
+type Result struct {
+	Columns      int         `json:"columns"       yaml:"columns"`
+	Rows         int         `json:"rows"          yaml:"rows"`
+	Lines        int         `json:"lines"         yaml:"lines"`
+	BetType      string      `json:"bet_type"      yaml:"bet_type"`
+	ReelSets     int         `json:"reel_sets"     yaml:"reel_sets"`
+	Combinations float64     `json:"combinations"  yaml:"combinations"` // weighted reel stop combinations of each reel set, summed
+	Win          float64     `json:"win"           yaml:"win"`          // expected win per screen
+	WinSD        float64     `json:"win_sd"        yaml:"win_sd"`       // standard deviation of the win per screen
+	HitRate      float64     `json:"hit_rate"      yaml:"hit_rate"`     // P(win > 0)
+	MaxWin       int         `json:"max_win"       yaml:"max_win"`      // largest win of a reachable screen
+	Symbols      []SymbolPay `json:"symbols"   yaml:"symbols"`
+	LinePays     []LinePay   `json:"line_pays" yaml:"line_pays"`
+	Scatters     []Scatter   `json:"scatters,omitempty" yaml:"scatters,omitempty"`
+}
+
+// SymbolPay is the share of one pay table entry: a symbol paid for a run length.
+type SymbolPay struct {
+	Symbol string  `json:"symbol" yaml:"symbol"`
+	ID     int     `json:"id"     yaml:"id"`
+	Count  int     `json:"count"  yaml:"count"` // run length
+	Pay    int     `json:"pay"    yaml:"pay"`
+	Hits   float64 `json:"hits"   yaml:"hits"` // expected line wins of this entry per screen
+	Win    float64 `json:"win"    yaml:"win"`  // expected win of this entry per screen
+}
+
+// LinePay is the share of one line and direction.
+type LinePay struct {
+	Line      int     `json:"line"      yaml:"line"`
+	Direction string  `json:"direction" yaml:"direction"` // ltr or rtl
+	HitRate   float64 `json:"hit_rate"  yaml:"hit_rate"`
+	Win       float64 `json:"win"       yaml:"win"`
+}
+
+// Scatter is the distribution of the number of one scatter symbol on a screen.
+type Scatter struct {
+	Symbol string    `json:"symbol" yaml:"symbol"`
+	ID     int       `json:"id"     yaml:"id"`
+	P      []float64 `json:"p"      yaml:"p"` // P[k]: probability of exactly k on the screen
+}
+
+// Analyze returns the exact statistics of one screen of gms.
+func Analyze(gms *spec.GameModeSetting) (*Result, error) {
+	g, err := newGame(gms)
+	if err != nil {
+		return nil, err
+	}
+	res := &Result{
+		Columns:  g.cols,
+		Rows:     g.rows,
+		Lines:    len(g.lines),
+		BetType:  gms.HitSetting.BetTypeStr,
+		ReelSets: len(gms.GenScreenSetting.ReelSetGroup),
+	}
+	pays := make(map[[2]int]*SymbolPay)
+	linePays := make([]LinePay, len(g.runs))
+	for i, r := range g.runs {
+		linePays[i] = LinePay{Line: r.line, Direction: directions[r.dir]}
+	}
+	total := 0
+	for _, rs := range gms.GenScreenSetting.ReelSetGroup {
+		total += rs.Weight
+	}
+	for id, st := range gms.SymbolSetting.SymbolTypes {
+		if st == spec.SymbolTypeScatter {
+			res.Scatters = append(res.Scatters, Scatter{Symbol: g.symbols[id], ID: id, P: make([]float64, g.cols*g.rows+1)})
+		}
+	}
+	var m1, m2 float64
+	for _, rs := range gms.GenScreenSetting.ReelSetGroup {
+		if rs.Weight == 0 {
+			continue
+		}
+		w := float64(rs.Weight) / float64(total)
+		set, combos := g.reelSet(rs.Reels)
+		res.Combinations += combos
+
+		for i := range g.runs {
+			g.walkLine(set, i, w, pays, &linePays[i])
+		}
+		for i := range res.Scatters {
+			for k, p := range g.count(set, int16(res.Scatters[i].ID)) {
+				res.Scatters[i].P[k] += w * p
+			}
+		}
+		s := g.screen(set)
+		res.HitRate += w * (1 - s.miss)
+		res.MaxWin = max(res.MaxWin, s.max)
+		m1 += w * s.m1
+		m2 += w * s.m2
+	}
+	res.Win = m1
+	res.WinSD = math.Sqrt(max(m2-m1*m1, 0))
+	for _, p := range pays {
+		res.Symbols = append(res.Symbols, *p)
+	}
+	slices.SortFunc(res.Symbols, func(a, b SymbolPay) int {
+		if a.ID != b.ID {
+			return a.ID - b.ID
+		}
+		return a.Count - b.Count
+	})
+	res.LinePays = linePays
+	return res, nil
+}
+
+var directions = [2]string{"ltr", "rtl"}
+
+// game is a game mode prepared for enumeration.
+type game struct {
+	cols, rows int
+	lines      [][]int16 // row of each column
+	runs       []lineRun // every (line, direction) that is paid
+	order      []int     // columns in screen enumeration order
+	symbols    []string
+	pay        [][]int
+	wild, paid []bool
+}
+
+// lineRun is one line read in one direction.
+type lineRun struct {
+	line int
+	dir  int // 0: ltr, 1: rtl
+}
+
+// column is the column of the line cell at position pos of run r.
+func (g *game) column(r lineRun, pos int) int {
+	if r.dir == 1 {
+		return g.cols - 1 - pos
+	}
+	return pos
+}
+
+func newGame(gms *spec.GameModeSetting) (*game, error) {
+	if err := errors.Join(gms.ScreenSetting.Init(), gms.GenScreenSetting.Init(), gms.SymbolSetting.Init(), gms.HitSetting.Init()); err != nil {
+		return nil, err
+	}
+	if gms.GenScreenSetting.GenReelType != spec.GenReelByReelIdx {
+		return nil, fmt.Errorf("gen_reel_type %s is not supported, only GenReelByReelIdx", gms.GenScreenSetting.GenReelTypeStr)
+	}
+	bt := gms.HitSetting.BetType
+	if !spec.IsBetTypeLine(bt) {
+		return nil, fmt.Errorf("bet_type %s is not supported, only line_*", gms.HitSetting.BetTypeStr)
+	}
+	ss := &gms.SymbolSetting
+	g := &game{
+		cols:    gms.ScreenSetting.Columns,
+		rows:    gms.ScreenSetting.Rows,
+		lines:   gms.HitSetting.LineTable,
+		symbols: ss.SymbolUsedStr,
+		pay:     ss.PayTable,
+		wild:    make([]bool, ss.SymbolCount),
+		paid:    make([]bool, ss.SymbolCount),
+	}
+	if g.cols < 1 || g.rows < 1 {
+		return nil, errors.New("screen must have at least one column and one row")
+	}
+	if ss.SymbolCount > 64 {
+		return nil, errors.New("more than 64 symbols")
+	}
+	for i, st := range ss.SymbolTypes {
+		g.wild[i] = st == spec.SymbolTypeWild
+		g.paid[i] = slices.ContainsFunc(ss.PayTable[i], func(v int) bool { return v > 0 })
+	}
+	for i, line := range g.lines {
+		if len(line) != g.cols {
+			return nil, fmt.Errorf("line %d has %d cells, want %d", i, len(line), g.cols)
+		}
+		for _, r := range line {
+			if r < 0 || int(r) >= g.rows {
+				return nil, fmt.Errorf("line %d has row %d outside the screen", i, r)
+			}
+		}
+		if spec.IsLeftToRight(bt) {
+			g.runs = append(g.runs, lineRun{line: i})
+		}
+		if spec.IsRightToLeft(bt) {
+			g.runs = append(g.runs, lineRun{line: i, dir: 1})
+		}
+	}
+	// Columns are placed from the end(s) lines are read from, so every run reads its cells
+	// in order as the screen is filled.
+	switch {
+	case !spec.IsRightToLeft(bt):
+		for c := range g.cols {
+			g.order = append(g.order, c)
+		}
+	case !spec.IsLeftToRight(bt):
+		for c := g.cols - 1; c >= 0; c-- {
+			g.order = append(g.order, c)
+		}
+	default:
+		for lo, hi := 0, g.cols-1; lo <= hi; lo, hi = lo+1, hi-1 {
+			g.order = append(g.order, lo)
+			if hi != lo {
+				g.order = append(g.order, hi)
+			}
+		}
+	}
+	for _, rs := range gms.GenScreenSetting.ReelSetGroup {
+		if len(rs.Reels) < g.cols {
+			return nil, fmt.Errorf("reel set has %d reels, want %d", len(rs.Reels), g.cols)
+		}
+		for _, reel := range rs.Reels {
+			for _, s := range reel.ReelSymbols {
+				if s < 0 || int(s) >= ss.SymbolCount {
+					return nil, fmt.Errorf("reel symbol %d is not in symbol_used", s)
+				}
+			}
+		}
+	}
+	return g, nil
+}
+
+// run is the state of one line being read, mirroring the upstream line calculator.
+type run struct {
+	pos     int8
+	done    bool
+	first   int16
+	norm    int16 // first paying non-wild symbol; -1 until seen
+	wildRun int8
+	normRun int8 // includes the wild prefix
+}
+
+func newRun() run { return run{norm: -1} }
+
+// step reads the next cell of the line.
+func (g *game) step(r *run, s int16) {
+	pos := r.pos
+	r.pos++
+	if pos == 0 {
+		r.first = s
+		switch {
+		case g.wild[s]:
+			r.wildRun = 1
+		case g.paid[s]:
+			r.norm, r.normRun = s, 1
+		default:
+			r.done = true
+			return
+		}
+	} else {
+		switch wild := g.wild[s]; {
+		case r.norm < 0 && int(r.wildRun) == int(pos) && wild:
+			r.wildRun++
+		case r.norm < 0 && !wild:
+			if !g.paid[s] {
+				r.done = true
+				return
+			}
+			r.norm, r.normRun = s, r.wildRun+1
+		case r.norm >= 0:
+			if s != r.norm && !wild {
+				r.done = true
+				return
+			}
+			r.normRun++
+		}
+	}
+	if int(r.pos) == g.cols {
+		r.done = true
+	}
+}
+
+// win returns the paid symbol, run length and win of a finished run; sym is -1 when the
+// line does not pay.
+func (g *game) win(r *run) (sym int16, count int, win int) {
+	wildWin, normWin := 0, 0
+	if r.wildRun > 0 {
+		wildWin = g.pay[r.first][r.wildRun-1]
+	}
+	if r.normRun > 0 {
+		normWin = g.pay[r.norm][r.normRun-1]
+	}
+	if wildWin > normWin {
+		return r.first, int(r.wildRun), wildWin
+	}
+	if normWin > 0 {
+		return r.norm, int(r.normRun), normWin
+	}
+	return -1, 0, 0
+}
+
+// reelSet is one reel set as distributions: for each column, the symbol probabilities
+// of every row and the distinct windows with their probabilities.
+type reelSet struct {
+	cells   [][][]float64 // [col][row][symbol]
+	windows [][]window    // [col]
+}
+
+type window struct {
+	syms []int16 // by row
+	p    float64
+}
+
+func (g *game) reelSet(reels []spec.Reel) (*reelSet, float64) {
+	set := &reelSet{cells: make([][][]float64, g.cols), windows: make([][]window, g.cols)}
+	combos := 1.0
+	for c := range g.cols {
+		reel := &reels[c]
+		total := 0
+		for _, w := range reel.ReelWeights {
+			total += w
+		}
+		combos *= float64(total)
+		set.cells[c] = make([][]float64, g.rows)
+		for r := range g.rows {
+			set.cells[c][r] = make([]float64, len(g.symbols))
+		}
+		seen := make(map[string]int)
+		for id, w := range reel.ReelWeights {
+			if w == 0 {
+				continue
+			}
+			p := float64(w) / float64(total)
+			syms := make([]int16, g.rows)
+			for r := range g.rows {
+				syms[r] = reel.ReelSymbols[(id+r)%len(reel.ReelSymbols)]
+				set.cells[c][r][syms[r]] += p
+			}
+			key := fmt.Sprint(syms)
+			if i, ok := seen[key]; ok {
+				set.windows[c][i].p += p
+				continue
+			}
+			seen[key] = len(set.windows[c])
+			set.windows[c] = append(set.windows[c], window{syms: syms, p: p})
+		}
+	}
+	return set, combos
+}
+
+// walkLine adds the pays of run i, weighted by w, by enumerating the symbols along the
+// line until the run ends. The cells of a line are in distinct columns, so they are
+// independent given the reel set.
+func (g *game) walkLine(set *reelSet, i int, w float64, pays map[[2]int]*SymbolPay, lp *LinePay) {
+	lr := g.runs[i]
+	var walk func(r run, p float64)
+	walk = func(r run, p float64) {
+		if r.done {
+			sym, count, win := g.win(&r)
+			if win == 0 {
+				return
+			}
+			lp.HitRate += p
+			lp.Win += p * float64(win)
+			k := [2]int{int(sym), count}
+			sp := pays[k]
+			if sp == nil {
+				sp = &SymbolPay{Symbol: g.symbols[sym], ID: int(sym), Count: count, Pay: win}
+				pays[k] = sp
+			}
+			sp.Hits += p
+			sp.Win += p * float64(win)
+			return
+		}
+		c := g.column(lr, int(r.pos))
+		for s, q := range set.cells[c][g.lines[lr.line][c]] {
+			if q == 0 {
+				continue
+			}
+			next := r
+			g.step(&next, int16(s))
+			walk(next, p*q)
+		}
+	}
+	walk(newRun(), w)
+}
+
+// count returns the distribution of the number of sym on a screen: the windows of the
+// columns are independent, so the per-column counts convolve.
+func (g *game) count(set *reelSet, sym int16) []float64 {
+	dist := []float64{1}
+	for c := range g.cols {
+		col := make([]float64, g.rows+1)
+		for _, win := range set.windows[c] {
+			n := 0
+			for _, s := range win.syms {
+				if s == sym {
+					n++
+				}
+			}
+			col[n] += win.p
+		}
+		next := make([]float64, len(dist)+g.rows)
+		for i, p := range dist {
+			for j, q := range col {
+				next[i+j] += p * q
+			}
+		}
+		dist = next
+	}
+	return dist
+}
+
+// outcome is what is left to win once some columns are placed: the probability of no
+// further line win, the first two moments of the further win and its maximum.
+type outcome struct {
+	miss, m1, m2 float64
+	max          int
+}
+
+// screen enumerates whole screens column by column. The rest of a screen only depends
+// on the state of every run, so equal states are solved once per column.
+func (g *game) screen(set *reelSet) outcome {
+	placed := make([][]int16, g.cols)
+	memo := make([]map[string]outcome, len(g.order))
+	for i := range memo {
+		memo[i] = make(map[string]outcome)
+	}
+	key := make([]byte, 0, 4*len(g.runs))
+	var solve func(depth int, runs []run) outcome
+	solve = func(depth int, runs []run) outcome {
+		if depth == len(g.order) {
+			return outcome{miss: 1}
+		}
+		key = key[:0]
+		for _, r := range runs {
+			if r.done {
+				key = append(key, 0, 0, 0, 0)
+				continue
+			}
+			key = append(key, byte(r.pos)+1, byte(r.first), byte(r.norm+1), byte(r.wildRun)<<4|byte(r.normRun))
+		}
+		k := string(key)
+		if o, ok := memo[depth][k]; ok {
+			return o
+		}
+		c := g.order[depth]
+		var o outcome
+		next := make([]run, len(runs))
+		for _, win := range set.windows[c] {
+			placed[c] = win.syms
+			copy(next, runs)
+			x := 0
+			for i := range next {
+				r := &next[i]
+				lr := g.runs[i]
+				for !r.done {
+					col := g.column(lr, int(r.pos))
+					if placed[col] == nil {
+						break
+					}
+					g.step(r, placed[col][g.lines[lr.line][col]])
+					if r.done {
+						_, _, w := g.win(r)
+						x += w
+					}
+				}
+			}
+			sub := solve(depth+1, next)
+			fx := float64(x)
+			if x == 0 {
+				o.miss += win.p * sub.miss
+			}
+			o.m1 += win.p * (fx + sub.m1)
+			o.m2 += win.p * (fx*fx + 2*fx*sub.m1 + sub.m2)
+			o.max = max(o.max, x+sub.max)
+		}
+		placed[c] = nil
+		memo[depth][k] = o
+		return o
+	}
+	runs := make([]run, len(g.runs))
+	for i := range runs {
+		runs[i] = newRun()
+	}
+	return solve(0, runs)
+}
@@ -0,0 +1,245 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exact
+
+import (
+	"io/fs"
+	"math"
+	"testing"
+
+	"github.com/zintix-labs/problab-scaffold/internal/configs"
+	"github.com/zintix-labs/problab/sdk/buf"
+	"github.com/zintix-labs/problab/sdk/calc"
+	"github.com/zintix-labs/problab/spec"
+	"gopkg.in/yaml.v3"
+)
+
+// small is a 3x2 game with wilds, a scatter, zero-pay entries that only a wild prefix
+// beats, weighted stops and two reel sets, small enough to enumerate every screen.
+const small = `
+screen_setting: {columns: 3, rows: 2, damp: 1}
+gen_screen_setting:
+  gen_reel_type: GenReelByReelIdx
+  reel_set_group:
+    - weight: 3
+      reels:
+        - {symbols: [2, 3, 4, 1, 4, 0], weights: [1, 2, 1, 1, 3, 1]}
+        - {symbols: [4, 2, 3, 3, 0, 4, 2]}
+        - {symbols: [3, 4, 4, 2, 1]}
+    - weight: 1
+      reels:
+        - {symbols: [2, 2, 3, 4]}
+        - {symbols: [3, 2, 4], weights: [2, 1, 0]}
+        - {symbols: [4, 4, 3, 2, 0]}
+symbol_setting:
+  symbol_used: [Z1, C1, W1, H1, L1]
+  pay_table:
+    - [0, 0, 0]
+    - [0, 0, 0]
+    - [0, 5, 50]
+    - [0, 0, 20]
+    - [0, 2, 8]
+hit_setting:
+  bet_type: line_ltr
+  line_table:
+    - [0, 0, 0]
+    - [1, 1, 1]
+    - [0, 1, 0]
+    - [1, 0, 1]
+`
+
+// bruteForce pays every screen of gms with the upstream calculator.
+func bruteForce(t *testing.T, gms *spec.GameModeSetting) *Result {
+	t.Helper()
+	sc := calc.NewScreenCalculator(gms)
+	gmr := buf.NewGameModeResult(0, gms, 64, 64)
+	cols, rows := gms.ScreenSetting.Columns, gms.ScreenSetting.Rows
+	res := &Result{}
+	lines := make(map[[2]int]*LinePay)
+	pays := make(map[[2]int]*SymbolPay)
+	var m2 float64
+	total := 0
+	for _, rs := range gms.GenScreenSetting.ReelSetGroup {
+		total += rs.Weight
+	}
+	screen := make([]int16, cols*rows)
+	res.Scatters = []Scatter{{ID: 1, P: make([]float64, cols*rows+1)}}
+	for _, rs := range gms.GenScreenSetting.ReelSetGroup {
+		stops := make([]int, cols)
+		var walk func(col int, p float64)
+		walk = func(col int, p float64) {
+			if col < cols {
+				reel := rs.Reels[col]
+				sum := 0
+				for _, w := range reel.ReelWeights {
+					sum += w
+				}
+				for id, w := range reel.ReelWeights {
+					if w == 0 {
+						continue // unreachable stop: no max win from it
+					}
+					stops[col] = id
+					walk(col+1, p*float64(w)/float64(sum))
+				}
+				return
+			}
+			for c, id := range stops {
+				reel := rs.Reels[c]
+				for r := range rows {
+					screen[r*cols+c] = reel.ReelSymbols[(id+r)%len(reel.ReelSymbols)]
+				}
+			}
+			gmr.Reset()
+			sc.CalcScreen(1, screen, gmr)
+			win := float64(gmr.GetTmpWin())
+			res.Win += p * win
+			m2 += p * win * win
+			if win > 0 {
+				res.HitRate += p
+			}
+			res.MaxWin = max(res.MaxWin, gmr.GetTmpWin())
+			n := 0
+			for _, s := range screen {
+				if s == 1 {
+					n++
+				}
+			}
+			res.Scatters[0].P[n] += p
+			for _, d := range gmr.GetDetails() {
+				lp := lines[[2]int{d.LineID, int(d.Direction)}]
+				if lp == nil {
+					lp = &LinePay{}
+					lines[[2]int{d.LineID, int(d.Direction)}] = lp
+				}
+				lp.HitRate += p
+				lp.Win += p * float64(d.Win)
+				sp := pays[[2]int{int(d.SymbolID), d.Count}]
+				if sp == nil {
+					sp = &SymbolPay{}
+					pays[[2]int{int(d.SymbolID), d.Count}] = sp
+				}
+				sp.Hits += p
+				sp.Win += p * float64(d.Win)
+			}
+		}
+		walk(0, float64(rs.Weight)/float64(total))
+	}
+	res.WinSD = math.Sqrt(m2 - res.Win*res.Win)
+	for k, lp := range lines {
+		res.LinePays = append(res.LinePays, LinePay{Line: k[0], Direction: directions[k[1]], HitRate: lp.HitRate, Win: lp.Win})
+	}
+	for k, sp := range pays {
+		res.Symbols = append(res.Symbols, SymbolPay{ID: k[0], Count: k[1], Hits: sp.Hits, Win: sp.Win})
+	}
+	return res
+}
+
+func near(a, b float64) bool { return math.Abs(a-b) <= 1e-12*max(1, math.Abs(b)) }
+
+func TestAnalyzeMatchesUpstreamCalculator(t *testing.T) {
+	for _, bt := range []string{"line_ltr", "line_rtl", "line_both"} {
+		t.Run(bt, func(t *testing.T) {
+			gms := new(spec.GameModeSetting)
+			if err := yaml.Unmarshal([]byte(small), gms); err != nil {
+				t.Fatal(err)
+			}
+			gms.HitSetting.BetTypeStr = bt
+			got, err := Analyze(gms)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := bruteForce(t, gms)
+			if !near(got.Win, want.Win) || !near(got.WinSD, want.WinSD) || !near(got.HitRate, want.HitRate) {
+				t.Fatalf("win %v sd %v hit %v, want %v %v %v", got.Win, got.WinSD, got.HitRate, want.Win, want.WinSD, want.HitRate)
+			}
+			if got.MaxWin != want.MaxWin || len(got.Scatters) != 1 {
+				t.Fatalf("max win %d, scatters %+v; want %d", got.MaxWin, got.Scatters, want.MaxWin)
+			}
+			for k, p := range want.Scatters[0].P {
+				if !near(got.Scatters[0].P[k], p) {
+					t.Fatalf("P(%d scatters) = %v, want %v", k, got.Scatters[0].P[k], p)
+				}
+			}
+			if want.Win == 0 || want.HitRate == 1 {
+				t.Fatalf("degenerate fixture: win %v hit %v", want.Win, want.HitRate)
+			}
+			for _, w := range want.LinePays {
+				found := false
+				for _, g := range got.LinePays {
+					if g.Line == w.Line && g.Direction == w.Direction {
+						found = true
+						if !near(g.HitRate, w.HitRate) || !near(g.Win, w.Win) {
+							t.Errorf("line %d %s: %+v, want %+v", w.Line, w.Direction, g, w)
+						}
+					}
+				}
+				if !found {
+					t.Errorf("line %d %s missing", w.Line, w.Direction)
+				}
+			}
+			if len(got.Symbols) != len(want.Symbols) {
+				t.Fatalf("%d symbol pays, want %d", len(got.Symbols), len(want.Symbols))
+			}
+			for _, w := range want.Symbols {
+				for _, g := range got.Symbols {
+					if g.ID == w.ID && g.Count == w.Count && (!near(g.Hits, w.Hits) || !near(g.Win, w.Win)) {
+						t.Errorf("symbol %d x%d: %+v, want %+v", w.ID, w.Count, g, w)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzeDemoNormal(t *testing.T) {
+	raw, err := fs.ReadFile(configs.FS, "zintix/demo_0/demo_0.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs, err := spec.GetGameSettingByYAML(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for mode := range gs.GameModeSettings {
+		res, err := Analyze(&gs.GameModeSettings[mode])
+		if err != nil {
+			t.Fatalf("mode %d: %v", mode, err)
+		}
+		var lines, symbols float64
+		for _, lp := range res.LinePays {
+			lines += lp.Win
+		}
+		for _, sp := range res.Symbols {
+			symbols += sp.Win
+		}
+		if !near(lines, res.Win) || !near(symbols, res.Win) {
+			t.Fatalf("mode %d: win %v, lines %v, symbols %v", mode, res.Win, lines, symbols)
+		}
+		if res.HitRate <= 0 || res.HitRate >= 1 || res.WinSD <= 0 {
+			t.Fatalf("mode %d: %+v", mode, res)
+		}
+	}
+
+	cascade, err := fs.ReadFile(configs.FS, "zintix/demo_1/demo_1.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gs, err = spec.GetGameSettingByYAML(cascade); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Analyze(&gs.GameModeSettings[0]); err == nil {
+		t.Fatal("cluster game mode was analyzed")
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"maps"
+	"reflect"
+
+	"github.com/zintix-labs/problab-scaffold/internal/configs"
+	"github.com/zintix-labs/problab-scaffold/internal/logic"
+	"github.com/zintix-labs/problab/spec"
+)
+
+// SchemaDialect is the JSON Schema version of JSONSchema.
+const SchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// keywords are the JSON Schema keywords added to the reflected type of a key, by path;
+// "[]" stands for any item of a list.
+var keywords = map[string]map[string]any{
+	"game_id":            {"description": "unique id of the game in the catalog"},
+	"game_name":          {"description": "name shown by reports and the catalog"},
+	"logic_key":          {"description": "key of a logic registered in internal/logic"},
+	"bet_units":          {"description": "bet of each bet mode, in credits", "minItems": 1},
+	"bet_units[]":        {"minimum": 1},
+	"max_win_limit":      {"description": "largest total win of a spin, in credits", "minimum": 1},
+	"game_mode_settings": {"description": "game modes; 0 is the base game", "minItems": 1},
+	"fixed":              {"description": "logic-specific settings, decoded by the logic of logic_key", "type": optional("object")},
+
+	"game_mode_settings[].screen_setting.columns": {"description": "reels of the screen", "minimum": 1},
+	"game_mode_settings[].screen_setting.rows":    {"description": "visible rows of the screen", "minimum": 1},
+	"game_mode_settings[].screen_setting.damp":    {"description": "extra symbols above and below the screen", "minimum": 0},
+	"game_mode_settings[].screen_setting.mask":    {"description": "1 for a cell and 0 for no cell, columns x rows entries; omit for a full screen", "type": optional("array")},
+	"game_mode_settings[].screen_setting.mask[]":  {"maximum": 1},
+
+	"game_mode_settings[].gen_screen_setting.gen_reel_type":                      {"description": "how screens are drawn from the reels"},
+	"game_mode_settings[].gen_screen_setting.reel_set_group":                     {"description": "reel sets, drawn by weight", "minItems": 1},
+	"game_mode_settings[].gen_screen_setting.reel_set_group[].weight":            {"minimum": 0},
+	"game_mode_settings[].gen_screen_setting.reel_set_group[].reels":             {"description": "one reel strip per column", "minItems": 1},
+	"game_mode_settings[].gen_screen_setting.reel_set_group[].reels[].symbols":   {"description": "symbol ids of the stops: indexes into symbol_used", "minItems": 1},
+	"game_mode_settings[].gen_screen_setting.reel_set_group[].reels[].symbols[]": {"minimum": 0},
+	"game_mode_settings[].gen_screen_setting.reel_set_group[].reels[].weights":   {"description": "weight of each stop; omit to weigh every stop 1", "type": optional("array")},
+	"game_mode_settings[].gen_screen_setting.reel_set_group[].reels[].weights[]": {"minimum": 0},
+
+	"game_mode_settings[].symbol_setting.symbol_used":   {"description": "symbols of the mode; a symbol id is its index here", "minItems": 1, "maxItems": 64, "uniqueItems": true},
+	"game_mode_settings[].symbol_setting.pay_table":     {"description": "one row per symbol of symbol_used; entry k-1 pays k symbols"},
+	"game_mode_settings[].symbol_setting.pay_table[][]": {"minimum": 0},
+
+	"game_mode_settings[].hit_setting.bet_type":       {"description": "how wins are evaluated"},
+	"game_mode_settings[].hit_setting.line_table":     {"description": "paylines of line bet types: the row of each column, 0 = top", "type": optional("array")},
+	"game_mode_settings[].hit_setting.line_table[][]": {"minimum": 0},
+}
+
+// optional is the type of a key that may be left empty (null in YAML).
+func optional(typ string) []string { return []string{typ, "null"} }
+
+// JSONSchema returns the JSON Schema of a game config. Each logic that registered a
+// `fixed:` decoder adds the schema of its block for configs with its logic_key; a
+// decoder that implements logic.FixedSchema adds its keywords to it.
+//
+// A variant (configs.VariantBase) needs only base, game_id and game_name; the keys it
+// sets are checked as in any config, the paths of its overrides are not.
+func JSONSchema() map[string]any {
+	s := typeSchema(reflect.TypeFor[spec.GameSetting](), "")
+	s["$schema"] = SchemaDialect
+	s["title"] = "problab game config"
+
+	props := s["properties"].(map[string]any)
+	props[configs.BaseKey] = map[string]any{"type": "string", "description": "file name of the base config of a variant"}
+	props[configs.OverridesKey] = map[string]any{
+		"type":        "object",
+		"description": "values of a variant replacing those of its base, by path, e.g. game_mode_settings[0].hit_setting.line_table",
+		"propertyNames": map[string]any{
+			"pattern": `^[A-Za-z_][A-Za-z0-9_]*(\[[0-9]+\])*(\.[A-Za-z_][A-Za-z0-9_]*(\[[0-9]+\])*)*$`,
+			"not":     map[string]any{"enum": []string{"game_id", "game_name", configs.BaseKey, configs.OverridesKey}},
+		},
+	}
+	s["dependentRequired"] = map[string][]string{configs.OverridesKey: {configs.BaseKey}}
+	conds := []any{map[string]any{
+		"if":   map[string]any{"required": []string{configs.BaseKey}},
+		"then": map[string]any{"required": []string{configs.BaseKey, "game_id", "game_name"}},
+		"else": map[string]any{"required": []string{"game_id", "logic_key", "bet_units", "max_win_limit", "game_mode_settings"}},
+	}}
+	props["logic_key"].(map[string]any)["examples"] = keyStrings(logic.FixedKeys())
+	mode := props["game_mode_settings"].(map[string]any)["items"].(map[string]any)
+	mode["required"] = []string{"screen_setting", "gen_screen_setting", "symbol_setting", "hit_setting"}
+	modeProps := mode["properties"].(map[string]any)
+	prop(modeProps, "gen_screen_setting", "gen_reel_type")["enum"] = genReelTypes
+	prop(modeProps, "symbol_setting", "symbol_used")["items"].(map[string]any)["enum"] = symbolNames
+	prop(modeProps, "hit_setting", "bet_type")["enum"] = betTypes
+
+	for _, key := range logic.FixedKeys() {
+		dec, _ := logic.Fixed(key)
+		fixed := typeSchema(reflect.TypeOf(dec).Elem(), "fixed")
+		if fs, ok := dec.(logic.FixedSchema); ok {
+			fprops := fixed["properties"].(map[string]any)
+			for k, kw := range fs.FixedSchema() {
+				if p, ok := fprops[k].(map[string]any); ok {
+					maps.Copy(p, kw)
+				}
+			}
+		}
+		conds = append(conds, map[string]any{
+			"if":   map[string]any{"properties": map[string]any{"logic_key": map[string]any{"const": string(key)}}, "required": []string{"logic_key"}},
+			"then": map[string]any{"properties": map[string]any{"fixed": fixed}},
+		})
+	}
+	s["allOf"] = conds
+	return s
+}
+
+// typeSchema reflects the schema of type t at path p (see keywords).
+func typeSchema(t reflect.Type, p string) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	s := make(map[string]any)
+	switch t.Kind() {
+	case reflect.Struct:
+		props := make(map[string]any)
+		for _, k := range keys(t) {
+			sf, _ := field(t, k)
+			kp := k
+			if p != "" {
+				kp = p + "." + k
+			}
+			props[k] = typeSchema(sf.Type, kp)
+		}
+		s["type"], s["properties"], s["additionalProperties"] = "object", props, false
+	case reflect.Slice, reflect.Array:
+		s["type"], s["items"] = "array", typeSchema(t.Elem(), p+"[]")
+	case reflect.Map:
+		s["type"] = "object"
+	case reflect.Bool:
+		s["type"] = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s["type"] = "integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s["type"], s["minimum"] = "integer", 0
+	case reflect.Float32, reflect.Float64:
+		s["type"] = "number"
+	case reflect.String:
+		s["type"] = "string"
+	}
+	maps.Copy(s, keywords[p])
+	return s
+}
+
+// prop returns the schema of the nested keys ks under props.
+func prop(props map[string]any, ks ...string) map[string]any {
+	var s map[string]any
+	for _, k := range ks {
+		s = props[k].(map[string]any)
+		props, _ = s["properties"].(map[string]any)
+	}
+	return s
+}
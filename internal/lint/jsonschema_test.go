@@ -0,0 +1,246 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zintix-labs/problab-scaffold/internal/configs"
+	"gopkg.in/yaml.v3"
+)
+
+// validate checks v against the subset of JSON Schema that JSONSchema uses and
+// returns the first violation.
+func validate(s map[string]any, v any, at string) error {
+	typ := s["type"]
+	if types, ok := typ.([]any); ok {
+		if v == nil && slices.Contains(types, "null") {
+			return nil
+		}
+		typ = types[0]
+	}
+	switch typ {
+	case "object":
+		if _, ok := v.(map[string]any); !ok {
+			return fmt.Errorf("%s: not an object", at)
+		}
+	case "array":
+		a, ok := v.([]any)
+		if !ok {
+			return fmt.Errorf("%s: not an array", at)
+		}
+		if n, ok := s["minItems"].(float64); ok && float64(len(a)) < n {
+			return fmt.Errorf("%s: fewer than %v items", at, n)
+		}
+		for i, item := range a {
+			if err := validate(s["items"].(map[string]any), item, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+	case "integer":
+		f, ok := v.(float64)
+		if !ok || f != float64(int64(f)) {
+			return fmt.Errorf("%s: %v is not an integer", at, v)
+		}
+		if m, ok := s["minimum"].(float64); ok && f < m {
+			return fmt.Errorf("%s: %v below %v", at, f, m)
+		}
+		if m, ok := s["maximum"].(float64); ok && f > m {
+			return fmt.Errorf("%s: %v above %v", at, f, m)
+		}
+	case "string":
+		if _, ok := v.(string); !ok {
+			return fmt.Errorf("%s: %v is not a string", at, v)
+		}
+	}
+	if m, ok := v.(map[string]any); ok {
+		props, _ := s["properties"].(map[string]any)
+		for k, kv := range m {
+			if names, ok := s["propertyNames"].(map[string]any); ok {
+				if err := validate(names, k, at+"."+k); err != nil {
+					return err
+				}
+			}
+			ps, ok := props[k].(map[string]any)
+			if !ok {
+				if s["additionalProperties"] == false {
+					return fmt.Errorf("%s: unknown key %s", at, k)
+				}
+				continue
+			}
+			if err := validate(ps, kv, at+"."+k); err != nil {
+				return err
+			}
+		}
+		req, _ := s["required"].([]any)
+		for _, k := range req {
+			if _, ok := m[k.(string)]; !ok {
+				return fmt.Errorf("%s: missing %s", at, k)
+			}
+		}
+		deps, _ := s["dependentRequired"].(map[string]any)
+		for k, req := range deps {
+			if _, ok := m[k]; !ok {
+				continue
+			}
+			for _, r := range req.([]any) {
+				if _, ok := m[r.(string)]; !ok {
+					return fmt.Errorf("%s: %s needs %s", at, k, r)
+				}
+			}
+		}
+	}
+	if p, ok := s["pattern"].(string); ok && !regexp.MustCompile(p).MatchString(v.(string)) {
+		return fmt.Errorf("%s: %v does not match %s", at, v, p)
+	}
+	if c, ok := s["const"]; ok && v != c {
+		return fmt.Errorf("%s: %v is not %v", at, v, c)
+	}
+	if enum, ok := s["enum"].([]any); ok && !slices.Contains(enum, v) {
+		return fmt.Errorf("%s: %v is not one of %v", at, v, enum)
+	}
+	if not, ok := s["not"].(map[string]any); ok && validate(not, v, at) == nil {
+		return fmt.Errorf("%s: %v is not allowed", at, v)
+	}
+	all, _ := s["allOf"].([]any)
+	for _, c := range all {
+		c := c.(map[string]any)
+		next := c
+		if cond, ok := c["if"].(map[string]any); ok {
+			next, _ = c["else"].(map[string]any)
+			if validate(cond, v, at) == nil {
+				next, _ = c["then"].(map[string]any)
+			}
+		}
+		if next == nil {
+			continue
+		}
+		if err := validate(next, v, at); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// schemaJSON returns JSONSchema and the YAML config src as decoded JSON.
+func schemaJSON(t *testing.T, src string) (map[string]any, any) {
+	t.Helper()
+	raw, err := json.Marshal(JSONSchema())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s map[string]any
+	json.Unmarshal(raw, &s)
+	var y any
+	if err := yaml.Unmarshal([]byte(src), &y); err != nil {
+		t.Fatal(err)
+	}
+	raw, err = json.Marshal(y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v any
+	json.Unmarshal(raw, &v)
+	return s, v
+}
+
+func TestJSONSchemaAcceptsConfigs(t *testing.T) {
+	embedded, err := ReadDir(configs.FS, "")
+	if err != nil || len(embedded) < 2 {
+		t.Fatalf("embedded configs %d, %v", len(embedded), err)
+	}
+	srcs := []string{base}
+	for _, src := range embedded {
+		srcs = append(srcs, string(src.Raw))
+	}
+	for i, src := range srcs {
+		s, v := schemaJSON(t, src)
+		if err := validate(s, v, "$"); err != nil {
+			t.Errorf("config %d: %v", i, err)
+		}
+	}
+}
+
+func TestJSONSchemaRejects(t *testing.T) {
+	cases := []struct{ old, new, want string }{
+		{"      bet_type: line_ltr", "      bet_typ: line_ltr", "unknown key bet_typ"},
+		{"bet_type: line_ltr", "bet_type: line_lrt", "line_lrt is not one of"},
+		{"max_win_limit: 10000", "max_win_limit: lots", "lots is not an integer"},
+		{"[Z1, C1, W1, H1, L1]", "[Z1, C1, WW, H1, L1]", "WW is not one of"},
+		{"bet_units: [20]", "bet_units: []", "bet_units: fewer than 1 items"},
+		{"{columns: 3, rows: 2, damp: 1}", "{columns: 3, rows: 0, damp: 1}", "rows: 0 below 1"},
+		{"game_id: 90\n", "", "missing game_id"},
+		{"  demo_c: lint", "  demo_cc: lint", "fixed: unknown key demo_cc"},
+		{"free_round: 10", "free_round: -1", "free_round: -1 below 0"},
+	}
+	for _, c := range cases {
+		s, v := schemaJSON(t, strings.Replace(base, c.old, c.new, 1))
+		if err := validate(s, v, "$"); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%q -> %q: got %v, want %q", c.old, c.new, err, c.want)
+		}
+	}
+}
+
+func TestJSONSchemaFixed(t *testing.T) {
+	s, _ := schemaJSON(t, base)
+	if s["$schema"] != SchemaDialect {
+		t.Fatalf("$schema %v", s["$schema"])
+	}
+	var keys []string
+	for _, c := range s["allOf"].([]any) {
+		c := c.(map[string]any)
+		props, ok := c["if"].(map[string]any)["properties"].(map[string]any)
+		if !ok {
+			continue // variant
+		}
+		key := props["logic_key"].(map[string]any)["const"].(string)
+		keys = append(keys, key)
+		fixed := c["then"].(map[string]any)["properties"].(map[string]any)["fixed"].(map[string]any)["properties"].(map[string]any)
+		for k, p := range fixed {
+			if _, ok := p.(map[string]any)["description"]; !ok {
+				t.Errorf("%s: fixed.%s has no description", key, k)
+			}
+		}
+	}
+	if !slices.Contains(keys, "demo_normal") || !slices.Contains(keys, "demo_cascade") {
+		t.Fatalf("logic keys %v", keys)
+	}
+}
+
+func TestJSONSchemaVariant(t *testing.T) {
+	const variant = "base: base.yaml\ngame_id: 91\ngame_name: v\nbet_units: [10]\noverrides:\n  game_mode_settings[0].reel_set_group[0].reels[1].weights: [1, 2, 3, 4]\n"
+	s, v := schemaJSON(t, variant)
+	if err := validate(s, v, "$"); err != nil {
+		t.Fatalf("variant: %v", err)
+	}
+	cases := []struct{ old, new, want string }{
+		{"game_name: v\n", "", "missing game_name"},
+		{"bet_units: [10]", "bet_units: []", "bet_units: fewer than 1 items"},
+		{"game_mode_settings[0].reel", "game_mode_settings[0]..reel", "does not match"},
+		{"game_mode_settings[0].reel_set_group[0].reels[1].weights", "game_id", "game_id is not allowed"},
+		{"base: base.yaml\n", "", "overrides needs base"},
+	}
+	for _, c := range cases {
+		s, v := schemaJSON(t, strings.Replace(variant, c.old, c.new, 1))
+		if err := validate(s, v, "$"); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%q -> %q: got %v, want %q", c.old, c.new, err, c.want)
+		}
+	}
+}
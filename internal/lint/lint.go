@@ -0,0 +1,571 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lint checks game configs without building them, and reports every problem at
+// its line and column in the YAML (or JSON) source with a suggested fix.
+//
+// It finds what spec.GetGameSettingByYAML and engine.New() reject with a terse error,
+// such as a pay_table that does not have one row per symbol_used entry, and what they
+// accept but the engine later misreads or panics on, such as a reel symbol id equal to
+// the number of symbols, a line_table row outside the screen or a pay_table row shorter
+// than the longest payable count. The `fixed:` block is checked against the struct the
+// logic decodes it into (logic.RegisterFixed).
+//
+// A variant (configs.VariantBase) is checked resolved, over its base among the linted
+// sources; problems in values it inherits are reported in the base file.
+//
+// JSONSchema exports the same rules, as far as JSON Schema can express them, for
+// editors and tools outside Go.
+package lint
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/zintix-labs/problab-scaffold/internal/configs"
+	"github.com/zintix-labs/problab/spec"
+	"gopkg.in/yaml.v3"
+)
+
+// Severity of a diagnostic. Errors break the engine or the game; warnings are probably
+// mistakes but the config still loads.
+type Severity string
+
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+)
+
+// Diagnostic is one problem of a config.
+type Diagnostic struct {
+	File     string   `json:"file"          yaml:"file"`
+	Line     int      `json:"line"          yaml:"line"`
+	Column   int      `json:"column"        yaml:"column"`
+	Severity Severity `json:"severity"      yaml:"severity"`
+	Path     string   `json:"path"          yaml:"path"` // e.g. game_mode_settings[0].symbol_setting.pay_table
+	Message  string   `json:"message"       yaml:"message"`
+	Fix      string   `json:"fix,omitempty" yaml:"fix,omitempty"`
+	// Variant is the variant being checked when File is one of its bases.
+	Variant string `json:"variant,omitempty" yaml:"variant,omitempty"`
+}
+
+// String formats d like a compiler diagnostic: file:line:column: severity: message.
+func (d Diagnostic) String() string {
+	s := fmt.Sprintf("%s:%d:%d: %s: %s", d.File, d.Line, d.Column, d.Severity, d.Message)
+	if d.Variant != "" {
+		s += " (in variant " + d.Variant + ")"
+	}
+	if d.Fix != "" {
+		s += "\n\tfix: " + d.Fix
+	}
+	return s
+}
+
+// Source is one config to lint.
+type Source struct {
+	File string // name used in the diagnostics
+	Raw  []byte
+}
+
+// Lint checks every source and what must be unique across them (game_id, game_name).
+// Diagnostics are in source order, then by position. A problem a variant inherits from
+// a linted base is reported once, for the base.
+func Lint(srcs []Source) []Diagnostic {
+	var diags []Diagnostic
+	ids := make(map[int]string)
+	names := make(map[string]string)
+	byName := make(map[string]Source, len(srcs))
+	for _, src := range srcs {
+		byName[filepath.Base(src.File)] = src
+	}
+	for _, src := range srcs {
+		f := check(src, byName)
+		if n, ok := f.node(path{"game_id"}); ok && n.Kind == yaml.ScalarNode {
+			if prev, dup := ids[int(f.gs.GameID)]; dup {
+				f.errorf(path{"game_id"}, "give each config its own game_id", "game_id %d is also used by %s", f.gs.GameID, prev)
+			} else {
+				ids[int(f.gs.GameID)] = fmt.Sprintf("%s:%d", src.File, n.Line)
+			}
+		}
+		if n, ok := f.node(path{"game_name"}); ok && f.gs.GameName != "" {
+			if prev, dup := names[f.gs.GameName]; dup {
+				f.warnf(path{"game_name"}, "game names are recommended unique within the catalog", "game_name %q is also used by %s", f.gs.GameName, prev)
+			} else {
+				names[f.gs.GameName] = fmt.Sprintf("%s:%d", src.File, n.Line)
+			}
+		}
+		slices.SortStableFunc(f.diags, func(a, b Diagnostic) int {
+			if a.File != b.File {
+				return strings.Compare(a.Variant, b.Variant) // the file itself first
+			}
+			if a.Line != b.Line {
+				return a.Line - b.Line
+			}
+			return a.Column - b.Column
+		})
+		diags = append(diags, f.diags...)
+	}
+
+	own := make(map[Diagnostic]bool)
+	for _, d := range diags {
+		if d.Variant == "" {
+			own[d] = true
+		}
+	}
+	return slices.DeleteFunc(diags, func(d Diagnostic) bool {
+		if d.Variant == "" {
+			return false
+		}
+		d.Variant = ""
+		return own[d]
+	})
+}
+
+// Dir lints every .yaml, .yml and .json config of the tree fsys, skipping files and
+// folders starting with "." or "_" like configs.Mount. dir prefixes the file names of
+// the diagnostics.
+func Dir(fsys fs.FS, dir string) ([]Diagnostic, error) {
+	srcs, err := ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	return Lint(srcs), nil
+}
+
+// ReadDir reads the configs Dir lints.
+func ReadDir(fsys fs.FS, dir string) ([]Source, error) {
+	var srcs []Source
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != "." && (strings.HasPrefix(d.Name(), ".") || strings.HasPrefix(d.Name(), "_")) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(p))
+		if d.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			return nil
+		}
+		raw, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		srcs = append(srcs, Source{File: filepath.Join(dir, filepath.FromSlash(p)), Raw: raw})
+		return nil
+	})
+	return srcs, err
+}
+
+// Files lints config files.
+func Files(names []string) ([]Diagnostic, error) {
+	srcs := make([]Source, len(names))
+	for i, name := range names {
+		raw, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		srcs[i] = Source{File: name, Raw: raw}
+	}
+	return Lint(srcs), nil
+}
+
+// Count returns the number of errors and warnings of diags.
+func Count(diags []Diagnostic) (errs, warnings int) {
+	for _, d := range diags {
+		if d.Severity == Error {
+			errs++
+		} else {
+			warnings++
+		}
+	}
+	return errs, warnings
+}
+
+// path addresses a node: string keys of mappings and int indexes of sequences.
+type path []any
+
+func (p path) String() string {
+	var b strings.Builder
+	for _, e := range p {
+		switch e := e.(type) {
+		case int:
+			fmt.Fprintf(&b, "[%d]", e)
+		default:
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			fmt.Fprint(&b, e)
+		}
+	}
+	return b.String()
+}
+
+func (p path) add(e ...any) path { return append(slices.Clip(p), e...) }
+
+// file is the lint state of one source.
+type file struct {
+	name  string
+	root  *yaml.Node       // top-level mapping; nil when the source did not parse
+	gs    spec.GameSetting // decoded as far as the types allow, not initialized
+	diags []Diagnostic
+	from  map[*yaml.Node]string // of a variant: the base file of the nodes it inherits
+}
+
+var lineRE = regexp.MustCompile(`^yaml: line (\d+): `)
+
+// parserProblems are the yaml.v3 parser (not scanner) errors; the parser reports
+// their line 0-based.
+var parserProblems = []string{
+	"did not find expected <", "did not find expected ',' or", "did not find expected key",
+	"did not find expected node content", "did not find expected '-' indicator",
+	"found duplicate %YAML", "found incompatible YAML", "found duplicate %TAG", "found undefined tag handle",
+}
+
+// check lints src; srcs are the linted sources by file name, where the base of a
+// variant is looked up.
+func check(src Source, srcs map[string]Source) *file {
+	f := &file{name: src.File}
+	if !f.parse(src.Raw) {
+		return f
+	}
+	if _, ok := configs.VariantBase(f.root); ok && !f.inherit(srcs, []string{filepath.Base(src.File)}) {
+		return f
+	}
+	// type mismatches are reported with their position by schema; decoding goes on
+	// past them and leaves those values zero
+	_ = f.root.Decode(&f.gs)
+
+	f.schema(f.root, reflect.TypeFor[spec.GameSetting](), nil)
+	f.game()
+	f.fixed()
+	return f
+}
+
+// parse sets the root of f, or reports why raw is not a config.
+func (f *file) parse(raw []byte) bool {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(bytes.NewReader(raw)).Decode(&doc); err != nil {
+		f.diags = append(f.diags, syntaxError(f.name, err))
+		return false
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		f.diags = append(f.diags, Diagnostic{File: f.name, Line: max(doc.Line, 1), Column: max(doc.Column, 1), Severity: Error, Message: "a config is a mapping of game_id, game_name, logic_key, bet_units, max_win_limit, game_mode_settings and fixed"})
+		return false
+	}
+	f.root = doc.Content[0]
+	return true
+}
+
+// inherit replaces the root of the variant f with the variant overlaid on its base, as
+// configs.Mount resolves it. chain holds the file names of the variants being resolved.
+// Problems of the base itself are left to the lint of the base; a base that cannot be
+// resolved is reported at the base: key of f.
+func (f *file) inherit(srcs map[string]Source, chain []string) bool {
+	name, _ := configs.VariantBase(f.root)
+	p := path{configs.BaseKey}
+	if slices.Contains(chain, name) {
+		f.errorf(p, "break the cycle", "variant cycle: %s -> %s", strings.Join(chain, " -> "), name)
+		return false
+	}
+	if !f.has(path{"game_id"}) || !f.has(path{"game_name"}) {
+		f.errorf(p, "add game_id and game_name", "a variant must set its own game_id and game_name")
+		return false
+	}
+	src, ok := srcs[name]
+	if !ok {
+		f.errorf(p, "lint it together with its base", "base %q is not among the linted configs", name)
+		return false
+	}
+	base := &file{name: src.File}
+	if !base.parse(src.Raw) {
+		f.errorf(p, "fix its base first", "base %s does not parse", src.File)
+		return false
+	}
+	if _, ok := configs.VariantBase(base.root); ok && !base.inherit(srcs, append(chain, name)) {
+		f.errorf(p, "fix its base first", "base %s does not resolve: %s", src.File, base.diags[0].Message)
+		return false
+	}
+	if f.from = base.from; f.from == nil {
+		f.from = make(map[*yaml.Node]string)
+	}
+	var mark func(n *yaml.Node)
+	mark = func(n *yaml.Node) {
+		if _, ok := f.from[n]; !ok {
+			f.from[n] = src.File
+		}
+		for _, c := range n.Content {
+			mark(c)
+		}
+	}
+	mark(base.root)
+	if err := configs.Overlay(base.root, f.root); err != nil {
+		var oe *configs.OverlayError
+		if errors.As(err, &oe) {
+			f.report(Error, oe.Node, path{configs.OverridesKey, oe.Path}, "overrides replace values that are in the base", "override %s: %s", oe.Path, oe.Msg)
+		} else {
+			f.errorf(nil, "", "%v", err)
+		}
+		return false
+	}
+	f.root = base.root
+	return true
+}
+
+// syntaxError is the diagnostic of a file that does not parse.
+func syntaxError(name string, err error) Diagnostic {
+	d := Diagnostic{File: name, Line: 1, Column: 1, Severity: Error, Message: strings.TrimPrefix(err.Error(), "yaml: "), Fix: "fix the syntax; nothing else is checked until the file parses"}
+	if m := lineRE.FindStringSubmatch(err.Error()); m != nil {
+		d.Line, _ = strconv.Atoi(m[1])
+		d.Message = strings.TrimPrefix(err.Error(), m[0])
+		for _, p := range parserProblems {
+			if strings.HasPrefix(d.Message, p) {
+				d.Line++
+				break
+			}
+		}
+	}
+	return d
+}
+
+// node returns the node at p, or its deepest existing ancestor and false.
+func (f *file) node(p path) (*yaml.Node, bool) {
+	n, _, ok := f.lookup(p)
+	return n, ok
+}
+
+// lookup is node that also returns the key of the node when it is a mapping value.
+func (f *file) lookup(p path) (n, key *yaml.Node, ok bool) {
+	if f.root == nil {
+		return &yaml.Node{Line: 1, Column: 1}, nil, false
+	}
+	n = f.root
+	for _, e := range p {
+		var next, nextKey *yaml.Node
+		switch e := e.(type) {
+		case string:
+			if n.Kind == yaml.MappingNode {
+				for i := 0; i+1 < len(n.Content); i += 2 {
+					if n.Content[i].Value == e {
+						next, nextKey = n.Content[i+1], n.Content[i]
+					}
+				}
+			}
+		case int:
+			if n.Kind == yaml.SequenceNode && e >= 0 && e < len(n.Content) {
+				next = n.Content[e]
+			}
+		}
+		if next == nil {
+			return n, key, false
+		}
+		if next.Kind == yaml.AliasNode && next.Alias != nil {
+			next = next.Alias
+		}
+		n, key = next, nextKey
+	}
+	return n, key, true
+}
+
+// has reports whether the key or index at p is in the source.
+func (f *file) has(p path) bool {
+	_, ok := f.node(p)
+	return ok
+}
+
+func (f *file) report(sev Severity, n *yaml.Node, p path, fix, format string, args ...any) {
+	d := Diagnostic{File: f.name, Line: n.Line, Column: n.Column, Severity: sev, Path: p.String(), Message: fmt.Sprintf(format, args...), Fix: fix}
+	if base, ok := f.from[n]; ok {
+		d.File, d.Variant = base, f.name
+	}
+	f.diags = append(f.diags, d)
+}
+
+// errorf reports an error at the node of p (or of its deepest existing ancestor).
+func (f *file) errorf(p path, fix, format string, args ...any) {
+	f.report(Error, f.pos(p), p, fix, format, args...)
+}
+
+// warnf reports a warning at the node of p (or of its deepest existing ancestor).
+func (f *file) warnf(p path, fix, format string, args ...any) {
+	f.report(Warning, f.pos(p), p, fix, format, args...)
+}
+
+// pos is the node a diagnostic about p points at: the key of a list or mapping value,
+// whose first item may be lines below, or else the node itself.
+func (f *file) pos(p path) *yaml.Node {
+	n, key, _ := f.lookup(p)
+	if key != nil && (n.Kind == yaml.SequenceNode || n.Kind == yaml.MappingNode) {
+		return key
+	}
+	return n
+}
+
+// schema checks that n can be decoded into t: no unknown keys, and values of the right
+// kind. Unknown keys are reported at the key, with the closest known key.
+func (f *file) schema(n *yaml.Node, t reflect.Type, p path) {
+	if n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!null" {
+		return // empty value: zero
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if n.Kind != yaml.MappingNode {
+			f.report(Error, n, p, "", "%s must be a mapping of %s", name(p), strings.Join(keys(t), ", "))
+			return
+		}
+		fields := make(map[string]reflect.StructField)
+		for _, k := range keys(t) {
+			sf, _ := field(t, k)
+			fields[k] = sf
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			sf, ok := fields[k.Value]
+			if !ok {
+				fix := "remove it; known keys: " + strings.Join(keys(t), ", ")
+				if s := suggest(k.Value, keys(t)); s != "" {
+					fix = fmt.Sprintf("did you mean %q?", s)
+				}
+				f.report(Error, k, p.add(k.Value), fix, "unknown key %q in %s", k.Value, name(p))
+				continue
+			}
+			f.schema(v, sf.Type, p.add(k.Value))
+		}
+	case reflect.Slice:
+		if n.Kind != yaml.SequenceNode {
+			f.report(Error, n, p, "write a list, e.g. [a, b, c]", "%s must be a list", name(p))
+			return
+		}
+		for i, item := range n.Content {
+			f.schema(item, t.Elem(), p.add(i))
+		}
+	case reflect.Map:
+		if n.Kind != yaml.MappingNode {
+			f.report(Error, n, p, "", "%s must be a mapping", name(p))
+		}
+	default:
+		if n.Kind != yaml.ScalarNode {
+			f.report(Error, n, p, "write "+kind(t), "%s must be %s", name(p), kind(t))
+			return
+		}
+		if err := n.Decode(reflect.New(t).Interface()); err != nil {
+			f.report(Error, n, p, "write "+kind(t), "%s: %q is not %s", name(p), n.Value, kind(t))
+		}
+	}
+}
+
+// keys returns the YAML keys of struct t, as yaml.v3 decodes them.
+func keys(t reflect.Type) []string {
+	var ks []string
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+		switch tag {
+		case "-":
+			continue
+		case "":
+			tag = strings.ToLower(sf.Name)
+		}
+		ks = append(ks, tag)
+	}
+	return ks
+}
+
+func field(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		tag, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+		if sf.IsExported() && (tag == key || (tag == "" && strings.ToLower(sf.Name) == key)) {
+			return sf, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// kind names the scalar type t for a message.
+func kind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "an integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a non-negative integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	}
+	return "a " + t.String()
+}
+
+// name is the path of a message: the whole config at the root.
+func name(p path) string {
+	if len(p) == 0 {
+		return "the config"
+	}
+	return p.String()
+}
+
+// suggest returns the candidate closest to s, or "" when none is close.
+func suggest(s string, candidates []string) string {
+	best, bestD := "", max(2, len(s)/3)+1
+	for _, c := range candidates {
+		if d := distance(strings.ToLower(s), strings.ToLower(c)); d < bestD {
+			best, bestD = c, d
+		}
+	}
+	return best
+}
+
+// distance is the Levenshtein distance of a and b.
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,179 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zintix-labs/problab-scaffold/internal/configs"
+)
+
+// base is a valid config of the demo_normal logic; each case breaks one line of it.
+const base = `game_id: 90
+game_name: lint_base
+logic_key: demo_normal
+bet_units: [20]
+max_win_limit: 10000
+game_mode_settings:
+  - screen_setting: {columns: 3, rows: 2, damp: 1}
+    gen_screen_setting:
+      gen_reel_type: GenReelByReelIdx
+      reel_set_group:
+        - weight: 1
+          reels:
+            - {symbols: [1, 2, 3, 4], weights: [1, 1, 2, 2]}
+            - {symbols: [2, 3, 4, 3]}
+            - {symbols: [4, 3, 1, 2]}
+    symbol_setting:
+      symbol_used: [Z1, C1, W1, H1, L1]
+      pay_table:
+        - [0, 0, 0]
+        - [0, 0, 0]
+        - [0, 0, 50]
+        - [0, 5, 20]
+        - [0, 2, 8]
+    hit_setting:
+      bet_type: line_ltr
+      line_table:
+        - [0, 0, 0]
+        - [1, 1, 1]
+fixed:
+  free_round: 10
+  demo_b: [0, 1]
+  demo_c: lint
+`
+
+func TestLintClean(t *testing.T) {
+	if diags := Lint([]Source{{File: "base.yaml", Raw: []byte(base)}}); len(diags) != 0 {
+		t.Fatalf("diagnostics of a valid config: %v", diags)
+	}
+	diags, err := Dir(configs.FS, "internal/configs/games")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs, _ := Count(diags); errs != 0 {
+		t.Fatalf("embedded configs: %v", diags)
+	}
+}
+
+func TestLintDiagnostics(t *testing.T) {
+	cases := []struct {
+		name, old, new string
+		line, column   int
+		sev            Severity
+		msg, fix       string
+	}{
+		{"pay table rows", "        - [0, 2, 8]\n", "", 18, 7, Error, "pay_table has 4 rows but symbol_used has 5 symbols", "add 1 row(s)"},
+		{"pay table row short", "- [0, 5, 20]", "- [0, 5]", 22, 11, Error, "row 3 (H1) has 2 entries", "pad the row with 0 to 3 entries"},
+		{"weights length", "weights: [1, 1, 2, 2]", "weights: [1, 1, 2]", 13, 39, Error, "reel set 0 reel 0 has 3 weights for 4 symbols", "add 1 weight(s)"},
+		{"line row range", "- [1, 1, 1]", "- [1, 2, 1]", 28, 15, Error, "line 1, column 1: row 2 is outside the 2 rows", "rows are 0 (top) to 1"},
+		{"line length", "- [1, 1, 1]", "- [1, 1]", 28, 11, Error, "line 1 has 2 entries, the screen has 3 columns", "3 entries"},
+		{"symbol id", "{symbols: [4, 3, 1, 2]}", "{symbols: [4, 3, 5, 2]}", 15, 32, Error, "symbol id 5 is not in symbol_used (5 symbols)", "0=Z1, 1=C1, 2=W1, 3=H1, 4=L1"},
+		{"bet units empty", "bet_units: [20]", "bet_units: []", 4, 1, Error, "bet_units is empty", "one per bet mode"},
+		{"bet unit above cap", "bet_units: [20]", "bet_units: [20, 20000]", 4, 17, Error, "bet mode 1 has bet unit 20000, above max_win_limit 10000", "raise max_win_limit"},
+		{"unknown key", "      bet_type: line_ltr", "      bet_typ: line_ltr", 25, 7, Error, `unknown key "bet_typ"`, `did you mean "bet_type"?`},
+		{"bet type", "bet_type: line_ltr", "bet_type: line_lrt", 25, 17, Error, `unknown bet_type "line_lrt"`, `did you mean "line_ltr"?`},
+		{"gen reel type", "GenReelByReelIdx", "GenReelByReelId", 9, 22, Error, `unknown gen_reel_type "GenReelByReelId"`, `did you mean "GenReelByReelIdx"?`},
+		{"symbol name", "[Z1, C1, W1, H1, L1]", "[Z1, C1, WW, H1, L1]", 17, 29, Error, `unknown symbol "WW"`, `did you mean "W1"?`},
+		{"type", "max_win_limit: 10000", "max_win_limit: lots", 5, 16, Error, `max_win_limit: "lots" is not an integer`, "write an integer"},
+		{"reel count", "            - {symbols: [4, 3, 1, 2]}\n", "", 12, 11, Error, "reel set 0 has 2 reels, the screen has 3 columns", "one reel per column: 3"},
+		{"logic key", "logic_key: demo_normal", "logic_key: demo_norml", 3, 12, Error, `no logic is registered as "demo_norml"`, `did you mean "demo_normal"?`},
+		{"fixed unknown", "  demo_c: lint", "  demo_cc: lint", 32, 3, Error, `unknown key "demo_cc" in fixed`, `did you mean "demo_c"?`},
+		{"fixed type", "free_round: 10", "free_round: ten", 30, 15, Error, `fixed.free_round: "ten" is not an integer`, ""},
+		{"fixed missing", "  demo_c: lint\n", "", 29, 1, Warning, "logic demo_normal reads demo_c from fixed", "add demo_c under fixed"},
+		{"duplicate line", "- [1, 1, 1]", "- [0, 0, 0]", 28, 11, Warning, "line 1 is the same as line 0", ""},
+		{"syntax", "  demo_c: lint", "  demo_c: [lint", 32, 1, Error, "did not find expected", "fix the syntax"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if !strings.Contains(base, tc.old) {
+				t.Fatalf("base has no %q", tc.old)
+			}
+			raw := strings.Replace(base, tc.old, tc.new, 1)
+			diags := Lint([]Source{{File: "base.yaml", Raw: []byte(raw)}})
+			for _, d := range diags {
+				if strings.Contains(d.Message, tc.msg) {
+					if d.Line != tc.line || d.Column != tc.column || d.Severity != tc.sev || !strings.Contains(d.Fix, tc.fix) || d.File != "base.yaml" {
+						t.Fatalf("got %s (%d:%d %s), want %d:%d %s fix %q", d, d.Line, d.Column, d.Severity, tc.line, tc.column, tc.sev, tc.fix)
+					}
+					return
+				}
+			}
+			t.Fatalf("no %q in %v", tc.msg, diags)
+		})
+	}
+}
+
+func TestLintAcrossConfigs(t *testing.T) {
+	other := strings.Replace(base, "game_name: lint_base", "game_name: lint_other", 1)
+	diags := Lint([]Source{{File: "a.yaml", Raw: []byte(base)}, {File: "b.yaml", Raw: []byte(other)}})
+	if len(diags) != 1 || diags[0].File != "b.yaml" || diags[0].Line != 1 || !strings.Contains(diags[0].Message, "game_id 90 is also used by a.yaml:1") {
+		t.Fatalf("diagnostics %v", diags)
+	}
+	if !strings.HasPrefix(diags[0].String(), "b.yaml:1:10: error: game_id 90") {
+		t.Fatalf("format %q", diags[0].String())
+	}
+}
+
+func TestLintVariant(t *testing.T) {
+	variant := func(overrides string) Source {
+		return Source{File: "games/v.yaml", Raw: []byte("base: base.yaml\ngame_id: 91\ngame_name: lint_v\n" + overrides)}
+	}
+	baseSrc := Source{File: "games/base.yaml", Raw: []byte(base)}
+	if diags := Lint([]Source{baseSrc, variant("overrides:\n  bet_units: [10]\n")}); len(diags) != 0 {
+		t.Fatalf("diagnostics of a valid variant: %v", diags)
+	}
+
+	cases := []struct {
+		name      string
+		srcs      []Source
+		file      string
+		line, col int
+		msg       string
+	}{
+		{"own value", []Source{baseSrc, variant("overrides:\n  game_mode_settings[0].hit_setting.bet_type: line_lrt\n")},
+			"games/v.yaml", 5, 47, `unknown bet_type "line_lrt"`},
+		{"inherited value", []Source{baseSrc, variant("overrides:\n  game_mode_settings[0].symbol_setting.symbol_used: [Z1, C1, W1, H1]\n")},
+			"games/base.yaml", 18, 7, "pay_table has 5 rows but symbol_used has 4 symbols"},
+		{"bad path", []Source{baseSrc, variant("overrides:\n  game_mode_settings[2].bet_units: [1]\n")},
+			"games/v.yaml", 5, 3, "game_mode_settings has 1 items in the base, no index 2"},
+		{"no base", []Source{variant("")},
+			"games/v.yaml", 1, 7, `base "base.yaml" is not among the linted configs`},
+		{"cycle", []Source{{File: "a.yaml", Raw: []byte("base: b.yaml\ngame_id: 1\ngame_name: a\n")}, {File: "b.yaml", Raw: []byte("base: a.yaml\ngame_id: 2\ngame_name: b\n")}},
+			"a.yaml", 1, 7, "variant cycle: a.yaml -> b.yaml -> a.yaml"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			diags := Lint(tc.srcs)
+			for _, d := range diags {
+				if strings.Contains(d.Message, tc.msg) {
+					if d.File != tc.file || d.Line != tc.line || d.Column != tc.col || (d.Variant != "") != (d.File == "games/base.yaml") {
+						t.Fatalf("got %s, want %s:%d:%d", d, tc.file, tc.line, tc.col)
+					}
+					return
+				}
+			}
+			t.Fatalf("no %q in %v", tc.msg, diags)
+		})
+	}
+
+	// a problem of the base is reported once, for the base
+	broken := strings.Replace(base, "bet_type: line_ltr", "bet_type: line_lrt", 1)
+	diags := Lint([]Source{{File: "games/base.yaml", Raw: []byte(broken)}, variant("")})
+	if len(diags) != 1 || diags[0].Variant != "" {
+		t.Fatalf("diagnostics %v", diags)
+	}
+}
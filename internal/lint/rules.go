@@ -0,0 +1,390 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/zintix-labs/problab-scaffold/internal/logic"
+	"github.com/zintix-labs/problab/spec"
+)
+
+// betTypes and genReelTypes are the values the spec accepts.
+var (
+	betTypes     = []string{"line_ltr", "line_rtl", "line_both", "way_ltr", "way_rtl", "way_both", "count", "cluster"}
+	genReelTypes = slices.Sorted(func(yield func(string) bool) {
+		for k, v := range spec.GenReelTypeMap {
+			if v != spec.GenReelTypeNone && !yield(k) {
+				return
+			}
+		}
+	})
+	symbolNames = func() []string {
+		var names []string
+		for _, c := range "ZSCWHL" {
+			for d := '1'; d <= '9'; d++ {
+				if _, ok := spec.ParseSymbol(string(c) + string(d)); ok {
+					names = append(names, string(c)+string(d))
+				}
+			}
+		}
+		return names
+	}()
+)
+
+// game checks the top-level settings and every game mode.
+func (f *file) game() {
+	gs := &f.gs
+	if !f.has(path{"game_id"}) {
+		f.errorf(path{"game_id"}, "add `game_id: <id>`, unique within the catalog", "game_id is missing")
+	}
+	if gs.GameName == "" {
+		f.warnf(path{"game_name"}, "add `game_name: <name>`; reports and the catalog show it", "game_name is missing or empty")
+	}
+	switch key := gs.LogicKey; {
+	case key == "":
+		f.errorf(path{"logic_key"}, "add `logic_key: <key>` of a logic registered in internal/logic", "logic_key is missing")
+	case !logic.Logics.IsExist(key):
+		fix := "register a logic with this key in internal/logic, or use a registered key"
+		if s := suggest(string(key), keyStrings(logic.FixedKeys())); s != "" {
+			fix = fmt.Sprintf("did you mean %q?", s)
+		}
+		f.errorf(path{"logic_key"}, fix, "no logic is registered as %q", key)
+	}
+
+	if gs.MaxWinLimit <= 0 {
+		f.errorf(path{"max_win_limit"}, "set the largest total win of a spin, in credits", "max_win_limit must be > 0, got %d", gs.MaxWinLimit)
+	}
+	if len(gs.BetUnits) == 0 {
+		f.errorf(path{"bet_units"}, "add `bet_units: [<bet of bet mode 0>, ...]`, one per bet mode", "bet_units is empty: the game has no bet mode")
+	}
+	for i, b := range gs.BetUnits {
+		p := path{"bet_units", i}
+		switch {
+		case b < 1:
+			f.errorf(p, "use a positive bet in credits", "bet mode %d has bet unit %d", i, b)
+		case gs.MaxWinLimit > 0 && b > gs.MaxWinLimit:
+			f.errorf(p, fmt.Sprintf("raise max_win_limit to at least %d or lower the bet", b), "bet mode %d has bet unit %d, above max_win_limit %d", i, b, gs.MaxWinLimit)
+		}
+	}
+
+	if len(gs.GameModeSettings) == 0 {
+		f.errorf(path{"game_mode_settings"}, "add the base game as game_mode_settings[0]", "game_mode_settings is empty")
+	}
+	for i := range gs.GameModeSettings {
+		f.mode(i)
+	}
+}
+
+// mode checks one game mode.
+func (f *file) mode(i int) {
+	gms := &f.gs.GameModeSettings[i]
+	p := path{"game_mode_settings", i}
+	for _, k := range []string{"screen_setting", "gen_screen_setting", "symbol_setting", "hit_setting"} {
+		if !f.has(p.add(k)) {
+			f.errorf(p.add(k), "add the "+k+" block", "game mode %d has no %s", i, k)
+		}
+	}
+
+	sc := &gms.ScreenSetting
+	cols, rows := sc.Columns, sc.Rows
+	sp := p.add("screen_setting")
+	if cols <= 0 {
+		f.errorf(sp.add("columns"), "set the number of reels (columns) of the screen", "columns must be > 0, got %d", cols)
+	}
+	if rows <= 0 {
+		f.errorf(sp.add("rows"), "set the number of visible rows of the screen", "rows must be > 0, got %d", rows)
+	}
+	if sc.Damp < 0 {
+		f.errorf(sp.add("damp"), "use 0 or more extra symbols above and below the screen", "damp must be >= 0, got %d", sc.Damp)
+	}
+	if sc.Mask != nil && cols > 0 && rows > 0 {
+		if len(sc.Mask) != cols*rows {
+			f.errorf(sp.add("mask"), fmt.Sprintf("one entry per cell: %d (columns x rows), or remove mask for a full screen", cols*rows), "mask has %d entries, the screen has %d cells", len(sc.Mask), cols*rows)
+		}
+		for j, v := range sc.Mask {
+			if v > 1 {
+				f.errorf(sp.add("mask", j), "use 1 for a cell and 0 for no cell", "mask entry %d is %d", j, v)
+			}
+		}
+	}
+
+	symbols := f.symbols(i)
+	bt, btOK := f.hits(i)
+	f.payTable(i, symbols, bt, btOK)
+	f.reels(i, symbols)
+}
+
+// symbols checks symbol_used and returns it (with the ids in the fix of reel errors).
+func (f *file) symbols(i int) []string {
+	ss := &f.gs.GameModeSettings[i].SymbolSetting
+	p := path{"game_mode_settings", i, "symbol_setting", "symbol_used"}
+	if len(ss.SymbolUsedStr) == 0 {
+		f.errorf(p, "list the symbols of the mode, e.g. [Z1, C1, W1, H1, L1]; reels and pay_table refer to them by index", "symbol_used is empty")
+	}
+	if len(ss.SymbolUsedStr) > 64 {
+		f.errorf(p, "use at most 64 symbols", "symbol_used has %d symbols; the calculators keep symbols in a 64-bit mask", len(ss.SymbolUsedStr))
+	}
+	seen := make(map[string]int)
+	for j, s := range ss.SymbolUsedStr {
+		if _, ok := spec.ParseSymbol(s); !ok {
+			fix := "symbols are Z1-Z9 (none), S1-S9 (special), C1-C9 (scatter), W1-W9 (wild), H1-H9 (high), L1-L9 (low)"
+			if sug := suggest(s, symbolNames); sug != "" {
+				fix = fmt.Sprintf("did you mean %q?", sug)
+			}
+			f.errorf(p.add(j), fix, "unknown symbol %q", s)
+		}
+		if prev, dup := seen[s]; dup {
+			f.errorf(p.add(j), "list each symbol once", "symbol %s is listed twice (ids %d and %d)", s, prev, j)
+		} else {
+			seen[s] = j
+		}
+	}
+	return ss.SymbolUsedStr
+}
+
+// hits checks hit_setting and returns the bet type.
+func (f *file) hits(i int) (spec.BetType, bool) {
+	gms := &f.gs.GameModeSettings[i]
+	hs := &gms.HitSetting
+	cols, rows := gms.ScreenSetting.Columns, gms.ScreenSetting.Rows
+	p := path{"game_mode_settings", i, "hit_setting"}
+	if hs.BetTypeStr == "" {
+		f.errorf(p.add("bet_type"), "one of "+strings.Join(betTypes, ", "), "bet_type is missing")
+		return 0, false
+	}
+	bt, ok := spec.ParseBetType(hs.BetTypeStr)
+	if !ok {
+		fix := "one of " + strings.Join(betTypes, ", ")
+		if s := suggest(hs.BetTypeStr, betTypes); s != "" {
+			fix = fmt.Sprintf("did you mean %q?", s)
+		}
+		f.errorf(p.add("bet_type"), fix, "unknown bet_type %q", hs.BetTypeStr)
+		return 0, false
+	}
+
+	lp := p.add("line_table")
+	if !spec.IsBetTypeLine(bt) {
+		if len(hs.LineTable) > 0 {
+			f.warnf(lp, "remove line_table", "line_table is ignored by bet_type %s", hs.BetTypeStr)
+		}
+		return bt, true
+	}
+	if len(hs.LineTable) == 0 {
+		f.errorf(lp, "add one line per payline: the row of each column, e.g. [1, 1, 1, 1, 1]", "bet_type %s needs a line_table", hs.BetTypeStr)
+	}
+	seen := make(map[string]int)
+	for l, line := range hs.LineTable {
+		if cols > 0 && len(line) != cols {
+			f.errorf(lp.add(l), fmt.Sprintf("one row index per column: %d entries", cols), "line %d has %d entries, the screen has %d columns", l, len(line), cols)
+		}
+		for c, r := range line {
+			if rows > 0 && (r < 0 || int(r) >= rows) {
+				f.errorf(lp.add(l, c), fmt.Sprintf("rows are 0 (top) to %d", rows-1), "line %d, column %d: row %d is outside the %d rows of the screen", l, c, r, rows)
+			}
+		}
+		k := fmt.Sprint(line)
+		if prev, dup := seen[k]; dup {
+			f.warnf(lp.add(l), "remove the duplicate, or change it if another line was meant", "line %d is the same as line %d: that path pays twice", l, prev)
+		} else {
+			seen[k] = l
+		}
+	}
+	return bt, true
+}
+
+// payTable checks that pay_table has one row per symbol, each as long as the longest
+// count the bet type can pay.
+func (f *file) payTable(i int, symbols []string, bt spec.BetType, btOK bool) {
+	gms := &f.gs.GameModeSettings[i]
+	pt := gms.SymbolSetting.PayTable
+	cols, rows := gms.ScreenSetting.Columns, gms.ScreenSetting.Rows
+	p := path{"game_mode_settings", i, "symbol_setting", "pay_table"}
+	if len(pt) != len(symbols) {
+		fix := fmt.Sprintf("one row per symbol_used entry, in the same order: add %d row(s)", len(symbols)-len(pt))
+		if len(pt) > len(symbols) {
+			fix = fmt.Sprintf("one row per symbol_used entry, in the same order: remove %d row(s) or add the missing symbols", len(pt)-len(symbols))
+		}
+		f.errorf(p, fix, "pay_table has %d rows but symbol_used has %d symbols", len(pt), len(symbols))
+	}
+
+	// the count of a win indexes its row: up to the columns for lines and ways, up to
+	// the cells of the screen for counts and clusters
+	want, unit := 0, ""
+	if btOK && cols > 0 && rows > 0 {
+		want, unit = cols, "columns"
+		if spec.IsBetTypeCount(bt) || spec.IsBetTypeCluster(bt) {
+			want, unit = cols*rows, "cells"
+		}
+	} else if len(pt) > 0 {
+		want, unit = len(pt[0]), "entries of the first row"
+	}
+	for r, row := range pt {
+		sym := "?"
+		if r < len(symbols) {
+			sym = symbols[r]
+		}
+		switch {
+		case want > 0 && len(row) < want:
+			f.errorf(p.add(r), fmt.Sprintf("pad the row with 0 to %d entries (pay of 1 to %d of a kind)", want, want), "row %d (%s) has %d entries, the screen allows wins of up to %d %s", r, sym, len(row), want, unit)
+		case want > 0 && len(row) > want:
+			f.warnf(p.add(r), fmt.Sprintf("trim the row to %d entries", want), "row %d (%s) has %d entries; pays beyond %d %s are never reached", r, sym, len(row), want, unit)
+		}
+		paid := false
+		for c, v := range row {
+			if v < 0 {
+				f.errorf(p.add(r, c), "use 0 for no pay", "row %d (%s) pays %d for %d of a kind", r, sym, v, c+1)
+			}
+			paid = paid || v > 0
+		}
+		if s, ok := spec.ParseSymbol(sym); ok && !paid && (spec.IsSymbolHigh(s) || spec.IsSymbolLow(s)) {
+			f.warnf(p.add(r), "add its pays, or make it a Z (none) symbol", "%s is a paying symbol type but its row is all 0", sym)
+		}
+	}
+}
+
+// reels checks the reel sets of GenReelByReelIdx and GenReelBySymbolWeight.
+func (f *file) reels(i int, symbols []string) {
+	gms := &f.gs.GameModeSettings[i]
+	gen := &gms.GenScreenSetting
+	cols := gms.ScreenSetting.Columns
+	p := path{"game_mode_settings", i, "gen_screen_setting"}
+	gt, ok := spec.GenReelTypeMap[gen.GenReelTypeStr]
+	switch {
+	case gen.GenReelTypeStr == "":
+		f.errorf(p.add("gen_reel_type"), "one of "+strings.Join(genReelTypes, ", "), "gen_reel_type is missing")
+	case !ok || gt == spec.GenReelTypeNone:
+		fix := "one of " + strings.Join(genReelTypes, ", ")
+		if s := suggest(gen.GenReelTypeStr, genReelTypes); s != "" {
+			fix = fmt.Sprintf("did you mean %q?", s)
+		}
+		f.errorf(p.add("gen_reel_type"), fix, "unknown gen_reel_type %q", gen.GenReelTypeStr)
+	}
+
+	gp := p.add("reel_set_group")
+	if len(gen.ReelSetGroup) == 0 {
+		f.errorf(gp, "add at least one reel set: {weight: 1, reels: [...]}", "reel_set_group is empty")
+		return
+	}
+	total := 0
+	for s, rs := range gen.ReelSetGroup {
+		if rs.Weight < 0 {
+			f.errorf(gp.add(s, "weight"), "use 0 or a positive weight", "reel set %d has weight %d", s, rs.Weight)
+		}
+		total += max(rs.Weight, 0)
+	}
+	if total == 0 {
+		f.errorf(gp, "give at least one reel set a positive weight", "every reel set has weight 0: none can be drawn")
+	}
+
+	ids := idList(symbols)
+	for s, rs := range gen.ReelSetGroup {
+		sp := gp.add(s, "reels")
+		if gt == spec.GenReelByReelIdx && cols > 0 && len(rs.Reels) != cols {
+			f.errorf(sp, fmt.Sprintf("one reel per column: %d", cols), "reel set %d has %d reels, the screen has %d columns", s, len(rs.Reels), cols)
+		}
+		for r, reel := range rs.Reels {
+			rp := sp.add(r)
+			if len(reel.ReelSymbols) == 0 {
+				f.errorf(rp.add("symbols"), "list the symbol id of every stop", "reel set %d reel %d has no symbols", s, r)
+			}
+			for k, id := range reel.ReelSymbols {
+				if int(id) < 0 || int(id) >= len(symbols) {
+					f.errorf(rp.add("symbols", k), "ids index symbol_used: "+ids, "reel set %d reel %d stop %d: symbol id %d is not in symbol_used (%d symbols)", s, r, k, id, len(symbols))
+				}
+			}
+			if reel.ReelWeights == nil {
+				continue
+			}
+			wp := rp.add("weights")
+			if len(reel.ReelWeights) != len(reel.ReelSymbols) {
+				fix := fmt.Sprintf("one weight per stop: add %d weight(s), or remove weights to weigh every stop 1", len(reel.ReelSymbols)-len(reel.ReelWeights))
+				if len(reel.ReelWeights) > len(reel.ReelSymbols) {
+					fix = fmt.Sprintf("one weight per stop: remove %d weight(s)", len(reel.ReelWeights)-len(reel.ReelSymbols))
+				}
+				f.errorf(wp, fix, "reel set %d reel %d has %d weights for %d symbols", s, r, len(reel.ReelWeights), len(reel.ReelSymbols))
+			}
+			sum := 0
+			for k, w := range reel.ReelWeights {
+				if w < 0 {
+					f.errorf(wp.add(k), "use 0 or a positive weight", "reel set %d reel %d stop %d has weight %d", s, r, k, w)
+				}
+				sum += max(w, 0)
+			}
+			if len(reel.ReelWeights) > 0 && sum == 0 {
+				f.errorf(wp, "give at least one stop a positive weight", "reel set %d reel %d: every stop has weight 0", s, r)
+			}
+		}
+	}
+}
+
+// fixed checks the `fixed:` block against the struct the logic decodes it into: every
+// key must be a field (spec.DecodeFixed is strict), of the field's type. Fields that are
+// not set are warned about: the logic sees their zero value.
+func (f *file) fixed() {
+	key := f.gs.LogicKey
+	if key == "" || !logic.Logics.IsExist(key) {
+		return
+	}
+	dec, ok := logic.Fixed(key)
+	p := path{"fixed"}
+	if !ok {
+		if len(f.gs.Fixed) > 0 {
+			f.warnf(p, "register its decoder with logic.RegisterFixed so the block can be checked", "logic %q registers no fixed decoder: the block is not checked", key)
+		}
+		return
+	}
+	t := reflect.TypeOf(dec).Elem()
+	known := keys(t)
+	n, has := f.node(p)
+	if has {
+		f.schema(n, t, p)
+	}
+	present := make(map[string]bool)
+	if has {
+		for j := 0; j+1 < len(n.Content); j += 2 {
+			present[n.Content[j].Value] = true
+		}
+	}
+	var missing []string
+	for _, k := range known {
+		if !present[k] {
+			missing = append(missing, k)
+		}
+	}
+	if len(missing) > 0 {
+		f.warnf(p, "add "+strings.Join(missing, ", ")+" under fixed", "logic %s reads %s from fixed, not set here: it gets zero values", key, strings.Join(missing, ", "))
+	}
+}
+
+// idList lists symbol ids for a fix, e.g. "0=Z1, 1=C1, 2=W1".
+func idList(symbols []string) string {
+	parts := make([]string, len(symbols))
+	for id, s := range symbols {
+		parts[id] = fmt.Sprintf("%d=%s", id, s)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func keyStrings(keys []spec.LogicKey) []string {
+	out := make([]string, len(keys))
+	for i, k := range keys {
+		out[i] = string(k)
+	}
+	return out
+}
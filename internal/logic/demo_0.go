@@ -28,13 +28,10 @@ import (
 
 func init() {
 	logic := "demo_normal"
-	if err := slot.GameRegister[*buf.NoExtend](
-		spec.LogicKey(logic),
-		buildGame0000,
-		Logics,
-	); err != nil {
+	if err := Register[*buf.NoExtend](spec.LogicKey(logic), buildGame0000); err != nil {
 		log.Fatalf("%s register failed: %v", logic, err)
 	}
+	RegisterFixed(spec.LogicKey(logic), func() any { return new(fixed0000) })
 }
 
 // ============================================================
@@ -71,6 +68,15 @@ type fixed0000 struct {
 	symboltypes []spec.SymbolType
 }
 
+// FixedSchema describes the fixed keys in the config JSON Schema.
+func (fixed0000) FixedSchema() map[string]map[string]any {
+	return map[string]map[string]any{
+		"free_round": {"description": "free spins played when the base game triggers", "minimum": 0},
+		"demo_b":     {"description": "demo value, not read by the logic"},
+		"demo_c":     {"description": "demo value, not read by the logic"},
+	}
+}
+
 // ============================================================
 // ** Game-specific Extension State (implements Reset and Snapshot) **
 // ============================================================
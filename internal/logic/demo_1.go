@@ -29,13 +29,10 @@ import (
 
 func init() {
 	logic := "demo_cascade"
-	if err := slot.GameRegister[*buf.NoExtend](
-		spec.LogicKey(logic),
-		buildGame0001,
-		Logics,
-	); err != nil {
+	if err := Register[*buf.NoExtend](spec.LogicKey(logic), buildGame0001); err != nil {
 		log.Fatalf("%s register failed: %v", logic, err)
 	}
+	RegisterFixed(spec.LogicKey(logic), func() any { return new(fixed0001) })
 }
 
 // ============================================================
@@ -74,6 +71,16 @@ type fixed0001 struct {
 	symbolTypes   []spec.SymbolType
 }
 
+// FixedSchema describes the fixed keys in the config JSON Schema.
+func (fixed0001) FixedSchema() map[string]map[string]any {
+	return map[string]map[string]any{
+		"max_step":    {"description": "most cascade steps of one round", "minimum": 1},
+		"free_rounds": {"description": "free spins played when the base game triggers", "minimum": 0},
+		"trigger":     {"description": "scatters on the screen that trigger the free spins", "minimum": 1},
+		"scatter_pay": {"description": "pay of a trigger, multiplied by the bet multiple", "minimum": 0},
+	}
+}
+
 // ============================================================
 // ** Game-specific Extension State (implements Reset and Snapshot) **
 // ============================================================
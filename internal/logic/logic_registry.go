@@ -13,7 +13,16 @@
 // limitations under the License.
 package logic
 
-import "github.com/zintix-labs/problab/sdk/slot"
+import (
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/zintix-labs/problab/sdk/buf"
+	"github.com/zintix-labs/problab/sdk/slot"
+	"github.com/zintix-labs/problab/spec"
+)
 
 // Logics is the global logic registry for this scaffold.
 //
@@ -25,4 +34,89 @@ import "github.com/zintix-labs/problab/sdk/slot"
 // Do NOT move it to another package, otherwise your init-time registrations may not run
 // (or may create a different registry instance), and the engine will fail to resolve
 // logic builders by key.
+//
+// Register logics with Register, which also records them for the build manifest. A
+// logic registered directly with slot.GameRegister still runs, but the manifest only
+// knows its key: its package and module are recorded as "unknown".
 var Logics = slot.NewLogicRegistry()
+
+// builders maps a logic key to the builder registered in Logics with Register; the
+// registry itself does not list its keys.
+var builders = map[spec.LogicKey]slot.LogicBuilder{}
+
+// Register registers the builder of a logic in Logics, T being the extend result type
+// its spins output (see slot.GameRegister), and records it for Keys and Package.
+func Register[T buf.ExtendResult](key spec.LogicKey, builder slot.LogicBuilder) error {
+	if err := slot.GameRegister[T](key, builder, Logics); err != nil {
+		return err
+	}
+	builders[key] = builder
+	return nil
+}
+
+// Keys returns the logic keys registered with Register, sorted.
+func Keys() []spec.LogicKey {
+	keys := make([]spec.LogicKey, 0, len(builders))
+	for k := range builders {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Package returns the import path of the Go package that defines the builder of a
+// logic, e.g. "github.com/zintix-labs/problab-scaffold/internal/logic".
+func Package(key spec.LogicKey) (string, bool) {
+	b, ok := builders[key]
+	if !ok {
+		return "", false
+	}
+	// a function name is the package path, a dot, then the function (or type and method)
+	name := runtime.FuncForPC(reflect.ValueOf(b).Pointer()).Name()
+	slash := strings.LastIndex(name, "/") + 1
+	if dot := strings.Index(name[slash:], "."); dot >= 0 {
+		name = name[:slash+dot]
+	}
+	return name, true
+}
+
+// fixedDecoders maps a logic key to a constructor of the struct its builder decodes the
+// game's `fixed:` block into with spec.DecodeFixed.
+//
+// Register it next to the logic (RegisterFixed in the same init) so that tools such as
+// the config linter can check a `fixed:` block without building the game.
+var fixedDecoders = map[spec.LogicKey]func() any{}
+
+// FixedSchema is implemented by a `fixed:` decoder that describes its keys in the JSON
+// Schema of game configs (`go run ./cmd/run schema`). The types come from the struct;
+// FixedSchema returns extra JSON Schema keywords per YAML key, such as a description,
+// minimum or enum.
+type FixedSchema interface {
+	FixedSchema() map[string]map[string]any
+}
+
+// RegisterFixed records the decoder struct of the `fixed:` block of a logic; newFixed
+// returns a pointer to a new zero value of it.
+func RegisterFixed(key spec.LogicKey, newFixed func() any) {
+	fixedDecoders[key] = newFixed
+}
+
+// Fixed returns a pointer to a new zero decoder struct of the `fixed:` block of a logic,
+// or false when the logic registered none.
+func Fixed(key spec.LogicKey) (any, bool) {
+	newFixed, ok := fixedDecoders[key]
+	if !ok {
+		return nil, false
+	}
+	return newFixed(), true
+}
+
+// FixedKeys returns the logic keys that registered a `fixed:` decoder, sorted.
+func FixedKeys() []spec.LogicKey {
+	keys := make([]spec.LogicKey, 0, len(fixedDecoders))
+	for k := range fixedDecoders {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
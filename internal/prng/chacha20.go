@@ -0,0 +1,146 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prng
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/zintix-labs/problab/sdk/core"
+)
+
+// chachaSigma is "expand 32-byte k", the first row of every ChaCha20 state.
+var chachaSigma = [4]uint32{0x61707865, 0x3320646e, 0x79622d32, 0x6b206574}
+
+// chacha20 generates the ChaCha20 keystream of key with a zero nonce: state words
+// 12-13 are a 64-bit block counter (the original ChaCha layout), so the stream does
+// not wrap for 2^64 blocks. Each 64-byte block gives 8 outputs.
+type chacha20 struct {
+	key [8]uint32
+	ctr uint64    // counter of the next block
+	buf [8]uint64 // outputs of block ctr-1
+	i   int       // next output in buf; len(buf) when used up
+}
+
+// newChaCha20 takes the key from 4 splitmix64 outputs of seed, so the key holds at most
+// the 64 bits of entropy of the seed (see the package doc).
+func newChaCha20(seed uint64) core.PRNG {
+	var key [8]uint32
+	for i := 0; i < len(key); i += 2 {
+		k := splitmix64(&seed)
+		key[i], key[i+1] = uint32(k), uint32(k>>32)
+	}
+	return newChaCha20Key(key)
+}
+
+func newChaCha20Key(key [8]uint32) *chacha20 {
+	c := &chacha20{key: key}
+	c.i = len(c.buf)
+	return c
+}
+
+// chachaBlock computes the ChaCha20 block function (RFC 8439 2.3) of in.
+func chachaBlock(out, in *[16]uint32) {
+	x0, x1, x2, x3 := in[0], in[1], in[2], in[3]
+	x4, x5, x6, x7 := in[4], in[5], in[6], in[7]
+	x8, x9, x10, x11 := in[8], in[9], in[10], in[11]
+	x12, x13, x14, x15 := in[12], in[13], in[14], in[15]
+	for range 10 {
+		x0, x4, x8, x12 = quarterRound(x0, x4, x8, x12)
+		x1, x5, x9, x13 = quarterRound(x1, x5, x9, x13)
+		x2, x6, x10, x14 = quarterRound(x2, x6, x10, x14)
+		x3, x7, x11, x15 = quarterRound(x3, x7, x11, x15)
+		x0, x5, x10, x15 = quarterRound(x0, x5, x10, x15)
+		x1, x6, x11, x12 = quarterRound(x1, x6, x11, x12)
+		x2, x7, x8, x13 = quarterRound(x2, x7, x8, x13)
+		x3, x4, x9, x14 = quarterRound(x3, x4, x9, x14)
+	}
+	*out = [16]uint32{x0, x1, x2, x3, x4, x5, x6, x7, x8, x9, x10, x11, x12, x13, x14, x15}
+	for i := range out {
+		out[i] += in[i]
+	}
+}
+
+func quarterRound(a, b, c, d uint32) (uint32, uint32, uint32, uint32) {
+	a += b
+	d = bits.RotateLeft32(d^a, 16)
+	c += d
+	b = bits.RotateLeft32(b^c, 12)
+	a += b
+	d = bits.RotateLeft32(d^a, 8)
+	c += d
+	b = bits.RotateLeft32(b^c, 7)
+	return a, b, c, d
+}
+
+// refill computes block ctr into buf.
+func (c *chacha20) refill() {
+	var in, out [16]uint32
+	copy(in[:4], chachaSigma[:])
+	copy(in[4:12], c.key[:])
+	in[12], in[13] = uint32(c.ctr), uint32(c.ctr>>32)
+	chachaBlock(&out, &in)
+	for i := range c.buf {
+		c.buf[i] = uint64(out[2*i]) | uint64(out[2*i+1])<<32
+	}
+	c.ctr++
+	c.i = 0
+}
+
+// Uint64 returns the next 8 bytes of the keystream, little-endian.
+func (c *chacha20) Uint64() uint64 {
+	if c.i == len(c.buf) {
+		c.refill()
+	}
+	u := c.buf[c.i]
+	c.i++
+	return u
+}
+
+func (c *chacha20) UintN(max uint) uint { return uintN(c, max) }
+func (c *chacha20) IntN(max int) int    { return intN(c, max) }
+func (c *chacha20) Float64() float64    { return float64Of(c.Uint64()) }
+
+// Snapshot is "chacha20:", the key, the next block counter and the next output index.
+func (c *chacha20) Snapshot() ([]byte, error) {
+	b := []byte(nameChaCha20 + ":")
+	for _, k := range c.key {
+		b = binary.LittleEndian.AppendUint32(b, k)
+	}
+	b = binary.LittleEndian.AppendUint64(b, c.ctr)
+	return append(b, byte(c.i)), nil
+}
+
+func (c *chacha20) Restore(data []byte) error {
+	body, err := unmarshal(nameChaCha20, data, 32+8+1)
+	if err != nil {
+		return err
+	}
+	i := int(body[40])
+	ctr := binary.LittleEndian.Uint64(body[32:])
+	if i > len(c.buf) || (i < len(c.buf) && ctr == 0) {
+		return errInvalid(nameChaCha20)
+	}
+	for k := range c.key {
+		c.key[k] = binary.LittleEndian.Uint32(body[4*k:])
+	}
+	c.ctr, c.i = ctr, len(c.buf)
+	if i < len(c.buf) {
+		c.ctr--
+		c.refill()
+		c.i = i
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prng
+
+import "github.com/zintix-labs/problab/sdk/core"
+
+// newPCG64 is the upstream default generator: math/rand/v2 PCG with its two state
+// words derived from the seed by splitmix64. Its snapshot is the math/rand/v2
+// encoding, "pcg:" and the big-endian state words.
+func newPCG64(seed uint64) core.PRNG {
+	return core.Default().New(int64(seed))
+}
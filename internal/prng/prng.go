@@ -0,0 +1,152 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package prng provides the PRNG implementations the engine can be built with, each a
+// `core.PRNGFactory` checked against published known-answer vectors:
+//
+//   - PCG64: PCG-DXSM with 128-bit state (math/rand/v2 PCG); this is the upstream
+//     `core.Default()` generator, so it reproduces the spins of an unmodified engine.
+//   - ChaCha20: the RFC 8439 ChaCha20 block function as a CSPRNG, its keystream read as
+//     little-endian uint64s, with a 256-bit key and a 64-bit block counter.
+//   - Xoshiro256: xoshiro256** by Blackman and Vigna, 256-bit state.
+//
+// A seed is expanded into the initial state with splitmix64, so every seed gives a
+// distinct, full-width state and New(seed) is deterministic for a given version of
+// this package. Bounded draws (UintN/IntN) are unbiased (Lemire's multiply-and-reject)
+// and Float64 has 53 bits of precision, both as upstream.
+//
+// Seeds are 64-bit (core.PRNGFactory takes an int64), so every generator has at most
+// 2^64 starting states whatever its state width. In particular the 256-bit ChaCha20
+// key is derived from the seed and carries at most 64 bits of entropy, not 256: its
+// output is only as unpredictable as the seed, and recovering the seed from output is
+// a 64-bit search. Where outcomes must not be predictable, draw seeds from crypto/rand
+// (as cmd/run does without -seed) and keep them secret.
+//
+// Snapshot/Restore serialize the whole generator state behind a "<name>:" prefix, so
+// a state of one generator is rejected by another.
+package prng
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/zintix-labs/problab/sdk/core"
+)
+
+// Factory is a named core.PRNGFactory.
+type Factory struct {
+	name string
+	new  func(seed uint64) core.PRNG
+}
+
+const (
+	namePCG64      = "pcg64"
+	nameChaCha20   = "chacha20"
+	nameXoshiro256 = "xoshiro256**"
+)
+
+var (
+	PCG64      = &Factory{name: namePCG64, new: newPCG64}
+	ChaCha20   = &Factory{name: nameChaCha20, new: newChaCha20}
+	Xoshiro256 = &Factory{name: nameXoshiro256, new: newXoshiro256}
+)
+
+// All returns the built-in factories.
+func All() []*Factory {
+	return []*Factory{PCG64, ChaCha20, Xoshiro256}
+}
+
+// Lookup returns the built-in factory of name.
+func Lookup(name string) (*Factory, bool) {
+	for _, f := range All() {
+		if f.name == name {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// Name is the algorithm name, e.g. "chacha20".
+func (f *Factory) Name() string { return f.name }
+
+// New returns the generator of seed.
+func (f *Factory) New(seed int64) core.PRNG { return f.new(uint64(seed)) }
+
+// splitmix64 advances the splitmix64 state x and returns its next output.
+func splitmix64(x *uint64) uint64 {
+	*x += 0x9e3779b97f4a7c15
+	z := *x
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return z ^ z>>31
+}
+
+// source is the raw 64-bit output of a generator.
+type source interface {
+	Uint64() uint64
+}
+
+// uint64n returns an unbiased draw in [0,n), n > 0.
+func uint64n[S source](s S, n uint64) uint64 {
+	if n&(n-1) == 0 {
+		return s.Uint64() & (n - 1)
+	}
+	hi, lo := bits.Mul64(s.Uint64(), n)
+	if lo < n {
+		thresh := -n % n
+		for lo < thresh {
+			hi, lo = bits.Mul64(s.Uint64(), n)
+		}
+	}
+	return hi
+}
+
+// uintN implements core.RAND.UintN: 0 when max == 0.
+func uintN[S source](s S, max uint) uint {
+	if max == 0 {
+		return 0
+	}
+	return uint(uint64n(s, uint64(max)))
+}
+
+// intN implements core.RAND.IntN: -1 when max <= 0.
+func intN[S source](s S, max int) int {
+	if max <= 0 {
+		return -1
+	}
+	return int(uint64n(s, uint64(max)))
+}
+
+// float64Of maps the low 53 bits of u to [0,1), like the upstream PCG64.
+func float64Of(u uint64) float64 {
+	return float64(u<<11>>11) / (1 << 53)
+}
+
+// unmarshal checks the "<name>:" prefix and length of a snapshot and returns its body.
+func unmarshal(name string, data []byte, n int) ([]byte, error) {
+	body, ok := bytes.CutPrefix(data, []byte(name+":"))
+	if !ok {
+		return nil, fmt.Errorf("prng: not a %s state", name)
+	}
+	if len(body) != n {
+		return nil, errInvalid(name)
+	}
+	return body, nil
+}
+
+func errInvalid(name string) error {
+	return errors.New("prng: invalid " + name + " state")
+}
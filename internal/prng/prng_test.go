@@ -0,0 +1,176 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prng
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/zintix-labs/problab/sdk/core"
+)
+
+// TestSplitMix64KAT checks the seed expansion against the reference splitmix64.c.
+func TestSplitMix64KAT(t *testing.T) {
+	x := uint64(0)
+	for i, want := range []uint64{0xe220a8397b1dcdaf, 0x6e789e6aa1b965f4, 0x06c45d188009454f} {
+		if got := splitmix64(&x); got != want {
+			t.Fatalf("splitmix64 #%d = %#x, want %#x", i, got, want)
+		}
+	}
+}
+
+// TestPCG64KAT checks the state (1, 2) against the math/rand/v2 vectors of NewPCG(1, 2).
+func TestPCG64KAT(t *testing.T) {
+	p := PCG64.New(0)
+	state := binary.BigEndian.AppendUint64(binary.BigEndian.AppendUint64([]byte("pcg:"), 1), 2)
+	if err := p.Restore(state); err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []uint64{0xc4f5a58656eef510, 0x9dcec3ad077dec6c, 0xc8d04605312f8088, 0xcbedc0dcb63ac19a, 0x3bf98798cae97950} {
+		if got := p.Uint64(); got != want {
+			t.Fatalf("PCG64 #%d = %#x, want %#x", i, got, want)
+		}
+	}
+}
+
+// TestPCG64IsDefault: building with PCG64 replays the spins of core.Default().
+func TestPCG64IsDefault(t *testing.T) {
+	for _, seed := range []int64{0, 1, -7, 2305843009213693951} {
+		a, b := PCG64.New(seed), core.Default().New(seed)
+		for range 100 {
+			if a.Uint64() != b.Uint64() || a.IntN(37) != b.IntN(37) || a.Float64() != b.Float64() {
+				t.Fatalf("seed %d: PCG64 differs from core.Default()", seed)
+			}
+		}
+	}
+}
+
+// TestChaCha20KAT checks the block function against RFC 8439 section 2.3.2 and the
+// generator against the keystream of the all-zero key (RFC 8439 A.1, vectors 1 and 2).
+func TestChaCha20KAT(t *testing.T) {
+	in := [16]uint32{12: 1, 13: 0x09000000, 14: 0x4a000000}
+	copy(in[:], chachaSigma[:])
+	for i := range 8 {
+		in[4+i] = binary.LittleEndian.Uint32([]byte{byte(4 * i), byte(4*i + 1), byte(4*i + 2), byte(4*i + 3)})
+	}
+	var out [16]uint32
+	chachaBlock(&out, &in)
+	var got []byte
+	for _, w := range out {
+		got = binary.LittleEndian.AppendUint32(got, w)
+	}
+	want := hexBytes(t, `
+		10 f1 e7 e4 d1 3b 59 15 50 0f dd 1f a3 20 71 c4 c7 d1 f4 c7 33 c0 68 03 04 22 aa 9a c3 d4 6c 4e
+		d2 82 64 46 07 9f aa 09 14 c2 d7 05 d9 8b 02 a2 b5 12 9c d1 de 16 4e b9 cb d0 83 e8 a2 50 3c 4e`)
+	if !bytes.Equal(got, want) {
+		t.Fatalf("block\n got %x\nwant %x", got, want)
+	}
+
+	c := newChaCha20Key([8]uint32{})
+	got = got[:0]
+	for range 16 {
+		got = binary.LittleEndian.AppendUint64(got, c.Uint64())
+	}
+	want = hexBytes(t, `
+		76 b8 e0 ad a0 f1 3d 90 40 5d 6a e5 53 86 bd 28 bd d2 19 b8 a0 8d ed 1a a8 36 ef cc 8b 77 0d c7
+		da 41 59 7c 51 57 48 8d 77 24 e0 3f b8 d8 4a 37 6a 43 b8 f4 15 18 a1 1c c3 87 b6 69 b2 ee 65 86
+		9f 07 e7 be 55 51 38 7a 98 ba 97 7c 73 2d 08 0d cb 0f 29 a0 48 e3 65 69 12 c6 53 3e 32 ee 7a ed
+		29 b7 21 76 9c e6 4e 43 d5 71 33 b0 74 d8 39 d5 31 ed 1f 28 51 0a fb 45 ac e1 0a 1f 4b 79 4d 6f`)
+	if !bytes.Equal(got, want) {
+		t.Fatalf("keystream\n got %x\nwant %x", got, want)
+	}
+}
+
+// TestXoshiro256KAT checks the state {1, 2, 3, 4} against the reference xoshiro256starstar.c.
+func TestXoshiro256KAT(t *testing.T) {
+	x := &xoshiro256{s: [4]uint64{1, 2, 3, 4}}
+	for i, want := range []uint64{11520, 0, 1509978240, 1215971899390074240} {
+		if got := x.Uint64(); got != want {
+			t.Fatalf("xoshiro256** #%d = %d, want %d", i, got, want)
+		}
+	}
+}
+
+func TestFactories(t *testing.T) {
+	for _, f := range All() {
+		if g, ok := Lookup(f.Name()); !ok || g != f {
+			t.Fatalf("Lookup(%q)", f.Name())
+		}
+		a, b, c := f.New(42), f.New(42), f.New(43)
+		same, diff := true, false
+		for range 20 {
+			x, y, z := a.Uint64(), b.Uint64(), c.Uint64()
+			same = same && x == y
+			diff = diff || x != z
+		}
+		if !same || !diff {
+			t.Fatalf("%s: New is not deterministic per seed", f.Name())
+		}
+
+		// a snapshot taken mid-block continues the same stream
+		for range 5 {
+			a.Uint64()
+		}
+		state, err := a.Snapshot()
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := f.New(0)
+		if err := r.Restore(state); err != nil {
+			t.Fatalf("%s: %v", f.Name(), err)
+		}
+		for i := range 20 {
+			if a.Uint64() != r.Uint64() {
+				t.Fatalf("%s: restored stream differs at %d", f.Name(), i)
+			}
+		}
+		for _, g := range All() {
+			if g == f {
+				continue
+			}
+			other, _ := g.New(1).Snapshot()
+			if err := r.Restore(other); err == nil {
+				t.Fatalf("%s restored a %s state", f.Name(), g.Name())
+			}
+		}
+		if err := r.Restore(state[:len(state)-1]); err == nil || !strings.Contains(err.Error(), "invalid") {
+			t.Fatalf("%s: truncated state: %v", f.Name(), err)
+		}
+
+		if a.IntN(0) != -1 || a.UintN(0) != 0 {
+			t.Fatalf("%s: IntN(0)/UintN(0)", f.Name())
+		}
+		for range 1000 {
+			if n := a.IntN(7); n < 0 || n >= 7 {
+				t.Fatalf("%s: IntN(7) = %d", f.Name(), n)
+			}
+			if u := a.Float64(); u < 0 || u >= 1 {
+				t.Fatalf("%s: Float64() = %v", f.Name(), u)
+			}
+		}
+	}
+}
+
+func hexBytes(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(strings.Join(strings.Fields(s), ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -0,0 +1,79 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prng
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/zintix-labs/problab/sdk/core"
+)
+
+// xoshiro256 is xoshiro256** (https://prng.di.unimi.it/xoshiro256starstar.c).
+type xoshiro256 struct {
+	s [4]uint64
+}
+
+// newXoshiro256 fills the state with 4 splitmix64 outputs of seed, as the authors
+// recommend; it is never all zero.
+func newXoshiro256(seed uint64) core.PRNG {
+	x := &xoshiro256{}
+	for i := range x.s {
+		x.s[i] = splitmix64(&seed)
+	}
+	return x
+}
+
+func (x *xoshiro256) Uint64() uint64 {
+	s := &x.s
+	out := bits.RotateLeft64(s[1]*5, 7) * 9
+	t := s[1] << 17
+	s[2] ^= s[0]
+	s[3] ^= s[1]
+	s[1] ^= s[2]
+	s[0] ^= s[3]
+	s[2] ^= t
+	s[3] = bits.RotateLeft64(s[3], 45)
+	return out
+}
+
+func (x *xoshiro256) UintN(max uint) uint { return uintN(x, max) }
+func (x *xoshiro256) IntN(max int) int    { return intN(x, max) }
+func (x *xoshiro256) Float64() float64    { return float64Of(x.Uint64()) }
+
+// Snapshot is "xoshiro256**:" and the 4 state words, little-endian.
+func (x *xoshiro256) Snapshot() ([]byte, error) {
+	b := []byte(nameXoshiro256 + ":")
+	for _, w := range x.s {
+		b = binary.LittleEndian.AppendUint64(b, w)
+	}
+	return b, nil
+}
+
+func (x *xoshiro256) Restore(data []byte) error {
+	body, err := unmarshal(nameXoshiro256, data, 32)
+	if err != nil {
+		return err
+	}
+	var s [4]uint64
+	for i := range s {
+		s[i] = binary.LittleEndian.Uint64(body[8*i:])
+	}
+	if s == [4]uint64{} {
+		return errInvalid(nameXoshiro256)
+	}
+	x.s = s
+	return nil
+}
@@ -0,0 +1,227 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reels
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/zintix-labs/problab-scaffold/internal/rngtest"
+	"github.com/zintix-labs/problab/sdk/core"
+	"github.com/zintix-labs/problab/sdk/gen"
+	"github.com/zintix-labs/problab/spec"
+)
+
+// Picks is the sampled reel picks of a game mode, drawn with the upstream
+// ScreenGenerator.
+type Picks struct {
+	GenReelType string      `json:"gen_reel_type" yaml:"gen_reel_type"`
+	Screens     int         `json:"screens"       yaml:"screens"` // per check
+	Checks      []PickCheck `json:"checks"        yaml:"checks"`
+}
+
+// PickCheck compares the sampled picks of a reel, or of the reel set, with the
+// probabilities the config designs: weight / total weight.
+type PickCheck struct {
+	ReelSet int     `json:"reel_set" yaml:"reel_set"` // -1: the reel set selection of GenScreen
+	Reel    int     `json:"reel"     yaml:"reel"`     // -1: the reel set selection of GenScreen
+	Cells   int     `json:"cells"    yaml:"cells"`    // stops (reel sets) of weight > 0
+	Picks   int     `json:"picks"    yaml:"picks"`
+	Stat    float64 `json:"chi2"     yaml:"chi2"`
+	DF      int     `json:"df"       yaml:"df"`
+	P       float64 `json:"p_value"  yaml:"p_value"`
+	// Worst is the stop (reel set) furthest from its probability in standard deviations,
+	// observed at Observed instead of Expected.
+	Worst    int     `json:"worst"    yaml:"worst"`
+	Observed float64 `json:"observed" yaml:"observed"`
+	Expected float64 `json:"expected" yaml:"expected"`
+	// ZeroWeight counts picks of stops (reel sets) of weight 0; Mismatches counts
+	// screens whose column is not the window of the picked stop. Either is a bug.
+	ZeroWeight int  `json:"zero_weight" yaml:"zero_weight"`
+	Mismatches int  `json:"mismatches"  yaml:"mismatches"`
+	Deviates   bool `json:"deviates"    yaml:"deviates"`
+}
+
+// Label names the check, e.g. "reel set 1 reel 3".
+func (c PickCheck) Label() string {
+	if c.ReelSet < 0 {
+		return "reel set selection"
+	}
+	return fmt.Sprintf("reel set %d reel %d", c.ReelSet, c.Reel)
+}
+
+// Judge marks c as deviating when it picked a weight-0 cell, mismatched a screen or has
+// a p-value below threshold.
+func (c *PickCheck) Judge(threshold float64) bool {
+	c.Deviates = c.ZeroWeight > 0 || c.Mismatches > 0 || c.P < threshold
+	return c.Deviates
+}
+
+// recorder records the IntN draws of a PRNG: LUT.Pick draws one IntN per pick.
+type recorder struct {
+	core.PRNG
+	draws []int
+}
+
+func (r *recorder) IntN(n int) int {
+	v := r.PRNG.IntN(n)
+	r.draws = append(r.draws, v)
+	return v
+}
+
+// SamplePicks draws screens screens of every reel set of gms with the upstream
+// ScreenGenerator and a PRNG of f seeded with seed, and checks the stop picked on every
+// reel. A pick is read off the IntN draw of the reel's LUT and checked against the
+// screen; the reel set selection is checked on screens more GenScreen draws.
+func SamplePicks(gms *spec.GameModeSetting, f core.PRNGFactory, seed int64, screens int) (*Picks, error) {
+	if screens < 1 {
+		return nil, errors.New("screens must be at least 1")
+	}
+	if err := errors.Join(gms.ScreenSetting.Init(), gms.GenScreenSetting.Init()); err != nil {
+		return nil, err
+	}
+	gs := &gms.GenScreenSetting
+	perCell := gs.GenReelType == spec.GenReelBySymbolWeight
+	if !perCell && gs.GenReelType != spec.GenReelByReelIdx {
+		return nil, fmt.Errorf("gen_reel_type %s is not supported", gs.GenReelTypeStr)
+	}
+	cols, rows := gms.ScreenSetting.Columns, gms.ScreenSetting.Rows
+	for si, rs := range gs.ReelSetGroup {
+		if len(rs.Reels) != cols {
+			return nil, fmt.Errorf("reel set %d has %d reels for %d columns", si, len(rs.Reels), cols)
+		}
+	}
+	rec := &recorder{PRNG: f.New(seed)}
+	sg := gen.NewScreenGenerator(core.New(rec), &gms.ScreenSetting, gs)
+	picksPerScreen := cols
+	if perCell {
+		picksPerScreen = cols * rows
+	}
+
+	// stops counts the picks of every reel in the draws of one screen of reel set si
+	stops := func(si int, draws []int, screen []int16, counts [][]int, mm []int) error {
+		if len(draws) != picksPerScreen {
+			return fmt.Errorf("reel set %d: the generator drew %d numbers for a screen, want %d", si, len(draws), picksPerScreen)
+		}
+		reels := gs.ReelSetGroup[si].Reels
+		for k, v := range draws {
+			col, row := k, 0
+			if perCell {
+				col, row = k/rows, k%rows
+			}
+			reel := &reels[col]
+			stop := reel.ReelLUT[v]
+			counts[col][stop]++
+			for r := range rows {
+				if perCell && r != row {
+					continue
+				}
+				sym := reel.ReelSymbols[stop]
+				if !perCell {
+					sym = reel.ReelSymbols[(stop+r)%len(reel.ReelSymbols)]
+				}
+				if screen[r*cols+col] != sym {
+					mm[col]++
+					break
+				}
+			}
+		}
+		return nil
+	}
+
+	res := &Picks{GenReelType: gs.GenReelTypeStr, Screens: screens}
+	if len(gs.ReelSetGroup) > 1 {
+		sets := make([]int, len(gs.ReelSetGroup))
+		mismatches := 0
+		for range screens {
+			rec.draws = rec.draws[:0]
+			screen := sg.GenScreen()
+			si := gs.ReelSetLUT[rec.draws[0]]
+			sets[si]++
+			counts := make([][]int, cols)
+			for c := range counts {
+				counts[c] = make([]int, len(gs.ReelSetGroup[si].Reels[c].ReelSymbols))
+			}
+			mm := make([]int, cols)
+			if err := stops(si, rec.draws[1:], screen, counts, mm); err != nil {
+				return nil, err
+			}
+			for _, m := range mm {
+				if m > 0 {
+					mismatches++
+					break
+				}
+			}
+		}
+		weights := make([]int, len(gs.ReelSetGroup))
+		for si, rs := range gs.ReelSetGroup {
+			weights[si] = rs.Weight
+		}
+		check := compare(sets, weights)
+		check.ReelSet, check.Reel, check.Mismatches = -1, -1, mismatches
+		res.Checks = append(res.Checks, check)
+	}
+	for si, rs := range gs.ReelSetGroup {
+		counts := make([][]int, cols)
+		for c := range counts {
+			counts[c] = make([]int, len(rs.Reels[c].ReelSymbols))
+		}
+		mm := make([]int, cols)
+		for range screens {
+			rec.draws = rec.draws[:0]
+			screen := sg.GenScreenByReelSetIdx(si)
+			if err := stops(si, rec.draws, screen, counts, mm); err != nil {
+				return nil, err
+			}
+		}
+		for ri, reel := range rs.Reels {
+			check := compare(counts[ri], reel.ReelWeights)
+			check.ReelSet, check.Reel, check.Mismatches = si, ri, mm[ri]
+			res.Checks = append(res.Checks, check)
+		}
+	}
+	return res, nil
+}
+
+// compare tests the counts of the cells against their weights.
+func compare(counts, weights []int) PickCheck {
+	total, picks := 0, 0
+	for i, w := range weights {
+		total += w
+		picks += counts[i]
+	}
+	c := PickCheck{Picks: picks}
+	var obs []int
+	var exp []float64
+	worst := -1.0
+	for i, w := range weights {
+		if w == 0 {
+			c.ZeroWeight += counts[i]
+			continue
+		}
+		c.Cells++
+		e := float64(picks) * float64(w) / float64(total)
+		obs, exp = append(obs, counts[i]), append(exp, e)
+		if z := math.Abs(float64(counts[i])-e) / math.Sqrt(e); z > worst {
+			worst = z
+			c.Worst = i
+			c.Observed = float64(counts[i]) / float64(picks)
+			c.Expected = float64(w) / float64(total)
+		}
+	}
+	c.Stat, c.DF, c.P = rngtest.ChiSquare(obs, exp)
+	return c
+}
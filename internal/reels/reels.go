@@ -0,0 +1,220 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package reels inspects the reel strips of a game mode: how often each symbol is on a
+// reel, how likely it is to land and to be visible, and how close identical special
+// symbols sit to each other. SamplePicks checks the upstream screen generator against
+// the weights by drawing screens.
+//
+// A reel stop shows the window of `rows` symbols starting at that stop, wrapping around
+// the strip, and is drawn with the probability of its weight, like the upstream
+// `GenReelByReelIdx` generator.
+package reels
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zintix-labs/problab/spec"
+)
+
+// Anomaly kinds.
+const (
+	// ScatterStack: one stop shows a scatter more than once on the same reel.
+	ScatterStack = "scatter_stack"
+	// ShortReel: the strip is shorter than the window, so a stop shows a symbol twice.
+	ShortReel = "short_reel"
+	// Unreachable: a symbol is on the strip but every window showing it has weight 0.
+	Unreachable = "unreachable"
+)
+
+// Report is the inspection of every reel of a game mode.
+type Report struct {
+	Columns   int       `json:"columns"   yaml:"columns"`
+	Rows      int       `json:"rows"      yaml:"rows"`
+	Symbols   []string  `json:"symbols"   yaml:"symbols"`
+	ReelSets  []ReelSet `json:"reel_sets" yaml:"reel_sets"`
+	Anomalies []Anomaly `json:"anomalies" yaml:"anomalies"`
+}
+
+// ReelSet is one entry of reel_set_group.
+type ReelSet struct {
+	Index  int    `json:"index"  yaml:"index"`
+	Weight int    `json:"weight" yaml:"weight"`
+	Reels  []Reel `json:"reels"  yaml:"reels"`
+}
+
+// Reel is one strip of a reel set.
+type Reel struct {
+	Index       int          `json:"index"        yaml:"index"`
+	Length      int          `json:"length"       yaml:"length"`
+	TotalWeight int          `json:"total_weight" yaml:"total_weight"`
+	Weighted    bool         `json:"weighted"     yaml:"weighted"` // stops have different weights
+	Symbols     []SymbolStat `json:"symbols"      yaml:"symbols"`  // by symbol id
+}
+
+// SymbolStat is one symbol on one reel.
+type SymbolStat struct {
+	Symbol  string  `json:"symbol"  yaml:"symbol"`
+	ID      int     `json:"id"      yaml:"id"`
+	Special bool    `json:"special" yaml:"special"` // scatter, wild or special (S) symbol
+	Count   int     `json:"count"   yaml:"count"`   // stops of the strip
+	P       float64 `json:"p"       yaml:"p"`       // weighted probability of the stop landing on it
+	Visible float64 `json:"visible" yaml:"visible"` // P(at least one in the window)
+	Stacked float64 `json:"stacked" yaml:"stacked"` // P(two or more in the window)
+	// MinGap is the smallest distance between two stops of the symbol along the strip,
+	// wrapping around (1: adjacent); 0 when it is on the strip less than twice.
+	MinGap int `json:"min_gap" yaml:"min_gap"`
+}
+
+// Anomaly is a suspicious property of one reel.
+type Anomaly struct {
+	Kind    string `json:"kind"             yaml:"kind"`
+	ReelSet int    `json:"reel_set"         yaml:"reel_set"`
+	Reel    int    `json:"reel"             yaml:"reel"`
+	Symbol  string `json:"symbol,omitempty" yaml:"symbol,omitempty"`
+	Stops   []int  `json:"stops,omitempty"  yaml:"stops,omitempty"` // window start stops that show it
+	Message string `json:"message"          yaml:"message"`
+}
+
+func (a Anomaly) String() string {
+	return fmt.Sprintf("reel set %d reel %d: %s", a.ReelSet, a.Reel, a.Message)
+}
+
+// Inspect reports the reels of gms, which must draw its screens with GenReelByReelIdx.
+func Inspect(gms *spec.GameModeSetting) (*Report, error) {
+	if err := errors.Join(gms.ScreenSetting.Init(), gms.GenScreenSetting.Init(), gms.SymbolSetting.Init()); err != nil {
+		return nil, err
+	}
+	if gms.GenScreenSetting.GenReelType != spec.GenReelByReelIdx {
+		return nil, fmt.Errorf("gen_reel_type %s is not supported, only GenReelByReelIdx", gms.GenScreenSetting.GenReelTypeStr)
+	}
+	ss := &gms.SymbolSetting
+	rep := &Report{
+		Columns: gms.ScreenSetting.Columns,
+		Rows:    gms.ScreenSetting.Rows,
+		Symbols: ss.SymbolUsedStr,
+	}
+	if rep.Rows < 1 {
+		return nil, errors.New("screen must have at least one row")
+	}
+	for si, rs := range gms.GenScreenSetting.ReelSetGroup {
+		set := ReelSet{Index: si, Weight: rs.Weight}
+		for ri := range rs.Reels {
+			reel, anomalies, err := inspectReel(rs.Reels[ri], ss, rep.Rows)
+			if err != nil {
+				return nil, fmt.Errorf("reel set %d reel %d: %w", si, ri, err)
+			}
+			reel.Index = ri
+			for _, a := range anomalies {
+				a.ReelSet, a.Reel = si, ri
+				if rs.Weight == 0 {
+					a.Message += " (reel set weight 0: never drawn)"
+				}
+				rep.Anomalies = append(rep.Anomalies, a)
+			}
+			set.Reels = append(set.Reels, reel)
+		}
+		rep.ReelSets = append(rep.ReelSets, set)
+	}
+	return rep, nil
+}
+
+func inspectReel(r spec.Reel, ss *spec.SymbolSetting, rows int) (Reel, []Anomaly, error) {
+	n := len(r.ReelSymbols)
+	weights := r.ReelWeights
+	if len(weights) == 0 {
+		weights = make([]int, n)
+		for i := range weights {
+			weights[i] = 1
+		}
+	}
+	reel := Reel{Length: n, Symbols: make([]SymbolStat, ss.SymbolCount)}
+	for i, w := range weights {
+		reel.TotalWeight += w
+		reel.Weighted = reel.Weighted || w != weights[0]
+		if id := int(r.ReelSymbols[i]); id < 0 || id >= ss.SymbolCount {
+			return reel, nil, fmt.Errorf("stop %d has symbol id %d, only %d symbols are used", i, id, ss.SymbolCount)
+		}
+	}
+	if n == 0 || reel.TotalWeight <= 0 {
+		return reel, nil, errors.New("empty reel or total weight 0")
+	}
+	total := float64(reel.TotalWeight)
+
+	var anomalies []Anomaly
+	if n < rows {
+		anomalies = append(anomalies, Anomaly{Kind: ShortReel, Message: fmt.Sprintf("%d stops for a window of %d rows: a stop shows some symbols twice", n, rows)})
+	}
+
+	last := make([]int, ss.SymbolCount)  // last stop of each symbol while scanning
+	first := make([]int, ss.SymbolCount) // first stop of each symbol
+	for i := range last {
+		last[i], first[i] = -1, -1
+	}
+	for i, s := range r.ReelSymbols {
+		st := &reel.Symbols[s]
+		st.Count++
+		st.P += float64(weights[i]) / total
+		if last[s] >= 0 && (st.MinGap == 0 || i-last[s] < st.MinGap) {
+			st.MinGap = i - last[s]
+		}
+		if first[s] < 0 {
+			first[s] = i
+		}
+		last[s] = i
+	}
+
+	in := make([]int, ss.SymbolCount)
+	stacks := make([][]int, ss.SymbolCount)
+	for stop, w := range weights {
+		clear(in)
+		for row := range rows {
+			in[r.ReelSymbols[(stop+row)%n]]++
+		}
+		p := float64(w) / total
+		for id, k := range in {
+			if k > 0 {
+				reel.Symbols[id].Visible += p
+			}
+			if k > 1 {
+				reel.Symbols[id].Stacked += p
+				if w > 0 {
+					stacks[id] = append(stacks[id], stop)
+				}
+			}
+		}
+	}
+
+	for id := range reel.Symbols {
+		st := &reel.Symbols[id]
+		st.Symbol, st.ID = ss.SymbolUsedStr[id], id
+		switch ss.SymbolTypes[id] {
+		case spec.SymbolTypeScatter, spec.SymbolTypeWild, spec.SymbolTypeSpecial:
+			st.Special = true
+		}
+		if st.Count > 1 {
+			if wrap := n - last[id] + first[id]; wrap < st.MinGap {
+				st.MinGap = wrap
+			}
+		}
+		if st.Count > 0 && st.Visible == 0 {
+			anomalies = append(anomalies, Anomaly{Kind: Unreachable, Symbol: st.Symbol, Message: fmt.Sprintf("%s is on %d stops but never shows: every window with it has weight 0", st.Symbol, st.Count)})
+		}
+		if ss.SymbolTypes[id] == spec.SymbolTypeScatter && len(stacks[id]) > 0 {
+			anomalies = append(anomalies, Anomaly{Kind: ScatterStack, Symbol: st.Symbol, Stops: stacks[id], Message: fmt.Sprintf("%s can show more than once on the reel (p=%.6g, min gap %d < %d rows)", st.Symbol, st.Stacked, st.MinGap, rows)})
+		}
+	}
+	return reel, anomalies, nil
+}
@@ -0,0 +1,246 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reels
+
+import (
+	"io/fs"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/zintix-labs/problab-scaffold/internal/configs"
+	"github.com/zintix-labs/problab-scaffold/internal/prng"
+	"github.com/zintix-labs/problab/spec"
+	"gopkg.in/yaml.v3"
+)
+
+// small has a scatter that stacks on reel 0, a symbol only on a weight-0 stop window
+// on reel 1 and a reel shorter than the window.
+const small = `
+screen_setting: {columns: 3, rows: 3, damp: 1}
+gen_screen_setting:
+  gen_reel_type: GenReelByReelIdx
+  reel_set_group:
+    - weight: 1
+      reels:
+        - {symbols: [1, 3, 1, 4, 4, 2, 3], weights: [1, 2, 1, 1, 3, 1, 1]}
+        - {symbols: [3, 3, 4, 0, 4, 4, 3], weights: [1, 0, 0, 0, 0, 1, 1]}
+        - {symbols: [2, 4]}
+symbol_setting:
+  symbol_used: [Z1, C1, W1, H1, L1]
+  pay_table:
+    - [0, 0, 0]
+    - [0, 0, 0]
+    - [0, 5, 50]
+    - [0, 0, 20]
+    - [0, 2, 8]
+hit_setting:
+  bet_type: line_ltr
+  line_table:
+    - [0, 0, 0]
+`
+
+func near(a, b float64) bool { return math.Abs(a-b) <= 1e-12 }
+
+func TestInspect(t *testing.T) {
+	gms := new(spec.GameModeSetting)
+	if err := yaml.Unmarshal([]byte(small), gms); err != nil {
+		t.Fatal(err)
+	}
+	rep, err := Inspect(gms)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.ReelSets) != 1 || len(rep.ReelSets[0].Reels) != 3 {
+		t.Fatalf("report %+v", rep)
+	}
+	reels := rep.ReelSets[0].Reels
+
+	// every statistic against the windows of each stop
+	for ri, reel := range reels {
+		r := gms.GenScreenSetting.ReelSetGroup[0].Reels[ri]
+		total := 0
+		for _, w := range r.ReelWeights {
+			total += w
+		}
+		if reel.TotalWeight != total || reel.Length != len(r.ReelSymbols) {
+			t.Fatalf("reel %d: %+v", ri, reel)
+		}
+		for id, s := range reel.Symbols {
+			var count int
+			var p, visible, stacked float64
+			for stop, sym := range r.ReelSymbols {
+				if int(sym) == id {
+					count++
+					p += float64(r.ReelWeights[stop]) / float64(total)
+				}
+				k := 0
+				for row := range 3 {
+					if int(r.ReelSymbols[(stop+row)%len(r.ReelSymbols)]) == id {
+						k++
+					}
+				}
+				if k > 0 {
+					visible += float64(r.ReelWeights[stop]) / float64(total)
+				}
+				if k > 1 {
+					stacked += float64(r.ReelWeights[stop]) / float64(total)
+				}
+			}
+			if s.ID != id || s.Count != count || !near(s.P, p) || !near(s.Visible, visible) || !near(s.Stacked, stacked) {
+				t.Errorf("reel %d symbol %d: %+v, want count %d p %v visible %v stacked %v", ri, id, s, count, p, visible, stacked)
+			}
+		}
+	}
+	if g := reels[0].Symbols[1].MinGap; g != 2 {
+		t.Errorf("C1 min gap %d, want 2", g)
+	}
+	if g := reels[1].Symbols[3].MinGap; g != 1 {
+		t.Errorf("H1 min gap %d, want 1 (wrapping around)", g)
+	}
+	if g := reels[0].Symbols[2].MinGap; g != 0 {
+		t.Errorf("single W1 min gap %d, want 0", g)
+	}
+	if !reels[0].Weighted || reels[2].Weighted || !reels[0].Symbols[1].Special || reels[0].Symbols[3].Special {
+		t.Errorf("flags %+v", reels)
+	}
+
+	want := []Anomaly{
+		{Kind: ScatterStack, ReelSet: 0, Reel: 0, Symbol: "C1", Stops: []int{0}},
+		{Kind: Unreachable, ReelSet: 0, Reel: 1, Symbol: "Z1"},
+		{Kind: ShortReel, ReelSet: 0, Reel: 2},
+	}
+	if len(rep.Anomalies) != len(want) {
+		t.Fatalf("anomalies %+v", rep.Anomalies)
+	}
+	for i, a := range rep.Anomalies {
+		w := want[i]
+		if a.Kind != w.Kind || a.ReelSet != w.ReelSet || a.Reel != w.Reel || a.Symbol != w.Symbol || !slices.Equal(a.Stops, w.Stops) || a.Message == "" {
+			t.Errorf("anomaly %d: %+v, want %+v", i, a, w)
+		}
+	}
+}
+
+func TestInspectDemos(t *testing.T) {
+	for _, name := range []string{"zintix/demo_0/demo_0.yaml", "zintix/demo_1/demo_1.yaml"} {
+		raw, err := fs.ReadFile(configs.FS, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gs, err := spec.GetGameSettingByYAML(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for mode := range gs.GameModeSettings {
+			rep, err := Inspect(&gs.GameModeSettings[mode])
+			if err != nil {
+				t.Fatalf("%s mode %d: %v", name, mode, err)
+			}
+			for _, set := range rep.ReelSets {
+				for _, reel := range set.Reels {
+					var p float64
+					for _, s := range reel.Symbols {
+						p += s.P
+					}
+					if !near(p, 1) {
+						t.Fatalf("%s mode %d set %d reel %d: probabilities sum to %v", name, mode, set.Index, reel.Index, p)
+					}
+				}
+			}
+			for _, a := range rep.Anomalies {
+				// the only stacked scatter of the demos is on a reel set of weight 0
+				if a.Kind != ScatterStack || gs.GameModeSettings[mode].GenScreenSetting.ReelSetGroup[a.ReelSet].Weight != 0 {
+					t.Errorf("%s mode %d: %v", name, mode, a)
+				}
+			}
+		}
+	}
+}
+
+func TestSamplePicks(t *testing.T) {
+	const screens = 20_000
+	load := func(genType string) *spec.GameModeSetting {
+		gms := new(spec.GameModeSetting)
+		if err := yaml.Unmarshal([]byte(small), gms); err != nil {
+			t.Fatal(err)
+		}
+		gs := &gms.GenScreenSetting
+		gs.GenReelTypeStr = genType
+		second := gs.ReelSetGroup[0]
+		second.Weight = 3
+		second.Reels = slices.Clone(second.Reels)
+		second.Reels[2] = spec.Reel{ReelSymbols: []int16{4, 2, 3}, ReelWeights: []int{5, 0, 1}}
+		gs.ReelSetGroup = append(gs.ReelSetGroup, second)
+		return gms
+	}
+	judge := func(res *Picks) (deviating []PickCheck) {
+		threshold := 0.001 / float64(len(res.Checks))
+		for i := range res.Checks {
+			if res.Checks[i].Judge(threshold) {
+				deviating = append(deviating, res.Checks[i])
+			}
+		}
+		return deviating
+	}
+
+	for _, genType := range []string{"GenReelByReelIdx", "GenReelBySymbolWeight"} {
+		res, err := SamplePicks(load(genType), prng.Xoshiro256, 11, screens)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Checks) != 1+2*3 || res.Checks[0].Label() != "reel set selection" {
+			t.Fatalf("%s: checks %+v", genType, res.Checks)
+		}
+		picks := screens
+		if genType == "GenReelBySymbolWeight" {
+			picks *= 3
+		}
+		for _, c := range res.Checks[1:] {
+			if c.Picks != picks {
+				t.Fatalf("%s %s: %d picks, want %d", genType, c.Label(), c.Picks, picks)
+			}
+		}
+		if res.Checks[2].Cells != 3 || res.Checks[6].Cells != 2 {
+			t.Errorf("%s: cells of weight > 0 %+v, %+v", genType, res.Checks[2], res.Checks[6])
+		}
+		if d := judge(res); len(d) > 0 {
+			t.Errorf("%s: deviating %+v", genType, d)
+		}
+	}
+
+	// a LUT that no longer follows the weights, and one that picks a weight-0 stop
+	gms := load("GenReelByReelIdx")
+	if err := gms.GenScreenSetting.Init(); err != nil {
+		t.Fatal(err)
+	}
+	reels := gms.GenScreenSetting.ReelSetGroup[1].Reels
+	reels[0].ReelLUT = []int{0, 1, 2, 3, 4, 5, 6}
+	reels[1].ReelLUT = append(reels[1].ReelLUT, 3)
+	res, err := SamplePicks(gms, prng.Xoshiro256, 11, screens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := judge(res)
+	if len(d) != 2 || d[0].Label() != "reel set 1 reel 0" || d[1].Label() != "reel set 1 reel 1" || d[1].ZeroWeight == 0 {
+		t.Fatalf("deviating %+v", d)
+	}
+	if d[0].Worst != 4 || !near(d[0].Expected, 3.0/10) {
+		t.Errorf("worst stop %d at %v, want 4 at 0.3", d[0].Worst, d[0].Expected)
+	}
+
+	if _, err := SamplePicks(load("GenReelByReelIdx"), prng.Xoshiro256, 11, 0); err == nil {
+		t.Fatal("0 screens accepted")
+	}
+}
@@ -0,0 +1,381 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rngtest is a statistical test battery for the engine PRNG and for the range
+// mapping of reel picks (`ReelLUT.Pick(core)`), after Knuth (TAOCP vol. 2, 3.3.2) and
+// Marsaglia (Diehard):
+//
+//   - range: chi-square uniformity of LUT picks over n stops, as a reel of n stops
+//     with weight 1 is picked; lut-weighted against a weighted LUT; uintn-large on a
+//     range of 2/3 of 2^64, where a modulo mapping would be biased 2:1; float64 on
+//     100 cells of [0,1)
+//   - serial: lag-1 serial correlation coefficient of Float64
+//   - runs: lengths of runs up of Float64, the element after each run dropped so the
+//     lengths are independent
+//   - gap: gaps between Float64 draws in [0.45, 0.55)
+//   - poker: distinct values in hands of 5 draws of IntN(10)
+//   - birthday: birthday spacings of 512 birthdays in 2^24 days, on the top and the
+//     bottom 24 bits of Uint64
+//
+// Every test draws from its own generator of the same seed, so a result reproduces
+// from the seed alone. A test fails when its p-value is below Alpha or above 1-Alpha:
+// a fit too good to be random is as suspect as a bad one.
+package rngtest
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"slices"
+	"sync"
+
+	"github.com/zintix-labs/problab/sdk/core"
+	"github.com/zintix-labs/problab/sdk/sampler"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Result is the outcome of one test.
+type Result struct {
+	Test    string  `json:"test"            yaml:"test"`
+	Params  string  `json:"params"          yaml:"params"`
+	Samples int     `json:"samples"         yaml:"samples"` // draws used
+	Stat    float64 `json:"statistic"       yaml:"statistic"`
+	DF      int     `json:"df,omitempty"    yaml:"df,omitempty"` // chi-square degrees of freedom; 0 for a z statistic
+	P       float64 `json:"p_value"         yaml:"p_value"`
+	Pass    bool    `json:"pass"            yaml:"pass"`
+}
+
+// Options sizes the battery.
+type Options struct {
+	N     int     // draws per test
+	Alpha float64 // significance level of each side
+}
+
+// MinN is the smallest Options.N: every chi-square cell then expects at least 5.
+const MinN = 100_000
+
+// Ranges are the stop counts of the range test.
+var Ranges = []int{2, 3, 6, 10, 37, 100, 1000, 4096, 10_007}
+
+// Run runs the battery on generators of f seeded with seed, the tests in parallel.
+func Run(f core.PRNGFactory, seed int64, opt Options) ([]Result, error) {
+	if opt.N < MinN {
+		return nil, fmt.Errorf("rngtest: %d draws per test, need at least %d", opt.N, MinN)
+	}
+	if !(opt.Alpha > 0 && opt.Alpha < 0.5) {
+		return nil, errors.New("rngtest: alpha must be in (0, 0.5)")
+	}
+	var tests []func(c *core.Core, n int) Result
+	for _, r := range Ranges {
+		tests = append(tests, func(c *core.Core, n int) Result { return rangeTest(c, n, r) })
+	}
+	tests = append(tests, lutWeighted, uintNLarge, float64Cells, serial, runsUp, gap, poker,
+		func(c *core.Core, n int) Result { return birthday(c, n, 40) },
+		func(c *core.Core, n int) Result { return birthday(c, n, 0) },
+	)
+	res := make([]Result, len(tests))
+	var wg sync.WaitGroup
+	for i, t := range tests {
+		wg.Go(func() {
+			res[i] = t(core.New(f.New(seed)), opt.N)
+			res[i].Pass = res[i].P >= opt.Alpha && res[i].P <= 1-opt.Alpha
+		})
+	}
+	wg.Wait()
+	return res, nil
+}
+
+// rangeTest picks n times from a LUT of k stops of weight 1.
+func rangeTest(c *core.Core, n, k int) Result {
+	lut := sampler.BuildLUT(slices.Repeat([]int{1}, k))
+	obs := make([]int, k)
+	for range n {
+		obs[lut.Pick(c)]++
+	}
+	exp := slices.Repeat([]float64{float64(n) / float64(k)}, k)
+	return chiSquare("range", fmt.Sprintf("LUT.Pick over %d stops", k), n, obs, exp)
+}
+
+// lutWeighted picks from a LUT of weights 1..10, like a weighted reel.
+func lutWeighted(c *core.Core, n int) Result {
+	weights := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	lut := sampler.BuildLUT(weights)
+	obs := make([]int, len(weights))
+	for range n {
+		obs[lut.Pick(c)]++
+	}
+	exp := make([]float64, len(weights))
+	for i, w := range weights {
+		exp[i] = float64(n) * float64(w) / float64(len(lut))
+	}
+	return chiSquare("lut-weighted", "LUT.Pick, weights 1..10", n, obs, exp)
+}
+
+// uintNLarge draws UintN(m), m = 2/3 of 2^64, into 6 cells of [0,m). Reducing a
+// uint64 modulo m would make [0, 2^64-m), half of the range, twice as likely.
+func uintNLarge(c *core.Core, n int) Result {
+	const m = 0xaaaaaaaaaaaaaaab
+	const cells = 6
+	obs := make([]int, cells)
+	for range n {
+		v := uint64(c.UintN(m))
+		hi, lo := bits.Mul64(v, cells)
+		q, _ := bits.Div64(hi, lo, m)
+		obs[q]++
+	}
+	exp := slices.Repeat([]float64{float64(n) / cells}, cells)
+	return chiSquare("uintn-large", "UintN(2^64*2/3), 6 cells", n, obs, exp)
+}
+
+// float64Cells counts Float64 in 100 equal cells of [0,1).
+func float64Cells(c *core.Core, n int) Result {
+	const cells = 100
+	obs := make([]int, cells)
+	for range n {
+		obs[int(c.Float64()*cells)]++
+	}
+	exp := slices.Repeat([]float64{float64(n) / cells}, cells)
+	return chiSquare("float64", "100 cells of [0,1)", n, obs, exp)
+}
+
+// serial is Knuth's serial correlation test (3.3.2 K) at lag 1, the sequence taken as
+// circular; C is about normal with mean -1/(n-1) and deviation √(n(n-3)/(n+1))/(n-1).
+func serial(c *core.Core, n int) Result {
+	first := c.Float64()
+	prev := first
+	sum, sq, cross := first, first*first, 0.0
+	for range n - 1 {
+		u := c.Float64()
+		sum += u
+		sq += u * u
+		cross += prev * u
+		prev = u
+	}
+	cross += prev * first
+	fn := float64(n)
+	corr := (fn*cross - sum*sum) / (fn*sq - sum*sum)
+	mu := -1 / (fn - 1)
+	sigma := math.Sqrt(fn*(fn-3)/(fn+1)) / (fn - 1)
+	return zTest("serial", "lag-1 correlation of Float64", n, (corr-mu)/sigma)
+}
+
+// runsUp counts the lengths of runs up (3.3.2 G), dropping the draw after each run:
+// a run has length r with probability r/(r+1)!; lengths of 6 and more share a cell.
+func runsUp(c *core.Core, n int) Result {
+	const cells = 6
+	obs := make([]int, cells)
+	used := 0
+	for used < n {
+		prev, r := c.Float64(), 1
+		used++
+		for used < n {
+			u := c.Float64()
+			used++
+			if u <= prev {
+				break // u ends the run and is dropped
+			}
+			prev, r = u, r+1
+		}
+		obs[min(r, cells)-1]++
+	}
+	runs := 0
+	for _, o := range obs {
+		runs += o
+	}
+	exp := make([]float64, cells)
+	fact := 1.0 // (r+1)!
+	for r := 1; r < cells; r++ {
+		fact *= float64(r + 1)
+		exp[r-1] = float64(runs) * float64(r) / fact
+	}
+	exp[cells-1] = float64(runs) / fact // 1/6!
+	return chiSquare("runs", "runs up of Float64", n, obs, exp)
+}
+
+// gap is the gap test (3.3.2 C) on [0.45, 0.55): a gap has length r with probability
+// p(1-p)^r; lengths of 30 and more share a cell.
+func gap(c *core.Core, n int) Result {
+	const lo, hi, t = 0.45, 0.55, 30
+	p := hi - lo
+	obs := make([]int, t+1)
+	r := 0
+	for range n {
+		if u := c.Float64(); u >= lo && u < hi {
+			obs[min(r, t)]++
+			r = 0
+		} else {
+			r++
+		}
+	}
+	gaps := 0
+	for _, o := range obs {
+		gaps += o
+	}
+	exp := make([]float64, t+1)
+	for k := range t {
+		exp[k] = float64(gaps) * p * math.Pow(1-p, float64(k))
+	}
+	exp[t] = float64(gaps) * math.Pow(1-p, t)
+	return chiSquare("gap", "gaps of Float64 in [0.45,0.55)", n, obs, exp)
+}
+
+// poker is the partition test (3.3.2 D): a hand of k draws of IntN(d) has r distinct
+// values with probability d(d-1)...(d-r+1)/d^k · S(k,r).
+func poker(c *core.Core, n int) Result {
+	const k, d = 5, 10
+	hands := n / k
+	obs := make([]int, k+1)
+	for range hands {
+		var seen [d]bool
+		r := 0
+		for range k {
+			v := c.IntN(d)
+			if !seen[v] {
+				seen[v] = true
+				r++
+			}
+		}
+		obs[r]++
+	}
+	// S(k,r) by S(i,j) = j·S(i-1,j) + S(i-1,j-1)
+	s := make([]float64, k+1)
+	s[0] = 1
+	for i := 1; i <= k; i++ {
+		for j := i; j >= 1; j-- {
+			s[j] = float64(j)*s[j] + s[j-1]
+		}
+		s[0] = 0
+	}
+	exp := make([]float64, k+1)
+	falling := 1.0
+	for r := 1; r <= k; r++ {
+		falling *= float64(d - r + 1)
+		exp[r] = float64(hands) * falling / math.Pow(d, k) * s[r]
+	}
+	return chiSquare("poker", "hands of 5 IntN(10)", hands*k, obs[1:], exp[1:])
+}
+
+// birthday is Marsaglia's birthday spacings test: m = 512 birthdays, 24 bits of Uint64
+// from bit shift, in 2^24 days. The number of repeated values among the sorted
+// spacings is about Poisson with mean m³/(4·2^24) = 2.
+func birthday(c *core.Core, n int, shift uint) Result {
+	const m, days = 512, 1 << 24
+	const lambda = float64(m) * m * m / (4 * days)
+	reps := n / m
+	counts := map[int]int{}
+	b := make([]uint32, m)
+	for range reps {
+		for i := range b {
+			b[i] = uint32(c.Uint64()>>shift) & (days - 1)
+		}
+		slices.Sort(b)
+		for i := m - 1; i > 0; i-- {
+			b[i] -= b[i-1]
+		}
+		slices.Sort(b)
+		j := 0
+		for i := 1; i < m; i++ {
+			if b[i] == b[i-1] {
+				j++
+			}
+		}
+		counts[j]++
+	}
+	// cells 0..K-1 and K or more, K the first count expected less than 5 times
+	var obs []int
+	var exp []float64
+	pk, cum := math.Exp(-lambda), 0.0
+	for k := 0; ; k++ {
+		if float64(reps)*(1-cum-pk) < 5 {
+			tail := 0
+			for j, o := range counts {
+				if j >= k {
+					tail += o
+				}
+			}
+			obs, exp = append(obs, tail), append(exp, float64(reps)*(1-cum))
+			break
+		}
+		obs, exp = append(obs, counts[k]), append(exp, float64(reps)*pk)
+		cum += pk
+		pk *= lambda / float64(k+1)
+	}
+	return chiSquare("birthday", fmt.Sprintf("spacings, bits %d-%d of Uint64", shift, shift+23), reps*m, obs, exp)
+}
+
+// chiSquare is ChiSquare as a Result.
+func chiSquare(test, params string, samples int, obs []int, exp []float64) Result {
+	stat, df, p := ChiSquare(obs, exp)
+	return Result{Test: test, Params: params, Samples: samples, Stat: stat, DF: df, P: p}
+}
+
+// ChiSquare is Pearson's chi-square test of observed against expected counts. Cells
+// expecting less than 5 are merged into their neighbour first; with a single cell left
+// the p-value is 1.
+func ChiSquare(obs []int, exp []float64) (stat float64, df int, p float64) {
+	var o []int
+	var e []float64
+	for i := range obs {
+		if k := len(e) - 1; k >= 0 && e[k] < 5 {
+			o[k] += obs[i]
+			e[k] += exp[i]
+			continue
+		}
+		o, e = append(o, obs[i]), append(e, exp[i])
+	}
+	if k := len(e) - 1; k > 0 && e[k] < 5 {
+		o[k-1] += o[k]
+		e[k-1] += e[k]
+		o, e = o[:k], e[:k]
+	}
+	for i := range o {
+		d := float64(o[i]) - e[i]
+		stat += d * d / e[i]
+	}
+	if df = len(o) - 1; df < 1 {
+		return stat, 0, 1
+	}
+	return stat, df, distuv.ChiSquared{K: float64(df)}.Survival(stat)
+}
+
+// zTest is a two-sided test of a standard normal statistic z.
+func zTest(test, params string, samples int, z float64) Result {
+	return Result{Test: test, Params: params, Samples: samples, Stat: z, P: 2 * distuv.UnitNormal.Survival(math.Abs(z))}
+}
+
+// Dump writes size bytes of the raw Uint64 output of p, little-endian, to w: the binary
+// input of external suites (PractRand `RNG_test stdin64`, TestU01, dieharder -g 201).
+// With size <= 0 it writes until w fails. It returns the bytes written.
+func Dump(w io.Writer, p core.RAND, size int64) (int64, error) {
+	buf := make([]byte, 0, 1<<16)
+	var done int64
+	for size <= 0 || done < size {
+		buf = buf[:0]
+		for len(buf) < cap(buf) {
+			buf = binary.LittleEndian.AppendUint64(buf, p.Uint64())
+		}
+		if size > 0 && int64(len(buf)) > size-done {
+			buf = buf[:size-done]
+		}
+		n, err := w.Write(buf)
+		done += int64(n)
+		if err != nil {
+			return done, err
+		}
+	}
+	return done, nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rngtest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/zintix-labs/problab-scaffold/internal/prng"
+	"github.com/zintix-labs/problab/sdk/core"
+)
+
+// lcg is a bad generator: the raw state of a 64-bit LCG, whose low bits have short
+// periods, mapped to ranges by modulo.
+type lcg struct{ x uint64 }
+
+func (l *lcg) Uint64() uint64 {
+	l.x = l.x*6364136223846793005 + 1442695040888963407
+	return l.x
+}
+func (l *lcg) UintN(n uint) uint         { return uint(l.Uint64() % uint64(n)) }
+func (l *lcg) IntN(n int) int            { return int(l.Uint64() % uint64(n)) }
+func (l *lcg) Float64() float64          { return float64(l.Uint64()>>11) / (1 << 53) }
+func (l *lcg) Snapshot() ([]byte, error) { return nil, nil }
+func (l *lcg) Restore(data []byte) error { return nil }
+
+type lcgFactory struct{}
+
+func (lcgFactory) New(seed int64) core.PRNG { return &lcg{x: uint64(seed)} }
+
+func TestRun(t *testing.T) {
+	opt := Options{N: MinN, Alpha: 0.001}
+	for _, f := range prng.All() {
+		res, err := Run(f, 7, opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res) != len(Ranges)+9 {
+			t.Fatalf("%s: %d results", f.Name(), len(res))
+		}
+		for _, r := range res {
+			if !r.Pass {
+				t.Errorf("%s: %s (%s) failed: statistic %g, p %g", f.Name(), r.Test, r.Params, r.Stat, r.P)
+			}
+		}
+	}
+
+	res, err := Run(lcgFactory{}, 7, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed := map[string]bool{}
+	for _, r := range res {
+		if !r.Pass {
+			failed[r.Test+" "+r.Params] = true
+		}
+	}
+	for _, want := range []string{"uintn-large UintN(2^64*2/3), 6 cells", "birthday spacings, bits 0-23 of Uint64", "range LUT.Pick over 4096 stops"} {
+		if !failed[want] {
+			t.Errorf("the LCG passed %s", want)
+		}
+	}
+
+	if _, err := Run(lcgFactory{}, 7, Options{N: MinN - 1, Alpha: 0.001}); err == nil {
+		t.Fatal("N below MinN accepted")
+	}
+	if _, err := Run(lcgFactory{}, 7, Options{N: MinN, Alpha: 0.5}); err == nil {
+		t.Fatal("alpha 0.5 accepted")
+	}
+}
+
+func TestDump(t *testing.T) {
+	var b bytes.Buffer
+	n, err := Dump(&b, prng.Xoshiro256.New(3), 1<<16+3)
+	if err != nil || n != 1<<16+3 || b.Len() != 1<<16+3 {
+		t.Fatalf("Dump = %d, %v; %d bytes", n, err, b.Len())
+	}
+	p := prng.Xoshiro256.New(3)
+	for i := 0; i+8 <= b.Len(); i += 8 {
+		if binary.LittleEndian.Uint64(b.Bytes()[i:]) != p.Uint64() {
+			t.Fatalf("word %d is not the stream", i/8)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Collector accumulates finished sessions. It is not safe for concurrent use; give
+// each worker its own and Merge them.
+type Collector struct {
+	lengths []int
+	nets    []float64
+	ends    [numEnds]int
+	winning int
+	spins   int
+	staked  int
+	won     int
+}
+
+// Add records one finished session.
+func (c *Collector) Add(p *Session) {
+	c.lengths = append(c.lengths, p.Spins)
+	c.nets = append(c.nets, p.Net())
+	c.ends[p.End]++
+	if p.Balance > p.BuyIn {
+		c.winning++
+	}
+	c.spins += p.Spins
+	c.staked += p.Staked
+	c.won += p.Won
+}
+
+// Merge adds every session of o into c.
+func (c *Collector) Merge(o *Collector) {
+	c.lengths = append(c.lengths, o.lengths...)
+	c.nets = append(c.nets, o.nets...)
+	for i := range c.ends {
+		c.ends[i] += o.ends[i]
+	}
+	c.winning += o.winning
+	c.spins += o.spins
+	c.staked += o.staked
+	c.won += o.won
+}
+
+// Report is the session-level player experience of one strategy.
+type Report struct {
+	Strategy    string     `json:"strategy"         yaml:"strategy"`
+	Sessions    int        `json:"sessions"         yaml:"sessions"`
+	BuyIn       int        `json:"buy_in"           yaml:"buy_in"` // credits
+	Winning     float64    `json:"winning_sessions" yaml:"winning_sessions"`
+	RTP         float64    `json:"rtp"              yaml:"rtp"` // total paid / total staked over all sessions
+	AvgStakeX   float64    `json:"avg_stake_x"      yaml:"avg_stake_x"`
+	Ends        []EndShare `json:"ends"             yaml:"ends"`
+	Net         []Quantile `json:"net_buyins"       yaml:"net_buyins"` // session result in buy-ins
+	Length      []Quantile `json:"length_spins"     yaml:"length_spins"`
+	MeanLength  float64    `json:"mean_length"      yaml:"mean_length"`
+	MeanMinutes float64    `json:"mean_minutes"     yaml:"mean_minutes"`
+	LengthDist  []Bin      `json:"length_dist"      yaml:"length_dist"`
+	Survival    []Survival `json:"survival"         yaml:"survival"`
+}
+
+// EndShare is the number of sessions that stopped for one reason.
+type EndShare struct {
+	End      string  `json:"end"      yaml:"end"`
+	Sessions int     `json:"sessions" yaml:"sessions"`
+	Share    float64 `json:"share"    yaml:"share"`
+}
+
+// Quantile is one percentile of a per-session value.
+type Quantile struct {
+	P     float64 `json:"p"     yaml:"p"`
+	Value float64 `json:"value" yaml:"value"`
+}
+
+// Bin is the share of sessions whose length is in [Lo, Hi] spins.
+type Bin struct {
+	Lo       int     `json:"lo"       yaml:"lo"`
+	Hi       int     `json:"hi"       yaml:"hi"`
+	Sessions int     `json:"sessions" yaml:"sessions"`
+	Share    float64 `json:"share"    yaml:"share"`
+}
+
+// Survival is the share of sessions still playing at spin Spins (i.e. lasting at least
+// that many spins).
+type Survival struct {
+	Spins   int     `json:"spins"   yaml:"spins"`
+	Minutes float64 `json:"minutes" yaml:"minutes"`
+	Alive   float64 `json:"alive"   yaml:"alive"`
+}
+
+var quantiles = []float64{0.1, 0.25, 0.5, 0.75, 0.9}
+
+// Report summarizes the collected sessions. baseUnit is the bet unit of the base mode and
+// limit the session spin limit given to New.
+func (c *Collector) Report(s *Strategy, buyIn int, baseUnit int, limit int) *Report {
+	n := len(c.lengths)
+	r := &Report{Strategy: s.String(), Sessions: n, BuyIn: buyIn}
+	if n == 0 {
+		return r
+	}
+	share := func(k int) float64 { return float64(k) / float64(n) }
+	r.Winning = share(c.winning)
+	if c.staked > 0 {
+		r.RTP = float64(c.won) / float64(c.staked)
+	}
+	if c.spins > 0 {
+		r.AvgStakeX = float64(c.staked) / float64(c.spins) / float64(baseUnit)
+	}
+	for e, k := range c.ends {
+		r.Ends = append(r.Ends, EndShare{End: End(e).String(), Sessions: k, Share: share(k)})
+	}
+
+	lengths := slices.Clone(c.lengths)
+	slices.Sort(lengths)
+	nets := slices.Clone(c.nets)
+	slices.Sort(nets)
+	for _, q := range quantiles {
+		i := min(int(math.Ceil(q*float64(n)))-1, n-1)
+		r.Length = append(r.Length, Quantile{P: q, Value: float64(lengths[max(i, 0)])})
+		r.Net = append(r.Net, Quantile{P: q, Value: nets[max(i, 0)]})
+	}
+	r.MeanLength = float64(c.spins) / float64(n)
+	r.MeanMinutes = r.MeanLength * s.spinSeconds() / 60
+
+	// lasting returns the number of sessions with at least t spins
+	lasting := func(t int) int {
+		i, _ := slices.BinarySearch(lengths, t)
+		return n - i
+	}
+	limit = s.maxSpins(limit)
+	grid := Grid(limit)
+	for _, t := range grid {
+		r.Survival = append(r.Survival, Survival{Spins: t, Minutes: float64(t) * s.spinSeconds() / 60, Alive: share(lasting(t))})
+	}
+	lo := 1
+	for _, t := range grid[1:] {
+		k := lasting(lo) - lasting(t)
+		r.LengthDist = append(r.LengthDist, Bin{Lo: lo, Hi: t - 1, Sessions: k, Share: share(k)})
+		lo = t
+	}
+	k := lasting(lo)
+	r.LengthDist = append(r.LengthDist, Bin{Lo: lo, Hi: limit, Sessions: k, Share: share(k)})
+	return r
+}
+
+// Grid returns the spin counts at which survival is reported: 1, 10, 20, 50, 100, ...
+// below limit, then limit itself.
+func Grid(limit int) []int {
+	g := []int{1}
+	for base := 10; base < limit; base *= 10 {
+		for _, m := range []int{1, 2, 5} {
+			if t := base * m; t < limit {
+				g = append(g, t)
+			}
+		}
+	}
+	if limit > 1 {
+		g = append(g, limit)
+	}
+	return g
+}
+
+// Label formats a length bin, e.g. "[10, 19]".
+func (b Bin) Label() string {
+	if b.Lo == b.Hi {
+		return fmt.Sprint(b.Lo)
+	}
+	return fmt.Sprintf("[%d, %d]", b.Lo, b.Hi)
+}
@@ -0,0 +1,156 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import "math"
+
+// End is the reason a session stopped.
+type End int
+
+const (
+	EndLimit    End = iota // reached the spin or time limit with money left
+	EndBust                // cannot afford the base bet any more
+	EndStopWin             // balance reached stop_win
+	EndStopLoss            // loss reached stop_loss
+	numEnds
+)
+
+var endNames = [numEnds]string{"limit", "bust", "stop_win", "stop_loss"}
+
+func (e End) String() string { return endNames[e] }
+
+// Session is one player playing one strategy. Call Next for the bet of the coming spin,
+// spin the machine, then Settle with the spin's TotalWin, until Next reports the end.
+//
+// Machine results are produced at bet multiplier 1; a spin at multiplier k stakes and
+// pays k times as much.
+type Session struct {
+	s     *Strategy
+	units []int
+	base  int // base bet mode
+	limit int
+
+	BuyIn      int
+	Balance    int
+	MaxBalance int
+	MinBalance int
+	Spins      int
+	Staked     int
+	Won        int
+	ModeSpins  []int // spins per bet mode
+	End        End
+
+	mult int // multiplier of the next spin before the affordability cap
+	mode int // pending spin
+	bet  int
+	done bool
+}
+
+// New starts a session with buyIn credits on bet mode baseMode. limit caps the session
+// length in spins on top of the strategy's own limits.
+func New(s *Strategy, betUnits []int, baseMode int, buyIn int, limit int) *Session {
+	p := &Session{
+		s:          s,
+		units:      betUnits,
+		base:       baseMode,
+		limit:      s.maxSpins(limit),
+		BuyIn:      buyIn,
+		Balance:    buyIn,
+		MaxBalance: buyIn,
+		MinBalance: buyIn,
+		ModeSpins:  make([]int, len(betUnits)),
+		mult:       s.startMult(),
+	}
+	if buyIn < betUnits[baseMode] {
+		p.End, p.done = EndBust, true
+	}
+	return p
+}
+
+// Next returns the bet mode and multiplier of the coming spin. ok is false once the
+// session has ended.
+func (p *Session) Next() (mode int, mult int, ok bool) {
+	if p.done {
+		return 0, 0, false
+	}
+	mode = p.base
+	for _, m := range p.s.ModeSwitch {
+		if p.within(m) && p.units[m.Mode] <= p.Balance {
+			mode = m.Mode
+			break
+		}
+	}
+	mult = min(p.mult, p.Balance/p.units[mode])
+	p.mode, p.bet = mode, mult
+	return mode, mult, true
+}
+
+// Settle books the spin returned by Next. win is the spin's TotalWin at multiplier 1.
+func (p *Session) Settle(win int) {
+	stake := p.units[p.mode] * p.bet
+	pay := win * p.bet
+	p.Balance += pay - stake
+	p.Spins++
+	p.Staked += stake
+	p.Won += pay
+	p.ModeSpins[p.mode]++
+	p.MaxBalance = max(p.MaxBalance, p.Balance)
+	p.MinBalance = min(p.MinBalance, p.Balance)
+
+	if pay > stake {
+		p.mult = p.s.nextMult(p.bet, p.s.Bet.OnWin)
+	} else {
+		p.mult = p.s.nextMult(p.bet, p.s.Bet.OnLoss)
+	}
+
+	switch {
+	case p.s.StopWin > 0 && float64(p.Balance) >= p.s.StopWin*float64(p.BuyIn):
+		p.End, p.done = EndStopWin, true
+	case p.Balance < p.units[p.base]:
+		p.End, p.done = EndBust, true
+	case p.s.StopLoss > 0 && float64(p.BuyIn-p.Balance) >= p.s.StopLoss*float64(p.BuyIn):
+		p.End, p.done = EndStopLoss, true
+	case p.Spins >= p.limit:
+		p.End, p.done = EndLimit, true
+	}
+}
+
+// Done reports whether the session has ended.
+func (p *Session) Done() bool { return p.done }
+
+// Net is the session result in buy-ins (0 = broke even, -1 = lost everything).
+func (p *Session) Net() float64 {
+	return float64(p.Balance-p.BuyIn) / float64(p.BuyIn)
+}
+
+func (p *Session) within(m ModeSwitch) bool {
+	b := float64(p.Balance)
+	buy := float64(p.BuyIn)
+	return (m.Above == 0 || b >= m.Above*buy) && (m.Below == 0 || b < m.Below*buy)
+}
+
+func (s *Strategy) startMult() int { return max(s.Bet.Mult, 1) }
+
+// nextMult applies one bet factor: 0 resets to the starting multiplier.
+func (s *Strategy) nextMult(cur int, factor float64) int {
+	if factor == 0 {
+		return s.startMult()
+	}
+	n := max(int(math.Round(float64(cur)*factor)), 1)
+	if s.Bet.MaxMult > 0 {
+		n = min(n, s.Bet.MaxMult)
+	}
+	return n
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// play runs a session against a fixed list of per-spin wins (at multiplier 1) and
+// returns the multipliers that were bet.
+func play(p *Session, wins []int) []int {
+	var mults []int
+	for _, w := range wins {
+		_, mult, ok := p.Next()
+		if !ok {
+			break
+		}
+		mults = append(mults, mult)
+		p.Settle(w)
+	}
+	return mults
+}
+
+func TestMartingale(t *testing.T) {
+	s := &Strategy{StopWin: 2, Bet: BetRule{OnLoss: 2, MaxMult: 4}}
+	p := New(s, []int{10}, 0, 1000, 1000)
+	mults := play(p, []int{0, 0, 0, 0, 30, 0})
+	if want := []int{1, 2, 4, 4, 4, 1}; !slices.Equal(mults, want) {
+		t.Fatalf("multipliers %v, want %v", mults, want)
+	}
+	if p.Balance != 1000-10-20-40-40-40+120-10 || p.Done() {
+		t.Fatalf("balance %d done %v", p.Balance, p.Done())
+	}
+
+	// the bet is capped by what the balance can still afford
+	p = New(s, []int{10}, 0, 100, 1000)
+	if mults = play(p, []int{0, 0, 0, 0}); !slices.Equal(mults, []int{1, 2, 4, 3}) || p.End != EndBust {
+		t.Fatalf("multipliers %v end %v, want [1 2 4 3] and bust", mults, p.End)
+	}
+}
+
+func TestSessionEnds(t *testing.T) {
+	cases := []struct {
+		name string
+		s    Strategy
+		wins []int
+		end  End
+		n    int
+	}{
+		{"bust", Strategy{}, []int{0, 0, 0, 0, 0, 0}, EndBust, 5},
+		{"stop-win", Strategy{StopWin: 2}, []int{0, 120, 0}, EndStopWin, 2},
+		{"stop-loss", Strategy{StopLoss: 0.3}, []int{0, 0, 0, 0}, EndStopLoss, 2},
+		{"limit", Strategy{MaxSpins: 2}, []int{10, 10, 10}, EndLimit, 2},
+		{"minutes", Strategy{MaxMinutes: 0.1, SpinSeconds: 2}, []int{10, 10, 10, 10}, EndLimit, 3},
+	}
+	for _, c := range cases {
+		p := New(&c.s, []int{10}, 0, 50, 100)
+		play(p, c.wins)
+		if !p.Done() || p.End != c.end || p.Spins != c.n {
+			t.Fatalf("%s: done %v end %v after %d spins, want %v after %d", c.name, p.Done(), p.End, p.Spins, c.end, c.n)
+		}
+	}
+}
+
+func TestModeSwitch(t *testing.T) {
+	s := &Strategy{ModeSwitch: []ModeSwitch{{Mode: 1, Above: 1.5}}}
+	p := New(s, []int{10, 100}, 0, 100, 100)
+	var modes []int
+	for _, w := range []int{60, 0, 0} {
+		mode, _, _ := p.Next()
+		modes = append(modes, mode)
+		p.Settle(w)
+	}
+	// 100 -> 150 (base) -> 50 (buy at 150) -> 40 (base again)
+	if want := []int{0, 1, 0}; !slices.Equal(modes, want) || p.Balance != 40 || p.ModeSpins[1] != 1 {
+		t.Fatalf("modes %v balance %d, want %v and 40", modes, p.Balance, want)
+	}
+	if err := s.Check([]int{10}); err == nil {
+		t.Fatal("Check should reject a bet mode the game does not have")
+	}
+}
+
+func TestReport(t *testing.T) {
+	s := &Strategy{}
+	c, o := new(Collector), new(Collector)
+	for _, n := range []int{1, 5, 10, 25, 100} {
+		p := New(&Strategy{MaxSpins: n}, []int{10}, 0, 1000, 100)
+		play(p, make([]int, n))
+		c.Add(p)
+	}
+	p := New(s, []int{10}, 0, 1000, 100)
+	play(p, []int{2000})
+	o.Add(p)
+	c.Merge(o)
+
+	r := c.Report(s, 1000, 10, 100)
+	if r.Sessions != 6 || r.Winning != 1.0/6 {
+		t.Fatalf("sessions %d winning %v", r.Sessions, r.Winning)
+	}
+	if got := Grid(100); !slices.Equal(got, []int{1, 10, 20, 50, 100}) {
+		t.Fatalf("Grid(100) = %v", got)
+	}
+	alive := make([]float64, len(r.Survival))
+	for i, s := range r.Survival {
+		alive[i] = s.Alive
+	}
+	if want := []float64{1, 3.0 / 6, 2.0 / 6, 1.0 / 6, 1.0 / 6}; !slices.Equal(alive, want) {
+		t.Fatalf("survival %v, want %v", alive, want)
+	}
+	total := 0
+	for _, b := range r.LengthDist {
+		total += b.Sessions
+	}
+	if total != r.Sessions {
+		t.Fatalf("length bins hold %d sessions, want %d", total, r.Sessions)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	if s, err := Load(DefaultStrategy); err != nil || s.StopWin != 3 {
+		t.Fatalf("default preset: %+v, %v", s, err)
+	}
+	if _, err := Load("no-such-preset"); err == nil {
+		t.Fatal("unknown preset should fail")
+	}
+
+	dir := t.TempDir()
+	good := filepath.Join(dir, "chase.yaml")
+	os.WriteFile(good, []byte("stop_win: 2\nbet:\n  on_loss: 2\n  max_mult: 8\n"), 0o644)
+	s, err := Load(good)
+	if err != nil || s.Name != "chase" || s.Bet.OnLoss != 2 {
+		t.Fatalf("Load(%s) = %+v, %v", good, s, err)
+	}
+	bad := filepath.Join(dir, "typo.json")
+	os.WriteFile(bad, []byte(`{"stopwin": 2}`), 0o644)
+	if _, err := Load(bad); err == nil {
+		t.Fatal("unknown field should fail")
+	}
+}
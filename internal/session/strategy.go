@@ -0,0 +1,228 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package session models player sessions on top of machine spins.
+//
+// Upstream `SimPlayers` plays a flat bet until the player busts or reaches 3x the
+// buy-in. A Strategy describes how a player actually plays instead: when to leave
+// (stop-win, stop-loss, session length), how the bet changes after wins and losses,
+// and when to switch bet mode (e.g. buy feature). Session applies a strategy to a
+// stream of spin results; Report aggregates finished sessions.
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultStrategy is the preset used when none is given. It keeps the upstream
+// SimPlayers rules: flat bet, leave at 3x the buy-in or when broke.
+const DefaultStrategy = "cashout"
+
+// DefaultSpinTime is the assumed wall time of one spin when a strategy limits the
+// session by duration but does not set spin_seconds.
+const DefaultSpinTime = 3 * time.Second
+
+// Strategy is one way of playing a session. Amounts are relative to the buy-in, so a
+// strategy applies to any game and starting balance.
+//
+// Example strategy file:
+//
+//	name: chase-and-buy
+//	stop_win: 2          # leave at 2x the buy-in
+//	stop_loss: 0.5       # leave after losing half the buy-in
+//	max_minutes: 60      # one hour at spin_seconds per spin
+//	spin_seconds: 3
+//	bet:
+//	  on_loss: 2         # double the bet after a losing spin (martingale)
+//	  max_mult: 32
+//	mode_switch:
+//	  - mode: 1          # buy feature while at least 1.5x ahead
+//	    above: 1.5
+type Strategy struct {
+	Name        string       `yaml:"name"         json:"name"`
+	StopWin     float64      `yaml:"stop_win"     json:"stop_win"`     // leave when balance >= StopWin x buy-in; 0 never
+	StopLoss    float64      `yaml:"stop_loss"    json:"stop_loss"`    // leave after losing StopLoss x buy-in, in (0,1]; 0 play until broke
+	MaxSpins    int          `yaml:"max_spins"    json:"max_spins"`    // 0: only the -spins limit
+	MaxMinutes  float64      `yaml:"max_minutes"  json:"max_minutes"`  // 0: no time limit
+	SpinSeconds float64      `yaml:"spin_seconds" json:"spin_seconds"` // wall time per spin for MaxMinutes; 0 means DefaultSpinTime
+	Bet         BetRule      `yaml:"bet"          json:"bet"`
+	ModeSwitch  []ModeSwitch `yaml:"mode_switch"  json:"mode_switch"`
+}
+
+// BetRule changes the bet multiplier between spins. A spin is won when it pays more
+// than its stake. Factors multiply the current multiplier; 0 (or omitted) resets it to
+// Mult and 1 keeps it. The multiplier is capped by MaxMult and by what the balance can
+// still afford.
+type BetRule struct {
+	Mult    int     `yaml:"mult"     json:"mult"` // starting multiplier; 0 means 1
+	OnWin   float64 `yaml:"on_win"   json:"on_win"`
+	OnLoss  float64 `yaml:"on_loss"  json:"on_loss"`
+	MaxMult int     `yaml:"max_mult" json:"max_mult"` // 0: no cap
+}
+
+// ModeSwitch plays bet mode Mode while the balance is at least Above x buy-in (when
+// set) and below Below x buy-in (when set). The first matching rule wins; otherwise
+// the session's base mode is played.
+type ModeSwitch struct {
+	Mode  int     `yaml:"mode"  json:"mode"`
+	Above float64 `yaml:"above" json:"above"`
+	Below float64 `yaml:"below" json:"below"`
+}
+
+// presets are the strategies selectable by name.
+var presets = map[string]Strategy{
+	"flat":       {Name: "flat"},
+	"cashout":    {Name: "cashout", StopWin: 3},
+	"stop-loss":  {Name: "stop-loss", StopWin: 2, StopLoss: 0.5},
+	"martingale": {Name: "martingale", StopWin: 2, Bet: BetRule{OnLoss: 2, MaxMult: 32}},
+	"paroli":     {Name: "paroli", StopWin: 3, Bet: BetRule{OnWin: 2, MaxMult: 8}},
+	"hour":       {Name: "hour", StopWin: 3, MaxMinutes: 60},
+}
+
+// Presets returns the names of the built-in strategies.
+func Presets() []string {
+	names := make([]string, 0, len(presets))
+	for n := range presets {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load returns the preset called spec, or reads spec as a strategy file (.json, or YAML
+// otherwise). The strategy is validated but not yet checked against a game; see Check.
+func Load(spec string) (*Strategy, error) {
+	if p, ok := presets[spec]; ok {
+		return &p, nil
+	}
+	raw, err := os.ReadFile(spec)
+	if err != nil {
+		if os.IsNotExist(err) && filepath.Ext(spec) == "" {
+			return nil, fmt.Errorf("unknown strategy %q (presets: %s, or a .yaml/.json file)", spec, strings.Join(Presets(), ", "))
+		}
+		return nil, err
+	}
+	s := new(Strategy)
+	if strings.EqualFold(filepath.Ext(spec), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(s)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(raw))
+		dec.KnownFields(true)
+		err = dec.Decode(s)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read strategy %s: %w", spec, err)
+	}
+	if s.Name == "" {
+		s.Name = strings.TrimSuffix(filepath.Base(spec), filepath.Ext(spec))
+	}
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("strategy %s: %w", spec, err)
+	}
+	return s, nil
+}
+
+// Validate checks the strategy on its own.
+func (s *Strategy) Validate() error {
+	switch {
+	case s.StopWin != 0 && s.StopWin <= 1:
+		return fmt.Errorf("stop_win must be > 1 (x buy-in) or 0")
+	case s.StopLoss < 0 || s.StopLoss > 1:
+		return fmt.Errorf("stop_loss must be in (0,1] or 0")
+	case s.MaxSpins < 0 || s.MaxMinutes < 0 || s.SpinSeconds < 0:
+		return fmt.Errorf("max_spins, max_minutes and spin_seconds must be >= 0")
+	case s.Bet.Mult < 0 || s.Bet.MaxMult < 0 || s.Bet.OnWin < 0 || s.Bet.OnLoss < 0:
+		return fmt.Errorf("bet values must be >= 0")
+	case s.Bet.MaxMult > 0 && s.Bet.Mult > s.Bet.MaxMult:
+		return fmt.Errorf("bet.mult must be <= bet.max_mult")
+	}
+	for i, m := range s.ModeSwitch {
+		if m.Mode < 0 {
+			return fmt.Errorf("mode_switch[%d]: mode must be >= 0", i)
+		}
+		if m.Above < 0 || m.Below < 0 || (m.Below > 0 && m.Above >= m.Below) {
+			return fmt.Errorf("mode_switch[%d]: need 0 <= above < below (or below = 0)", i)
+		}
+	}
+	return nil
+}
+
+// Check validates the strategy against the bet units of a game.
+func (s *Strategy) Check(betUnits []int) error {
+	for i, m := range s.ModeSwitch {
+		if m.Mode >= len(betUnits) {
+			return fmt.Errorf("mode_switch[%d]: bet mode %d not in game (bet_units %v)", i, m.Mode, betUnits)
+		}
+	}
+	return nil
+}
+
+// maxSpins is the session spin limit implied by MaxSpins, MaxMinutes and limit.
+func (s *Strategy) maxSpins(limit int) int {
+	n := limit
+	if s.MaxSpins > 0 {
+		n = min(n, s.MaxSpins)
+	}
+	if s.MaxMinutes > 0 {
+		n = min(n, max(1, int(s.MaxMinutes*60/s.spinSeconds())))
+	}
+	return n
+}
+
+func (s *Strategy) spinSeconds() float64 {
+	if s.SpinSeconds > 0 {
+		return s.SpinSeconds
+	}
+	return DefaultSpinTime.Seconds()
+}
+
+// String is a one-line description for banners.
+func (s *Strategy) String() string {
+	parts := []string{s.Name}
+	if s.StopWin > 0 {
+		parts = append(parts, fmt.Sprintf("stop-win %gx", s.StopWin))
+	}
+	if s.StopLoss > 0 {
+		parts = append(parts, fmt.Sprintf("stop-loss %g%%", 100*s.StopLoss))
+	}
+	if s.MaxSpins > 0 {
+		parts = append(parts, fmt.Sprintf("max %d spins", s.MaxSpins))
+	}
+	if s.MaxMinutes > 0 {
+		parts = append(parts, fmt.Sprintf("max %gmin @ %gs/spin", s.MaxMinutes, s.spinSeconds()))
+	}
+	if b := s.Bet; b.OnWin != 0 || b.OnLoss != 0 || b.Mult > 1 {
+		parts = append(parts, fmt.Sprintf("bet x%d win*%g loss*%g cap %d", max(b.Mult, 1), b.OnWin, b.OnLoss, b.MaxMult))
+	}
+	if len(s.ModeSwitch) > 0 {
+		modes := make([]int, len(s.ModeSwitch))
+		for i, m := range s.ModeSwitch {
+			modes[i] = m.Mode
+		}
+		parts = append(parts, fmt.Sprintf("mode switch %v", slices.Compact(modes)))
+	}
+	return strings.Join(parts, ", ")
+}
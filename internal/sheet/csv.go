@@ -0,0 +1,309 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sheet converts the reel strips and pay tables of game configs to and from
+// the CSV files spreadsheets export, and writes them back into a config.
+//
+// A reels CSV has one column per reel, top to bottom, named by its header (e.g. R1). A
+// column whose header is "weight" or ends in " weight" or "_weight" holds the stop
+// weights of the reel to its left. Reels may differ in length: a reel ends at its first
+// empty cell. Cells hold symbol names of symbol_used (H1, L2) or symbol ids.
+//
+// A pay table CSV has one row per symbol: its name (or id), then the pay of 1, 2, ...
+// symbols in a row; empty cells pay 0. Rows may come in any order; they are written in
+// the order of symbol_used.
+package sheet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/zintix-labs/problab/spec"
+)
+
+// Error is a problem of a CSV cell; Row and Column are 1-based, Column 0 for the row.
+type Error struct {
+	Row, Column int
+	Msg         string
+}
+
+func (e *Error) Error() string {
+	if e.Column == 0 {
+		return fmt.Sprintf("row %d: %s", e.Row, e.Msg)
+	}
+	return fmt.Sprintf("row %d, column %d: %s", e.Row, e.Column, e.Msg)
+}
+
+// ReadReels reads a reels CSV; symbols is the symbol_used of the game mode.
+func ReadReels(r io.Reader, symbols []string) ([]spec.Reel, error) {
+	recs, err := records(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(recs) == 0 {
+		return nil, &Error{Row: 1, Msg: "no header row"}
+	}
+	header := recs[0]
+	for len(header) > 0 && strings.TrimSpace(header[len(header)-1]) == "" {
+		header = header[:len(header)-1]
+	}
+	var (
+		reels []spec.Reel
+		cols  []int // the column of each reel
+		wcols []int // the weights column of each reel, or -1
+	)
+	for c, h := range header {
+		if isWeight(h) {
+			if len(cols) == 0 || wcols[len(wcols)-1] >= 0 || cols[len(cols)-1] != c-1 {
+				return nil, &Error{Row: 1, Column: c + 1, Msg: fmt.Sprintf("weights column %q does not follow a reel column", h)}
+			}
+			wcols[len(wcols)-1] = c
+			continue
+		}
+		reels = append(reels, spec.Reel{})
+		cols, wcols = append(cols, c), append(wcols, -1)
+	}
+	if len(reels) == 0 {
+		return nil, &Error{Row: 1, Msg: "no reel column"}
+	}
+	ids := symbolIDs(symbols)
+	for i := range reels {
+		end := 0 // row of the first empty cell
+		for r, rec := range recs[1:] {
+			row := r + 2
+			sym, w := cell(rec, cols[i]), cell(rec, wcols[i])
+			if sym == "" {
+				if end == 0 {
+					end = row
+				}
+				if w != "" {
+					return nil, &Error{Row: row, Column: wcols[i] + 1, Msg: fmt.Sprintf("weight %q without a symbol in %s", w, header[cols[i]])}
+				}
+				continue
+			}
+			if end != 0 {
+				return nil, &Error{Row: row, Column: cols[i] + 1, Msg: fmt.Sprintf("%s continues after its empty cell at row %d", header[cols[i]], end)}
+			}
+			id, ok := ids[strings.ToUpper(sym)]
+			if !ok {
+				n, err := strconv.Atoi(sym)
+				if err != nil || n < 0 || n >= len(symbols) {
+					return nil, &Error{Row: row, Column: cols[i] + 1, Msg: fmt.Sprintf("unknown symbol %q; symbol_used is %s", sym, strings.Join(symbols, ", "))}
+				}
+				id = int16(n)
+			}
+			reels[i].ReelSymbols = append(reels[i].ReelSymbols, id)
+			if wcols[i] < 0 {
+				continue
+			}
+			n, err := strconv.Atoi(w)
+			if err != nil || n < 0 {
+				return nil, &Error{Row: row, Column: wcols[i] + 1, Msg: fmt.Sprintf("weight %q is not a non-negative integer", w)}
+			}
+			reels[i].ReelWeights = append(reels[i].ReelWeights, n)
+		}
+		if len(reels[i].ReelSymbols) == 0 {
+			return nil, &Error{Row: 2, Column: cols[i] + 1, Msg: fmt.Sprintf("reel %s is empty", header[cols[i]])}
+		}
+	}
+	return reels, nil
+}
+
+// WriteReels writes reels as a reels CSV, symbols by name: columns R1, R2, ... each
+// followed by its weights column when the reel has weights.
+func WriteReels(w io.Writer, reels []spec.Reel, symbols []string) error {
+	var header []string
+	rows := 0
+	for i, r := range reels {
+		header = append(header, fmt.Sprintf("R%d", i+1))
+		if len(r.ReelWeights) > 0 {
+			header = append(header, fmt.Sprintf("R%d weight", i+1))
+		}
+		rows = max(rows, len(r.ReelSymbols))
+	}
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	for row := range rows {
+		var rec []string
+		for _, r := range reels {
+			sym, weight := "", ""
+			if row < len(r.ReelSymbols) {
+				sym = symbolName(symbols, int(r.ReelSymbols[row]))
+				if row < len(r.ReelWeights) {
+					weight = strconv.Itoa(r.ReelWeights[row])
+				}
+			}
+			rec = append(rec, sym)
+			if len(r.ReelWeights) > 0 {
+				rec = append(rec, weight)
+			}
+		}
+		cw.Write(rec)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadPayTable reads a pay table CSV into one row per symbol of symbols.
+func ReadPayTable(r io.Reader, symbols []string) ([][]int, error) {
+	recs, err := records(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(recs) == 0 {
+		return nil, &Error{Row: 1, Msg: "no header row"}
+	}
+	header := recs[0]
+	for len(header) > 1 && strings.TrimSpace(header[len(header)-1]) == "" {
+		header = header[:len(header)-1]
+	}
+	n := len(header) - 1
+	if n < 1 {
+		return nil, &Error{Row: 1, Msg: "no pay column: write symbol, 1, 2, ... up to the longest win"}
+	}
+	ids := symbolIDs(symbols)
+	table := make([][]int, len(symbols))
+	from := make([]int, len(symbols)) // row of each symbol
+	for r, rec := range recs[1:] {
+		row := r + 2
+		sym := cell(rec, 0)
+		if sym == "" {
+			if strings.TrimSpace(strings.Join(rec, "")) != "" {
+				return nil, &Error{Row: row, Column: 1, Msg: "pays without a symbol"}
+			}
+			continue
+		}
+		id, ok := ids[strings.ToUpper(sym)]
+		if !ok {
+			v, err := strconv.Atoi(sym)
+			if err != nil || v < 0 || v >= len(symbols) {
+				return nil, &Error{Row: row, Column: 1, Msg: fmt.Sprintf("unknown symbol %q; symbol_used is %s", sym, strings.Join(symbols, ", "))}
+			}
+			id = int16(v)
+		}
+		if from[id] != 0 {
+			return nil, &Error{Row: row, Column: 1, Msg: fmt.Sprintf("symbol %s is also at row %d", symbols[id], from[id])}
+		}
+		from[id] = row
+		pays := make([]int, n)
+		for c := range n {
+			s := cell(rec, c+1)
+			if s == "" {
+				continue
+			}
+			v, err := strconv.Atoi(s)
+			if err != nil || v < 0 {
+				return nil, &Error{Row: row, Column: c + 2, Msg: fmt.Sprintf("pay %q is not a non-negative integer", s)}
+			}
+			pays[c] = v
+		}
+		if len(rec) > n+1 && strings.TrimSpace(strings.Join(rec[n+1:], "")) != "" {
+			return nil, &Error{Row: row, Column: n + 2, Msg: "more pays than the header has columns"}
+		}
+		table[id] = pays
+	}
+	var missing []string
+	for id, row := range from {
+		if row == 0 {
+			missing = append(missing, symbols[id])
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &Error{Row: len(recs), Msg: fmt.Sprintf("no row for %s: the pay table needs one row per symbol of symbol_used", strings.Join(missing, ", "))}
+	}
+	return table, nil
+}
+
+// WritePayTable writes a pay table as a pay table CSV, symbols by name.
+func WritePayTable(w io.Writer, table [][]int, symbols []string) error {
+	n := 0
+	for _, row := range table {
+		n = max(n, len(row))
+	}
+	header := []string{"symbol"}
+	for k := 1; k <= n; k++ {
+		header = append(header, strconv.Itoa(k))
+	}
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	for id, row := range table {
+		rec := make([]string, n+1)
+		rec[0] = symbolName(symbols, id)
+		for k := range n {
+			rec[k+1] = "0"
+			if k < len(row) {
+				rec[k+1] = strconv.Itoa(row[k])
+			}
+		}
+		cw.Write(rec)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// records reads every record of a CSV export: rows may be ragged, a UTF-8 BOM is
+// dropped, and the separator is ';' when the header has ';' but no ','.
+func records(r io.Reader) ([][]string, error) {
+	br := bufio.NewReader(r)
+	if b, err := br.Peek(3); err == nil && bytes.Equal(b, []byte("\xef\xbb\xbf")) {
+		br.Discard(3)
+	}
+	cr := csv.NewReader(br)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	head, _ := br.Peek(4096) // what is buffered, at EOF too
+	head, _, _ = bytes.Cut(head, []byte("\n"))
+	if bytes.ContainsRune(head, ';') && !bytes.ContainsRune(head, ',') {
+		cr.Comma = ';'
+	}
+	return cr.ReadAll()
+}
+
+func cell(rec []string, c int) string {
+	if c < 0 || c >= len(rec) {
+		return ""
+	}
+	return strings.TrimSpace(rec[c])
+}
+
+func isWeight(h string) bool {
+	h = strings.ToLower(strings.TrimSpace(h))
+	for _, w := range []string{"weight", "weights"} {
+		if h == w || strings.HasSuffix(h, " "+w) || strings.HasSuffix(h, "_"+w) {
+			return true
+		}
+	}
+	return false
+}
+
+// symbolIDs maps the upper-case names of symbol_used to their ids.
+func symbolIDs(symbols []string) map[string]int16 {
+	ids := make(map[string]int16, len(symbols))
+	for i, s := range symbols {
+		ids[strings.ToUpper(strings.TrimSpace(s))] = int16(i)
+	}
+	return ids
+}
+
+func symbolName(symbols []string, id int) string {
+	if id >= 0 && id < len(symbols) {
+		return symbols[id]
+	}
+	return strconv.Itoa(id)
+}
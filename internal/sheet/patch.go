@@ -0,0 +1,373 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sheet
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zintix-labs/problab-scaffold/internal/configs"
+	"github.com/zintix-labs/problab/spec"
+	"gopkg.in/yaml.v3"
+)
+
+// ReelsPath is the override path of the reels of reel set set of game mode mode.
+func ReelsPath(mode, set int) string {
+	return fmt.Sprintf("game_mode_settings[%d].gen_screen_setting.reel_set_group[%d].reels", mode, set)
+}
+
+// PayTablePath is the override path of the pay table of game mode mode.
+func PayTablePath(mode int) string {
+	return fmt.Sprintf("game_mode_settings[%d].symbol_setting.pay_table", mode)
+}
+
+// ReelsNode is the config value of reels, one `- # Reel[i]` item per reel.
+func ReelsNode(reels []spec.Reel) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for i, r := range reels {
+		item := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", LineComment: fmt.Sprintf("# Reel[%d]", i)}
+		item.Content = append(item.Content, key("symbols"), ints(r.ReelSymbols))
+		if len(r.ReelWeights) > 0 {
+			item.Content = append(item.Content, key("weights"), ints(r.ReelWeights))
+		}
+		n.Content = append(n.Content, item)
+	}
+	return n
+}
+
+// PayTableNode is the config value of a pay table, one row per line.
+func PayTableNode(table [][]int) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for _, row := range table {
+		n.Content = append(n.Content, ints(row))
+	}
+	return n
+}
+
+func key(k string) *yaml.Node { return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k} }
+
+func ints[T int | int16](vs []T) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+	for _, v := range vs {
+		n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(int(v))})
+	}
+	return n
+}
+
+// Set replaces the value at path p (see configs.ParsePath) of the config src with v.
+// The key must be in the config already. In a YAML config only the old value is
+// rewritten, so comments and layout elsewhere are kept; a JSON config is re-encoded with
+// its keys in order.
+func Set(src []byte, isJSON bool, p string, v *yaml.Node) ([]byte, error) {
+	steps, err := configs.ParsePath(p)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := steps[len(steps)-1].(string); !ok {
+		return nil, fmt.Errorf("path %s must end with a key", p)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(src, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("a config must be a mapping")
+	}
+	if base, ok := configs.VariantBase(doc.Content[0]); ok {
+		return nil, fmt.Errorf("the config is a variant of %s: write into its base, or set %s under its overrides", base, p)
+	}
+	parent, i, err := find(doc.Content[0], steps, !isJSON)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", p, err)
+	}
+	if isJSON {
+		parent.Content[i+1] = v
+		var b bytes.Buffer
+		writeJSON(&b, doc.Content[0])
+		var out bytes.Buffer
+		if err := json.Indent(&out, b.Bytes(), "", "  "); err != nil {
+			return nil, err
+		}
+		out.WriteByte('\n')
+		return out.Bytes(), nil
+	}
+	return splice(src, &doc, parent.Content[i], parent.Content[i+1], v)
+}
+
+// find returns the mapping holding the last key of steps and the index of that key.
+// With block, the value must not be nested in a flow-style value, which splice cannot
+// rewrite.
+func find(root *yaml.Node, steps []any, block bool) (*yaml.Node, int, error) {
+	n := root
+	for s, e := range steps {
+		if block && n.Style&yaml.FlowStyle != 0 {
+			return nil, 0, fmt.Errorf("%s is written in flow style ({...} or [...]); write it in block style to import into it", pathString(steps[:s]))
+		}
+		switch e := e.(type) {
+		case string:
+			i := -1
+			if n.Kind == yaml.MappingNode {
+				for k := 0; k+1 < len(n.Content); k += 2 {
+					if n.Content[k].Value == e {
+						i = k
+					}
+				}
+			}
+			if i < 0 {
+				return nil, 0, fmt.Errorf("the config has no %s", pathString(steps[:s+1]))
+			}
+			if s == len(steps)-1 {
+				return n, i, nil
+			}
+			n = n.Content[i+1]
+		case int:
+			if n.Kind != yaml.SequenceNode || e >= len(n.Content) {
+				return nil, 0, fmt.Errorf("the config has no %s", pathString(steps[:s+1]))
+			}
+			n = n.Content[e]
+		}
+		if n.Kind == yaml.AliasNode {
+			return nil, 0, fmt.Errorf("%s is an alias; write the value out to import into it", pathString(steps[:s+1]))
+		}
+	}
+	return nil, 0, errors.New("empty path")
+}
+
+func pathString(steps []any) string {
+	if len(steps) == 0 {
+		return "the config"
+	}
+	var b strings.Builder
+	for _, e := range steps {
+		switch e := e.(type) {
+		case string:
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(e)
+		case int:
+			fmt.Fprintf(&b, "[%d]", e)
+		}
+	}
+	return b.String()
+}
+
+// splice rewrites the text of the value of key k in the YAML source: from the colon
+// after k up to the next node that is not part of the old value, less the blank,
+// comment and bare "-" lines right before that node, which belong to it.
+func splice(src []byte, doc, k, old, v *yaml.Node) ([]byte, error) {
+	lines := bytes.SplitAfter(src, []byte("\n"))
+	offset := func(line int) int { // of the start of the 1-based line
+		o := 0
+		for _, l := range lines[:min(line-1, len(lines))] {
+			o += len(l)
+		}
+		return o
+	}
+	// the colon after the key
+	kl := lines[k.Line-1]
+	col := runeOffset(kl, k.Column-1) + len(k.Value)
+	colon := bytes.IndexByte(kl[min(col, len(kl)):], ':')
+	if colon < 0 {
+		return nil, fmt.Errorf("line %d: no colon after key %s", k.Line, k.Value)
+	}
+	start := offset(k.Line) + col + colon + 1
+
+	// the first node after the old value
+	inside := make(map[*yaml.Node]bool)
+	var mark func(n *yaml.Node)
+	mark = func(n *yaml.Node) {
+		inside[n] = true
+		for _, c := range n.Content {
+			mark(c)
+		}
+	}
+	mark(old)
+	var next *yaml.Node
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if !inside[n] && n.Kind != yaml.DocumentNode && after(n, k) && (next == nil || after(next, n)) {
+			next = n
+		}
+		for _, c := range n.Content {
+			walk(c)
+		}
+	}
+	walk(doc)
+	end, last := len(src), len(lines)
+	if next != nil {
+		if next.Line == k.Line {
+			return nil, fmt.Errorf("line %d: %s is written in flow style; write it in block style to import into it", k.Line, k.Value)
+		}
+		last = next.Line - 1
+		for last > k.Line && isLead(lines[last-1]) {
+			last--
+		}
+		end = offset(last + 1)
+	}
+
+	indent := strings.Repeat(" ", k.Column-1+2)
+	var b bytes.Buffer
+	b.Write(src[:start])
+	if v.Kind == yaml.SequenceNode && v.Style&yaml.FlowStyle == 0 {
+		b.WriteByte('\n')
+		writeBlock(&b, v, indent)
+	} else {
+		b.WriteByte(' ')
+		b.WriteString(flow(v, nil))
+		b.WriteByte('\n')
+	}
+	b.Write(src[end:])
+	return b.Bytes(), nil
+}
+
+// after reports whether n starts after m.
+func after(n, m *yaml.Node) bool {
+	return n.Line > m.Line || (n.Line == m.Line && n.Column > m.Column)
+}
+
+func isLead(line []byte) bool {
+	t := bytes.TrimSpace(line)
+	for len(t) > 0 && t[0] == '-' { // the dash of an item whose first key is below
+		t = bytes.TrimSpace(t[1:])
+	}
+	return len(t) == 0 || t[0] == '#'
+}
+
+// runeOffset is the byte offset of the n-th rune of line.
+func runeOffset(line []byte, n int) int {
+	o := 0
+	for ; n > 0 && o < len(line); n-- {
+		_, size := utf8.DecodeRune(line[o:])
+		o += size
+	}
+	return o
+}
+
+// writeBlock writes the block sequence v, items at indent. Mapping items put their line
+// comment after the dash and their keys below it; lists of int lists are aligned in
+// columns.
+func writeBlock(b *bytes.Buffer, v *yaml.Node, indent string) {
+	widths := columns(v)
+	for _, item := range v.Content {
+		b.WriteString(indent + "-")
+		if item.Kind != yaml.MappingNode {
+			b.WriteString(" " + flow(item, widths) + "\n")
+			continue
+		}
+		if item.LineComment != "" {
+			b.WriteString(" " + item.LineComment)
+		}
+		b.WriteByte('\n')
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			fmt.Fprintf(b, "%s  %s: %s\n", indent, item.Content[i].Value, flow(item.Content[i+1], nil))
+		}
+	}
+}
+
+// columns returns the width of each column of the int lists of v, or nil.
+func columns(v *yaml.Node) []int {
+	var w []int
+	for _, row := range v.Content {
+		if row.Kind != yaml.SequenceNode {
+			return nil
+		}
+		for i, c := range row.Content {
+			if i == len(w) {
+				w = append(w, 0)
+			}
+			w[i] = max(w[i], len(c.Value))
+		}
+	}
+	return w
+}
+
+// flow writes v in flow style. Items of an int list are padded to the widths given and
+// joined by ", ", or else padded to the widest item and joined by ",": [9,10, 4] like the
+// reels of the demos.
+func flow(v *yaml.Node, widths []int) string {
+	if v.Kind != yaml.SequenceNode {
+		out, _ := yaml.Marshal(v)
+		return strings.TrimSpace(string(out))
+	}
+	width := 0
+	for _, c := range v.Content {
+		width = max(width, len(c.Value))
+	}
+	items := make([]string, len(v.Content))
+	for i, c := range v.Content {
+		w := width
+		if widths != nil {
+			w = widths[i]
+		} else if i == 0 {
+			w = 0
+		}
+		if c.Kind == yaml.SequenceNode {
+			items[i] = flow(c, nil)
+		} else {
+			items[i] = fmt.Sprintf("%*s", w, c.Value)
+		}
+	}
+	if widths != nil {
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return "[" + strings.Join(items, ",") + "]"
+}
+
+// writeJSON writes n as compact JSON, keeping the order of mapping keys.
+func writeJSON(b *bytes.Buffer, n *yaml.Node) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		writeJSON(b, n.Content[0])
+	case yaml.AliasNode:
+		writeJSON(b, n.Alias)
+	case yaml.MappingNode:
+		b.WriteByte('{')
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			k, _ := json.Marshal(n.Content[i].Value)
+			b.Write(k)
+			b.WriteByte(':')
+			writeJSON(b, n.Content[i+1])
+		}
+		b.WriteByte('}')
+	case yaml.SequenceNode:
+		b.WriteByte('[')
+		for i, c := range n.Content {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeJSON(b, c)
+		}
+		b.WriteByte(']')
+	default:
+		switch n.ShortTag() {
+		case "!!int", "!!float", "!!bool":
+			b.WriteString(n.Value)
+		case "!!null":
+			b.WriteString("null")
+		default:
+			s, _ := json.Marshal(n.Value)
+			b.Write(s)
+		}
+	}
+}
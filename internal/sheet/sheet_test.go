@@ -0,0 +1,209 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sheet
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zintix-labs/problab-scaffold/internal/configs"
+	"github.com/zintix-labs/problab/spec"
+	"gopkg.in/yaml.v3"
+)
+
+var demos = []string{"zintix/demo_0/demo_0.yaml", "zintix/demo_1/demo_1.yaml"}
+
+func demo(t *testing.T, name string) ([]byte, spec.GameSetting) {
+	t.Helper()
+	raw, err := fs.ReadFile(configs.FS, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gs spec.GameSetting
+	if err := yaml.Unmarshal(raw, &gs); err != nil {
+		t.Fatal(err)
+	}
+	return raw, gs
+}
+
+// TestRoundTrip writes every reel set and pay table of the demos as CSV, reads it
+// back and writes it into the config; the config must decode to the same settings.
+func TestRoundTrip(t *testing.T) {
+	for _, name := range demos {
+		raw, gs := demo(t, name)
+		out := raw
+		for m, gm := range gs.GameModeSettings {
+			symbols := gm.SymbolSetting.SymbolUsedStr
+			for s, set := range gm.GenScreenSetting.ReelSetGroup {
+				var b bytes.Buffer
+				if err := WriteReels(&b, set.Reels, symbols); err != nil {
+					t.Fatal(err)
+				}
+				reels, err := ReadReels(&b, symbols)
+				if err != nil {
+					t.Fatalf("%s mode %d set %d: %v", name, m, s, err)
+				}
+				if !reflect.DeepEqual(reels, set.Reels) {
+					t.Fatalf("%s mode %d set %d: reels differ after the CSV round trip", name, m, s)
+				}
+				if out, err = Set(out, false, ReelsPath(m, s), ReelsNode(reels)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var b bytes.Buffer
+			if err := WritePayTable(&b, gm.SymbolSetting.PayTable, symbols); err != nil {
+				t.Fatal(err)
+			}
+			table, err := ReadPayTable(&b, symbols)
+			if err != nil {
+				t.Fatalf("%s mode %d: %v", name, m, err)
+			}
+			if out, err = Set(out, false, PayTablePath(m), PayTableNode(table)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		var got spec.GameSetting
+		if err := yaml.Unmarshal(out, &got); err != nil {
+			t.Fatalf("%s: %v\n%s", name, err, out)
+		}
+		if !reflect.DeepEqual(got, gs) {
+			t.Fatalf("%s: settings differ after import", name)
+		}
+		for _, kept := range []string{"# Symbols & Paytable", "# Win evaluation settings", "- # ReelSetIdx[0]", "line_table:"} {
+			if strings.Count(string(out), kept) != strings.Count(string(raw), kept) {
+				t.Fatalf("%s: %q not kept", name, kept)
+			}
+		}
+	}
+}
+
+func TestReadReels(t *testing.T) {
+	symbols := []string{"Z1", "C1", "W1", "H1", "L1"}
+	reels, err := ReadReels(strings.NewReader("\xef\xbb\xbfR1;R1 weight;R2\nh1;3;L1\n1;1;W1\n;;C1\n"), symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []spec.Reel{{ReelSymbols: []int16{3, 1}, ReelWeights: []int{3, 1}}, {ReelSymbols: []int16{4, 2, 1}}}
+	if !reflect.DeepEqual(reels, want) {
+		t.Fatalf("reels %+v, want %+v", reels, want)
+	}
+
+	cases := []struct{ csv, want string }{
+		{"R1,R2\nH1,L1\nH2,L1\n", `row 3, column 1: unknown symbol "H2"; symbol_used is Z1, C1, W1, H1, L1`},
+		{"R1,R2\nH1,\nH1,L1\n", "row 3, column 2: R2 continues after its empty cell at row 2"},
+		{"R1,weight\nH1,1\n,2\n", `row 3, column 2: weight "2" without a symbol in R1`},
+		{"R1,R1 weight\nH1,-1\n", `row 2, column 2: weight "-1" is not a non-negative integer`},
+		{"weight,R1\n1,H1\n", `row 1, column 1: weights column "weight" does not follow a reel column`},
+		{"R1,R2\nH1,\n", "row 2, column 2: reel R2 is empty"},
+		{"R1,R2\nH1,9\n", `row 2, column 2: unknown symbol "9"`},
+	}
+	for _, c := range cases {
+		if _, err := ReadReels(strings.NewReader(c.csv), symbols); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%q: got %v, want %q", c.csv, err, c.want)
+		}
+	}
+}
+
+func TestReadPayTable(t *testing.T) {
+	symbols := []string{"Z1", "W1", "H1"}
+	table, err := ReadPayTable(strings.NewReader("symbol,1,2,3\nH1,,5,20\nw1,0,10,50\n\nZ1\n"), symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]int{{0, 0, 0}, {0, 10, 50}, {0, 5, 20}}; !reflect.DeepEqual(table, want) {
+		t.Fatalf("pay table %v, want %v", table, want)
+	}
+
+	cases := []struct{ csv, want string }{
+		{"symbol,1,2,3\nZ1\nW1\n", "no row for H1"},
+		{"symbol,1,2,3\nZ1\nW1\nH1\nH1,1\n", "row 5, column 1: symbol H1 is also at row 4"},
+		{"symbol,1,2,3\nZ1\nW1\nH2\n", `row 4, column 1: unknown symbol "H2"`},
+		{"symbol,1,2,3\nZ1\nW1\nH1,0,x\n", `row 4, column 3: pay "x" is not a non-negative integer`},
+		{"symbol,1,2\nZ1\nW1\nH1,0,0,5\n", "row 4, column 4: more pays than the header has columns"},
+		{"symbol\nZ1\n", "row 1: no pay column"},
+	}
+	for _, c := range cases {
+		if _, err := ReadPayTable(strings.NewReader(c.csv), symbols); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%q: got %v, want %q", c.csv, err, c.want)
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	const src = `game_id: 1   # id
+modes:
+  - reel_set_group:
+      - weight: 1
+        reels:   # the strips
+          - symbols: [1, 2]
+          - symbols: [3, 4]
+
+      # second set
+      - {weight: 2, reels: [{symbols: [1]}]}
+    pay_table: [[0, 1], [0, 2]]
+tail: true
+`
+	reels := ReelsNode([]spec.Reel{{ReelSymbols: []int16{10, 2}, ReelWeights: []int{1, 3}}})
+	out, err := Set([]byte(src), false, "modes[0].reel_set_group[0].reels", reels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(src, `        reels:   # the strips
+          - symbols: [1, 2]
+          - symbols: [3, 4]
+`, `        reels:
+          - # Reel[0]
+            symbols: [10, 2]
+            weights: [1,3]
+`, 1)
+	if string(out) != want {
+		t.Fatalf("got\n%s\nwant\n%s", out, want)
+	}
+	out, err = Set(out, false, "modes[0].pay_table", PayTableNode([][]int{{0, 5}, {10, 200}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "    pay_table:\n      - [ 0,   5]\n      - [10, 200]\ntail: true\n") {
+		t.Fatalf("pay table from flow to block:\n%s", out)
+	}
+
+	if _, err := Set(out, false, "modes[0].reel_set_group[1].reels", reels); err == nil || !strings.Contains(err.Error(), "flow style") {
+		t.Fatalf("flow-style value: %v", err)
+	}
+	if _, err := Set(out, false, "modes[0].reel_set_group[2].reels", reels); err == nil || !strings.Contains(err.Error(), "has no modes[0].reel_set_group[2]") {
+		t.Fatalf("missing path: %v", err)
+	}
+	if _, err := Set([]byte("base: a.yaml\ngame_id: 2\n"), false, "bet_units", reels); err == nil || !strings.Contains(err.Error(), "variant of a.yaml") {
+		t.Fatalf("variant: %v", err)
+	}
+
+	js, err := Set([]byte(`{"game_id": 1, "pay_table": [[0]], "a": {"z": 1.5, "b": null}}`), true, "pay_table", PayTableNode([][]int{{0, 7}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !json.Valid(js) || !strings.Contains(string(js), `"game_id": 1,`) || strings.Index(string(js), `"z"`) > strings.Index(string(js), `"b"`) {
+		t.Fatalf("json:\n%s", js)
+	}
+	var v struct {
+		PayTable [][]int `json:"pay_table"`
+	}
+	if json.Unmarshal(js, &v); !reflect.DeepEqual(v.PayTable, [][]int{{0, 7}}) {
+		t.Fatalf("json pay table %v", v.PayTable)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simstat
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Paired accumulates one per-spin metric of two arms (A and B) that were driven by the
+// same random numbers, spin for spin.
+//
+// Because both arms see the same draws, most of the per-spin noise is shared and cancels
+// in the difference B-A; the test on the mean difference is therefore much tighter than
+// comparing two independent runs. For a 0/1 metric (hit, trigger, win >= Nx) the paired
+// z-test below is the large-sample McNemar test.
+type Paired struct {
+	N     int
+	SumA  float64
+	SumB  float64
+	SumAA float64
+	SumBB float64
+	SumD  float64
+	SumDD float64
+}
+
+// Add records the metric of one spin in arm A and in arm B.
+func (p *Paired) Add(a, b float64) {
+	d := b - a
+	p.N++
+	p.SumA += a
+	p.SumB += b
+	p.SumAA += a * a
+	p.SumBB += b * b
+	p.SumD += d
+	p.SumDD += d * d
+}
+
+// Merge adds the spins of o into p.
+func (p *Paired) Merge(o *Paired) {
+	p.N += o.N
+	p.SumA += o.SumA
+	p.SumB += o.SumB
+	p.SumAA += o.SumAA
+	p.SumBB += o.SumBB
+	p.SumD += o.SumD
+	p.SumDD += o.SumDD
+}
+
+// Delta is the outcome of a paired comparison at one confidence level.
+type Delta struct {
+	A     float64 `json:"a"     yaml:"a"`     // mean of arm A
+	B     float64 `json:"b"     yaml:"b"`     // mean of arm B
+	Delta float64 `json:"delta" yaml:"delta"` // B - A
+	SE    float64 `json:"se"    yaml:"se"`    // standard error of Delta
+	Lo    float64 `json:"lo"    yaml:"lo"`
+	Hi    float64 `json:"hi"    yaml:"hi"`
+	Z     float64 `json:"z"     yaml:"z"`
+	P     float64 `json:"p"     yaml:"p"` // two-sided p-value of Delta == 0
+	// Gain is how many times more spins two independent runs would need for the same SE
+	// (Var(A)+Var(B) over Var(B-A)); 0 when the arms never differ.
+	Gain float64 `json:"gain" yaml:"gain"`
+}
+
+// Delta returns the mean difference B-A with its normal-approximation interval and
+// two-sided z-test.
+//
+// When no spin differs the interval is [0,0] and P is 1. When every spin differs by the
+// same amount (zero variance but Delta != 0) P is 0 and Z is left at 0.
+func (p *Paired) Delta(confidence float64) Delta {
+	if p.N == 0 {
+		return Delta{P: 1}
+	}
+	n := float64(p.N)
+	d := Delta{A: p.SumA / n, B: p.SumB / n, Delta: p.SumD / n, P: 1}
+	if p.N < 2 {
+		return d
+	}
+	variance := func(sum, sq float64) float64 { return max(sq-sum*sum/n, 0) / (n - 1) }
+	vd := variance(p.SumD, p.SumDD)
+	if vd == 0 {
+		if d.Delta != 0 {
+			d.P = 0
+		}
+		d.Lo, d.Hi = d.Delta, d.Delta
+		return d
+	}
+	d.SE = math.Sqrt(vd / n)
+	half := Z(confidence) * d.SE
+	d.Lo, d.Hi = d.Delta-half, d.Delta+half
+	d.Z = d.Delta / d.SE
+	d.P = 2 * distuv.UnitNormal.Survival(math.Abs(d.Z))
+	d.Gain = (variance(p.SumA, p.SumAA) + variance(p.SumB, p.SumBB)) / vd
+	return d
+}
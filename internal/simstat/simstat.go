@@ -0,0 +1,66 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package simstat holds scaffold-side statistics on top of upstream `stats.StatReport`.
+//
+// Upstream reports are computed once per Sim/SimMP call with a fixed 95% interval.
+// The helpers here let cmd/* evaluate reports at any confidence level, count exact wins
+// and compare paired runs, without touching the engine.
+package simstat
+
+import (
+	"math"
+
+	"github.com/zintix-labs/problab/stats"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Z returns the two-sided standard normal critical value for a confidence level in (0,1).
+//
+// Example: Z(0.95) ≈ 1.96.
+func Z(confidence float64) float64 {
+	return distuv.UnitNormal.Quantile(0.5 + confidence/2)
+}
+
+// CI returns the normal-approximation RTP interval of r at the given confidence.
+//
+// Unlike the upstream 95% interval the lower bound is not clipped at 0, so Hi-Lo is
+// always the true interval width.
+func CI(r *stats.StatReport, confidence float64) stats.CI {
+	rtp := r.Rtp()
+	half := 0.0
+	if n := r.Summary.Rounds; n > 1 {
+		half = Z(confidence) * r.Std() / math.Sqrt(float64(n))
+	}
+	return stats.CI{Lo: rtp - half, Hi: rtp + half}
+}
+
+// VolatilityIndex returns z·σ, the usual slot volatility index: the half-width of the
+// per-spin win interval at the given confidence, in bet multiples.
+func VolatilityIndex(r *stats.StatReport, confidence float64) float64 {
+	return Z(confidence) * r.Std()
+}
+
+// SpinsForWidth estimates how many spins are needed for a CI of the given full width,
+// assuming the per-spin standard deviation observed in r.
+func SpinsForWidth(r *stats.StatReport, confidence float64, width float64) int {
+	if width <= 0 {
+		return 0
+	}
+	n := math.Pow(2*Z(confidence)*r.Std()/width, 2)
+	if n > math.MaxInt64/2 {
+		return math.MaxInt64 / 2
+	}
+	return int(math.Ceil(n))
+}
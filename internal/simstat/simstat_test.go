@@ -0,0 +1,143 @@
+// Copyright 2026 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simstat
+
+import (
+	"math"
+	"testing"
+
+	"github.com/zintix-labs/problab-scaffold/pkg/engine"
+	"github.com/zintix-labs/problab/spec"
+)
+
+func TestZ(t *testing.T) {
+	if z := Z(0.95); math.Abs(z-1.959964) > 1e-5 {
+		t.Fatalf("Z(0.95) = %v", z)
+	}
+	if z := Z(0.99); math.Abs(z-2.575829) > 1e-5 {
+		t.Fatalf("Z(0.99) = %v", z)
+	}
+}
+
+func TestCI(t *testing.T) {
+	lab, err := engine.New()
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	s, err := lab.NewSimulatorWithSeed(spec.GID(1), 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, _, _ := s.Sim(0, 5000, false)
+	ci95 := CI(st, 0.95)
+	ci99 := CI(st, 0.99)
+	if !(ci99.Hi-ci99.Lo > ci95.Hi-ci95.Lo) {
+		t.Fatalf("99%% interval %v should be wider than 95%% interval %v", ci99, ci95)
+	}
+	if math.Abs(ci95.Hi-st.Summary.RtpCI.Hi) > 1e-3 {
+		t.Fatalf("95%% upper bound %v differs from upstream %v", ci95.Hi, st.Summary.RtpCI.Hi)
+	}
+	// the interval of N spins is reached after about N spins, half of it after 4N
+	if n := SpinsForWidth(st, 0.95, ci95.Hi-ci95.Lo); n < 4999 || n > 5001 {
+		t.Fatalf("SpinsForWidth(own width) = %d, want 5000", n)
+	}
+	if n := SpinsForWidth(st, 0.95, (ci95.Hi-ci95.Lo)/2); n < 19996 || n > 20004 {
+		t.Fatalf("SpinsForWidth(half width) = %d, want 20000", n)
+	}
+}
+
+func TestWinCounts(t *testing.T) {
+	a, b := NewWinCounts(), NewWinCounts()
+	for _, w := range []int{0, 0, 0, 0, 5, 10, 40, 400} {
+		a.Add(w)
+	}
+	b.Add(100000) // beyond the dense range
+	b.Add(0)
+	a.Merge(b)
+
+	if a.Spins() != 10 || a.Max() != 100000 {
+		t.Fatalf("spins %d max %d", a.Spins(), a.Max())
+	}
+	if q := a.Quantile(0.5); q != 0 {
+		t.Fatalf("p50 = %d, want 0", q)
+	}
+	if q := a.Quantile(0.9); q != 400 {
+		t.Fatalf("p90 = %d, want 400", q)
+	}
+	if n := a.AtLeast(40); n != 3 {
+		t.Fatalf("AtLeast(40) = %d, want 3", n)
+	}
+	if got := WinCountsFromPairs(a.Pairs()); got.Spins() != a.Spins() || got.AtLeast(10) != a.AtLeast(10) || got.Max() != a.Max() {
+		t.Fatal("Pairs round trip changed the table")
+	}
+
+	// bet 10, cap 1000x: 0x | (0,1) | [1,10) | [10,1000) | cap
+	bands := a.Bands(10, []float64{10, 1, 5000}, 1000)
+	want := []int{5, 1, 2, 1, 1}
+	if len(bands) != len(want) {
+		t.Fatalf("got %d bands, want %d: %+v", len(bands), len(want), bands)
+	}
+	rtp := 0.0
+	for i, bd := range bands {
+		if bd.Spins != want[i] {
+			t.Fatalf("band %s: %d spins, want %d", bd.Label, bd.Spins, want[i])
+		}
+		rtp += bd.RTP
+	}
+	if total := float64(5+10+40+400+100000) / 100; math.Abs(rtp-total) > 1e-9 {
+		t.Fatalf("band RTP sums to %v, want %v", rtp, total)
+	}
+	if open := a.Bands(10, []float64{1}, 0); open[len(open)-1].Hi != nil {
+		t.Fatal("top band without a cap should be open")
+	}
+}
+
+func TestParseEdges(t *testing.T) {
+	if e, err := ParseEdges(" 1, 2.5 ,10"); err != nil || len(e) != 3 || e[1] != 2.5 {
+		t.Fatalf("ParseEdges = %v, %v", e, err)
+	}
+	for _, s := range []string{"", "1,-2", "a", "1,inf"} {
+		if _, err := ParseEdges(s); err == nil {
+			t.Fatalf("ParseEdges(%q) should fail", s)
+		}
+	}
+}
+
+func TestPairedDelta(t *testing.T) {
+	// B pays one more on every second spin: the shared noise cancels in the difference
+	var p Paired
+	for i := range 1000 {
+		a := float64(i % 7)
+		b := a
+		if i%2 == 0 {
+			b++
+		}
+		p.Add(a, b)
+	}
+	d := p.Delta(0.95)
+	if d.Delta != 0.5 || d.B-d.A != d.Delta || d.P > 1e-6 || d.Lo > 0.5 || d.Hi < 0.5 || d.Gain < 10 {
+		t.Fatalf("delta %+v", d)
+	}
+
+	var same, q Paired
+	for i := range 10 {
+		same.Add(float64(i), float64(i))
+		q.Add(float64(i), float64(i))
+	}
+	same.Merge(&q)
+	if d := same.Delta(0.95); same.N != 20 || d.Delta != 0 || d.P != 1 || d.Gain != 0 {
+		t.Fatalf("identical arms: %+v", d)
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simstat
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// denseWins is the number of small win values counted in a flat slice; larger wins go
+// to a map. Most spins win little, so the hot path rarely touches the map.
+const denseWins = 1 << 14
+
+// WinCounts is the frequency table of per-spin total wins, in credits, of one bet mode.
+//
+// Wins are integers, so the table is exact: any band histogram or percentile can be
+// computed from it afterwards, and tables from different workers merge without loss.
+type WinCounts struct {
+	dense  []int
+	sparse map[int]int
+	spins  int
+}
+
+func NewWinCounts() *WinCounts {
+	return &WinCounts{dense: make([]int, denseWins), sparse: map[int]int{}}
+}
+
+// Add records one spin.
+func (c *WinCounts) Add(win int) {
+	c.spins++
+	if win >= 0 && win < denseWins {
+		c.dense[win]++
+		return
+	}
+	c.sparse[win]++
+}
+
+// Spins returns the number of recorded spins.
+func (c *WinCounts) Spins() int { return c.spins }
+
+// Merge adds every count of o into c.
+func (c *WinCounts) Merge(o *WinCounts) {
+	for w, n := range o.dense {
+		c.dense[w] += n
+	}
+	for w, n := range o.sparse {
+		c.sparse[w] += n
+	}
+	c.spins += o.spins
+}
+
+// Pairs returns the table as (win, spins) pairs sorted by win, omitting empty wins.
+// It is the serialized form used by checkpoints and shard results.
+func (c *WinCounts) Pairs() [][2]int {
+	out := make([][2]int, 0, 256)
+	for w, n := range c.dense {
+		if n > 0 {
+			out = append(out, [2]int{w, n})
+		}
+	}
+	tail := make([][2]int, 0, len(c.sparse))
+	for w, n := range c.sparse {
+		tail = append(tail, [2]int{w, n})
+	}
+	slices.SortFunc(tail, func(a, b [2]int) int { return a[0] - b[0] })
+	return append(out, tail...)
+}
+
+// WinCountsFromPairs rebuilds a table from Pairs.
+func WinCountsFromPairs(pairs [][2]int) *WinCounts {
+	c := NewWinCounts()
+	for _, p := range pairs {
+		if p[0] >= 0 && p[0] < denseWins {
+			c.dense[p[0]] += p[1]
+		} else {
+			c.sparse[p[0]] += p[1]
+		}
+		c.spins += p[1]
+	}
+	return c
+}
+
+// Max returns the largest recorded win (0 when empty).
+func (c *WinCounts) Max() int {
+	mx := 0
+	for w, n := range c.dense {
+		if n > 0 {
+			mx = w
+		}
+	}
+	for w := range c.sparse {
+		mx = max(mx, w)
+	}
+	return mx
+}
+
+// Quantile returns the smallest win w such that at least a q fraction of spins won <= w.
+func (c *WinCounts) Quantile(q float64) int {
+	if c.spins == 0 {
+		return 0
+	}
+	need := int(math.Ceil(q * float64(c.spins)))
+	acc := 0
+	for _, p := range c.Pairs() {
+		acc += p[1]
+		if acc >= need {
+			return p[0]
+		}
+	}
+	return c.Max()
+}
+
+// AtLeast returns the number of spins that won at least w.
+func (c *WinCounts) AtLeast(w int) int {
+	n := 0
+	for _, p := range c.Pairs() {
+		if p[0] >= w {
+			n += p[1]
+		}
+	}
+	return n
+}
+
+// Band is one win band of a histogram. Bounds are in bet multiples.
+type Band struct {
+	Label string   `json:"band"        yaml:"band"`
+	Lo    float64  `json:"lo_x"        yaml:"lo_x"`
+	Hi    *float64 `json:"hi_x"        yaml:"hi_x"` // nil for the open top band
+	Spins int      `json:"spins"       yaml:"spins"`
+	Prob  float64  `json:"prob"        yaml:"prob"`
+	OneIn float64  `json:"one_in"      yaml:"one_in"` // 1/prob; 0 when the band was never hit
+	RTP   float64  `json:"rtp_contrib" yaml:"rtp_contrib"`
+}
+
+// Bands builds the histogram over the given edges (bet multiples, ascending, > 0).
+//
+// The bands are: exactly 0x, (0, e1), [e1, e2), ..., [ek, cap) and a final band of spins
+// that reached the max-win cap (win >= capX). Edges at or above capX are dropped. With
+// capX <= 0 the last band is [ek, +inf). RTP is each band's contribution to total RTP.
+func (c *WinCounts) Bands(bet int, edges []float64, capX float64) []Band {
+	es := make([]float64, 0, len(edges))
+	for _, e := range edges {
+		if e > 0 && (capX <= 0 || e < capX) {
+			es = append(es, e)
+		}
+	}
+	sort.Float64s(es)
+	es = slices.Compact(es)
+
+	// bounds[i] is the lower bound in credits of band i+1 (band 0 is exactly zero)
+	bands := []Band{{Label: "0x", Lo: 0, Hi: ptr(0)}}
+	bounds := []int{1}
+	lo := 0.0
+	for _, e := range es {
+		bands = append(bands, Band{Label: bandLabel(lo, e, lo == 0), Lo: lo, Hi: ptr(e)})
+		bounds = append(bounds, int(math.Ceil(e*float64(bet))))
+		lo = e
+	}
+	if capX > 0 {
+		bands = append(bands, Band{Label: bandLabel(lo, capX, lo == 0), Lo: lo, Hi: ptr(capX)})
+		bounds = append(bounds, int(math.Ceil(capX*float64(bet))))
+		bands = append(bands, Band{Label: "cap " + fmtX(capX), Lo: capX, Hi: ptr(capX)})
+	} else {
+		bands = append(bands, Band{Label: fmt.Sprintf("[%s, +inf)", fmtX(lo)), Lo: lo})
+	}
+
+	wins := make([]int, len(bands))
+	for _, p := range c.Pairs() {
+		w, n := p[0], p[1]
+		// band index = number of bounds <= w
+		i := sort.Search(len(bounds), func(i int) bool { return bounds[i] > w })
+		bands[i].Spins += n
+		wins[i] += w * n
+	}
+	for i := range bands {
+		b := &bands[i]
+		if c.spins > 0 {
+			b.Prob = float64(b.Spins) / float64(c.spins)
+			b.RTP = float64(wins[i]) / (float64(c.spins) * float64(bet))
+		}
+		if b.Spins > 0 {
+			b.OneIn = 1 / b.Prob
+		}
+	}
+	return bands
+}
+
+func bandLabel(lo, hi float64, openLo bool) string {
+	if openLo {
+		return fmt.Sprintf("(%s, %s)", fmtX(lo), fmtX(hi))
+	}
+	return fmt.Sprintf("[%s, %s)", fmtX(lo), fmtX(hi))
+}
+
+func fmtX(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) + "x" }
+
+func ptr(v float64) *float64 { return &v }
+
+// ParseEdges parses a comma-separated list of band edges in bet multiples, e.g. "1,5,10".
+func ParseEdges(s string) ([]float64, error) {
+	var out []float64
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil || v <= 0 || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("invalid band edge %q: must be a positive number", f)
+		}
+		out = append(out, v)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no band edges in %q", s)
+	}
+	return out, nil
+}
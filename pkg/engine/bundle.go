@@ -0,0 +1,85 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/zintix-labs/problab-scaffold/internal/bundle"
+	"github.com/zintix-labs/problab-scaffold/internal/configs"
+)
+
+// BundleInfo is the signed config bundle mounted over the config sources.
+type BundleInfo struct {
+	Path    string    `json:"-"              yaml:"-"`      // see BuildManifest.BundlePath
+	SHA256  string    `json:"sha256"         yaml:"sha256"` // of the whole archive
+	Key     string    `json:"key"            yaml:"key"`    // hex Ed25519 public key that verified it
+	Created time.Time `json:"created"        yaml:"created"`
+	Note    string    `json:"note,omitempty" yaml:"note,omitempty"`
+	Files   int       `json:"files"          yaml:"files"`
+}
+
+// Bundle returns the config bundle this process mounted; nil without one.
+func Bundle() *BundleInfo {
+	return mountedBundle
+}
+
+// BundleKey returns the hex Ed25519 public key built into this binary to verify config
+// bundles; empty when none is set.
+func BundleKey() string {
+	return bundleKey
+}
+
+// mountBundle mounts the config sources, then the bundle at path with Override once its
+// signature verifies with the hex public key and its files match its manifest. Without
+// a path it mounts the sources alone.
+func mountBundle(sources []configs.Source, path, key string) (*configs.Mounted, []fs.FS, *BundleInfo, error) {
+	if path == "" {
+		m, cfgs, err := mount(sources)
+		return m, cfgs, nil, err
+	}
+	if key == "" {
+		return nil, nil, nil, fmt.Errorf("config bundle %s: no public key to verify it (bundleKey)", path)
+	}
+	pub, err := bundle.ParsePublicKey(key)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("config bundle key: %w", err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("config bundle: %w", err)
+	}
+	b, err := bundle.Open(raw, pub)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("config bundle %s: %w", path, err)
+	}
+	withBundle := append(sources[:len(sources):len(sources)], configs.Source{Name: "bundle", FS: b.FS(), Override: true})
+	m, cfgs, err := mount(withBundle)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	info := &BundleInfo{
+		Path:    path,
+		SHA256:  b.SHA256,
+		Key:     key,
+		Created: b.Manifest.Created,
+		Note:    b.Manifest.Note,
+		Files:   len(b.Manifest.Files),
+	}
+	return m, cfgs, info, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"path"
+	"strings"
+
+	"github.com/zintix-labs/problab/spec"
+)
+
+// ReadConfig returns the raw bytes of a mounted config file; a variant is returned
+// resolved.
+//
+// name is the catalog ConfigName (e.g. "demo_0.yaml").
+func ReadConfig(name string) ([]byte, error) {
+	return readConfig(name)
+}
+
+// GameSetting parses and initializes a mounted config file the same way the catalog does.
+//
+// Tools use it to read screen sizes, reel strips and pay tables that the Problab API
+// does not expose.
+func GameSetting(name string) (*spec.GameSetting, error) {
+	raw, err := readConfig(name)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(path.Ext(name), ".json") {
+		return spec.GetGameSettingByJSON(raw)
+	}
+	return spec.GetGameSettingByYAML(raw)
+}
+
+// ConfigInfo is where a mounted config comes from.
+type ConfigInfo struct {
+	Name   string `json:"name"   yaml:"name"`   // catalog ConfigName, e.g. "demo_0.yaml"
+	Origin string `json:"origin" yaml:"origin"` // source and path, e.g. "embedded:zintix/demo_0/demo_0.yaml"
+	// Lineage is the origin of the config, then those of its bases when it is a variant,
+	// up to the root base.
+	Lineage []string `json:"lineage" yaml:"lineage"`
+}
+
+// Config returns where the mounted config name comes from. ReadConfig returns it
+// resolved: a variant overlaid on its bases.
+func Config(name string) (ConfigInfo, bool) {
+	if mounted == nil {
+		return ConfigInfo{}, false
+	}
+	origin, ok := mounted.Origin(name)
+	if !ok {
+		return ConfigInfo{}, false
+	}
+	lineage, _ := mounted.Lineage(name)
+	return ConfigInfo{Name: name, Origin: origin, Lineage: lineage}, true
+}
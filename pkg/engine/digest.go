@@ -0,0 +1,48 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+)
+
+// ConfigSHA256 returns the hex-encoded SHA-256 of a config file as mounted by the engine.
+//
+// name is the catalog ConfigName (e.g. "demo_0.yaml"). The digest is computed over the raw
+// bytes of the file, so two runs report the same hash only if they used byte-identical configs.
+func ConfigSHA256(name string) (string, error) {
+	raw, err := readConfig(name)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// readConfig looks the file up in every mounted config source, in mount order.
+func readConfig(name string) ([]byte, error) {
+	for _, src := range cfgs {
+		if src == nil {
+			continue
+		}
+		if raw, err := fs.ReadFile(src, name); err == nil {
+			return raw, nil
+		}
+	}
+	return nil, fmt.Errorf("config not found: %s", name)
+}
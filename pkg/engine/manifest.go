@@ -0,0 +1,355 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"maps"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/zintix-labs/problab-scaffold/internal/configs"
+	"github.com/zintix-labs/problab-scaffold/internal/logic"
+	"github.com/zintix-labs/problab/spec"
+)
+
+// upstreamModule is the module of the Problab engine.
+const upstreamModule = "github.com/zintix-labs/problab"
+
+// BuildManifest identifies what this binary runs: its module and source revision, the
+// engine version, the PRNG, every embedded config file, the config bundle and every
+// registered logic with the module that provides it. Two binaries with the same SHA256
+// run the same configs and logic code.
+type BuildManifest struct {
+	Module    ModuleInfo `json:"module"            yaml:"module"`
+	GoVersion string     `json:"go_version"        yaml:"go_version"`
+	Tags      string     `json:"tags,omitempty"    yaml:"tags,omitempty"` // build tags, e.g. prng_chacha20
+	VCS       *VCSInfo   `json:"vcs,omitempty"     yaml:"vcs,omitempty"`  // absent when the build is not stamped (go run, go test)
+	Engine    ModuleInfo `json:"engine"            yaml:"engine"`
+	PRNG      string     `json:"prng"              yaml:"prng"`
+	// Configs are the files embedded in configs.FS, by path.
+	Configs []FileDigest `json:"configs" yaml:"configs"`
+	// Mounted are the configs the catalog reads, resolved (see ConfigSHA256), with the
+	// source they come from.
+	Mounted []MountedDigest `json:"mounted" yaml:"mounted"`
+	// Bundle is the signed config bundle mounted over the sources, if any.
+	Bundle *BundleInfo `json:"bundle,omitempty" yaml:"bundle,omitempty"`
+	Logics []LogicInfo `json:"logics"           yaml:"logics"`
+	// SHA256 is the digest of the JSON encoding of the manifest with SHA256 and
+	// BundlePath empty.
+	SHA256 string `json:"sha256" yaml:"sha256"`
+	// BundlePath is the file Bundle was read from. It depends on where the binary is
+	// deployed, not on what it runs, so SHA256 does not cover it.
+	BundlePath string `json:"bundle_path,omitempty" yaml:"bundle_path,omitempty"`
+}
+
+// ModuleInfo is a Go module as recorded by debug.ReadBuildInfo. Version is "(devel)"
+// for the main module of a build outside a tagged revision; Sum is empty for it.
+type ModuleInfo struct {
+	Path    string `json:"path"          yaml:"path"`
+	Version string `json:"version"       yaml:"version"`
+	Sum     string `json:"sum,omitempty" yaml:"sum,omitempty"`
+}
+
+// String is path@version, then the sum when there is one.
+func (mi ModuleInfo) String() string {
+	if mi.Sum == "" {
+		return mi.Path + "@" + mi.Version
+	}
+	return mi.Path + "@" + mi.Version + " " + mi.Sum
+}
+
+// VCSInfo is the source revision stamped by go build.
+type VCSInfo struct {
+	System   string `json:"system"   yaml:"system"`
+	Revision string `json:"revision" yaml:"revision"`
+	Time     string `json:"time"     yaml:"time"`
+	Modified bool   `json:"modified" yaml:"modified"` // built with uncommitted changes
+}
+
+// FileDigest is the SHA-256 of one file.
+type FileDigest struct {
+	Path   string `json:"path"   yaml:"path"`
+	Size   int    `json:"size"   yaml:"size"`
+	SHA256 string `json:"sha256" yaml:"sha256"`
+}
+
+// MountedDigest is the SHA-256 of one mounted config, resolved.
+type MountedDigest struct {
+	Name   string `json:"name"   yaml:"name"`
+	Origin string `json:"origin" yaml:"origin"`
+	SHA256 string `json:"sha256" yaml:"sha256"`
+}
+
+// LogicInfo is one registered logic and the Go package and module of its builder.
+type LogicInfo struct {
+	Key     spec.LogicKey `json:"key"     yaml:"key"`
+	Package string        `json:"package" yaml:"package"`
+	Module  ModuleInfo    `json:"module"  yaml:"module"`
+}
+
+// readBuildInfo is debug.ReadBuildInfo, replaced in tests.
+var readBuildInfo = debug.ReadBuildInfo
+
+// manifest is built once, by the first New (or Manifest).
+var manifest = sync.OnceValues(buildManifest)
+
+// Manifest returns the build manifest of this binary.
+func Manifest() (*BuildManifest, error) {
+	return manifest()
+}
+
+// buildManifest collects the manifest from the build info, configs.FS, the mounted
+// configs and the logic registry.
+func buildManifest() (*BuildManifest, error) {
+	if mountErr != nil {
+		return nil, mountErr
+	}
+	bi, ok := readBuildInfo()
+	if !ok {
+		// stripped binaries and some test harnesses carry no build info: the manifest
+		// then has no module versions, but the engine still starts
+		bi = &debug.BuildInfo{GoVersion: runtime.Version()}
+	}
+	m := &BuildManifest{
+		Module:    moduleInfo(&bi.Main),
+		GoVersion: bi.GoVersion,
+		PRNG:      PRNG(),
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "-tags":
+			m.Tags = s.Value
+		case "vcs", "vcs.revision", "vcs.time", "vcs.modified":
+			if m.VCS == nil {
+				m.VCS = new(VCSInfo)
+			}
+			switch s.Key {
+			case "vcs":
+				m.VCS.System = s.Value
+			case "vcs.revision":
+				m.VCS.Revision = s.Value
+			case "vcs.time":
+				m.VCS.Time = s.Value
+			case "vcs.modified":
+				m.VCS.Modified = s.Value == "true"
+			}
+		}
+	}
+	m.Engine = ModuleInfo{Path: upstreamModule, Version: "unknown"}
+	if mod, ok := findModule(bi, upstreamModule); ok {
+		m.Engine = mod
+	}
+
+	// embedded files (WalkDir visits them in lexical order)
+	err := fs.WalkDir(configs.FS, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		raw, err := fs.ReadFile(configs.FS, p)
+		if err != nil {
+			return err
+		}
+		m.Configs = append(m.Configs, FileDigest{Path: p, Size: len(raw), SHA256: digest(raw)})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("manifest: %w", err)
+	}
+
+	entries, err := fs.ReadDir(mounted, ".")
+	if err != nil {
+		return nil, fmt.Errorf("manifest: %w", err)
+	}
+	uses := make(map[string]spec.LogicKey, len(entries))
+	for _, e := range entries {
+		hash, err := ConfigSHA256(e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("manifest: %w", err)
+		}
+		origin, _ := mounted.Origin(e.Name())
+		m.Mounted = append(m.Mounted, MountedDigest{Name: e.Name(), Origin: origin, SHA256: hash})
+		gs, err := GameSetting(e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("manifest: %w", err)
+		}
+		uses[e.Name()] = gs.LogicKey
+	}
+
+	m.Bundle = mountedBundle
+
+	for _, key := range logicKeys(uses) {
+		li := LogicInfo{Key: key, Package: "unknown", Module: ModuleInfo{Version: "unknown"}}
+		if pkg, ok := logic.Package(key); ok {
+			li.Package = pkg
+			if mod, ok := findModule(bi, pkg); ok {
+				li.Module = mod
+			}
+		}
+		m.Logics = append(m.Logics, li)
+	}
+
+	if m.SHA256, err = m.digest(); err != nil {
+		return nil, err
+	}
+	if m.Bundle != nil {
+		m.BundlePath = m.Bundle.Path
+	}
+	return m, nil
+}
+
+// digest is the SHA-256 of the JSON encoding of m with SHA256 and BundlePath empty.
+func (m *BuildManifest) digest() (string, error) {
+	c := *m
+	c.SHA256, c.BundlePath = "", ""
+	raw, err := json.Marshal(&c)
+	if err != nil {
+		return "", err
+	}
+	return digest(raw), nil
+}
+
+// logicKeys lists the logics registered with logic.Register and those the mounted configs
+// use (uses maps a config to its logic_key), sorted. A logic registered directly with
+// slot.GameRegister is only known by its key: its package and module are "unknown".
+func logicKeys(uses map[string]spec.LogicKey) []spec.LogicKey {
+	keys := logic.Keys()
+	for _, k := range uses {
+		if !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// findModule returns the module of the build that provides package pkg: the main module
+// or the dependency with the longest matching path.
+func findModule(bi *debug.BuildInfo, pkg string) (ModuleInfo, bool) {
+	var best *debug.Module
+	for _, mod := range append([]*debug.Module{&bi.Main}, bi.Deps...) {
+		if mod.Path != "" && (pkg == mod.Path || strings.HasPrefix(pkg, mod.Path+"/")) {
+			if best == nil || len(mod.Path) > len(best.Path) {
+				best = mod
+			}
+		}
+	}
+	if best == nil {
+		return ModuleInfo{}, false
+	}
+	return moduleInfo(best), true
+}
+
+// moduleInfo records mod, or what replaces it.
+func moduleInfo(mod *debug.Module) ModuleInfo {
+	mi := ModuleInfo{Path: mod.Path, Version: mod.Version, Sum: mod.Sum}
+	if r := mod.Replace; r != nil {
+		mi.Version, mi.Sum = r.Version, r.Sum
+		if r.Version == "" {
+			mi.Version = "=> " + r.Path
+		}
+	}
+	return mi
+}
+
+// Diff lists how m differs from want, e.g. a certified manifest, one line per change;
+// nil when they match.
+func (m *BuildManifest) Diff(want *BuildManifest) []string {
+	var diffs []string
+	field := func(name, got, want string) {
+		if got != want {
+			diffs = append(diffs, fmt.Sprintf("%s: %q, want %q", name, got, want))
+		}
+	}
+	field("module", m.Module.String(), want.Module.String())
+	field("go version", m.GoVersion, want.GoVersion)
+	field("tags", m.Tags, want.Tags)
+	vcs := func(v *VCSInfo) string {
+		if v == nil {
+			return ""
+		}
+		return fmt.Sprintf("%s %s %s modified=%t", v.System, v.Revision, v.Time, v.Modified)
+	}
+	field("vcs", vcs(m.VCS), vcs(want.VCS))
+	field("engine", m.Engine.String(), want.Engine.String())
+	field("prng", m.PRNG, want.PRNG)
+
+	diffs = append(diffs, diffSet("config", digests(m.Configs), digests(want.Configs))...)
+	mountedSet := func(ms []MountedDigest) map[string]string {
+		set := make(map[string]string, len(ms))
+		for _, c := range ms {
+			set[c.Name] = c.Origin + " " + c.SHA256
+		}
+		return set
+	}
+	diffs = append(diffs, diffSet("mounted", mountedSet(m.Mounted), mountedSet(want.Mounted))...)
+	bundle := func(b *BundleInfo) string {
+		if b == nil {
+			return ""
+		}
+		return b.SHA256 + " key " + b.Key
+	}
+	field("bundle", bundle(m.Bundle), bundle(want.Bundle))
+	logicSet := func(ls []LogicInfo) map[string]string {
+		set := make(map[string]string, len(ls))
+		for _, l := range ls {
+			set[string(l.Key)] = l.Package + " " + l.Module.String()
+		}
+		return set
+	}
+	diffs = append(diffs, diffSet("logic", logicSet(m.Logics), logicSet(want.Logics))...)
+	return diffs
+}
+
+// digests maps the files to their SHA-256.
+func digests(files []FileDigest) map[string]string {
+	set := make(map[string]string, len(files))
+	for _, f := range files {
+		set[f.Path] = f.SHA256
+	}
+	return set
+}
+
+// diffSet lists the keys that are missing, extra or different in got.
+func diffSet(kind string, got, want map[string]string) []string {
+	var diffs []string
+	for _, k := range slices.Sorted(maps.Keys(want)) {
+		g, ok := got[k]
+		switch {
+		case !ok:
+			diffs = append(diffs, fmt.Sprintf("%s %s: missing", kind, k))
+		case g != want[k]:
+			diffs = append(diffs, fmt.Sprintf("%s %s: %q, want %q", kind, k, g, want[k]))
+		}
+	}
+	for _, k := range slices.Sorted(maps.Keys(got)) {
+		if _, ok := want[k]; !ok {
+			diffs = append(diffs, fmt.Sprintf("%s %s: not in the wanted manifest", kind, k))
+		}
+	}
+	return diffs
+}
+
+func digest(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
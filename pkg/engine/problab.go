@@ -19,9 +19,11 @@
 // and the upstream Problab engine.
 //
 // Customize points:
-//   - PRNG / core factory: swap `core.Default()` with your own deterministic PRNGFactory.
-//   - Config FS: you may mount multiple fs.FS sources (e.g., embed + os.DirFS), but
-//     keeping a single source is recommended for simplicity.
+//   - PRNG / core factory: pick a built-in generator (internal/prng) with a build tag,
+//     see pRNGFactory, or set your own deterministic PRNGFactory there.
+//   - Config sources: mount the embedded tree plus, e.g., an os.DirFS override
+//     directory; see configs.Mount for which source wins a game_id. A signed config
+//     bundle, verified against a built-in public key, can be mounted over them.
 //   - Logic registry: you may register multiple logic sets, but keeping one registry
 //     is recommended to reduce operational complexity.
 //
@@ -30,6 +32,7 @@
 package engine
 
 import (
+	"fmt"
 	"io/fs"
 
 	"github.com/zintix-labs/problab"
@@ -51,34 +54,63 @@ import (
 //
 // If you need to customize wiring, edit the private variables below and ship a new version.
 var (
-	// PRNGFactory: replace this with your own deterministic PRNG implementation if needed.
-	// The engine only depends on the PRNG interface/factory, not a specific algorithm.
+	// PRNGFactory: the generator is chosen at build time by a build tag (prng_*.go):
+	//   - (no tag)              PCG64, the upstream core.Default(): same spins as before
+	//   - -tags prng_chacha20   ChaCha20 CSPRNG (RFC 8439 block function), keyed from the 64-bit seed
+	//   - -tags prng_xoshiro256 xoshiro256**
+	// Each is documented and checked against known-answer vectors in internal/prng; the
+	// binary reports its choice through PRNG(). Setting two tags fails the build.
+	// The engine only depends on the PRNG interface/factory, not a specific algorithm, so
+	// your own deterministic implementation can also be set here.
 	// See package `github.com/zintix-labs/problab/sdk/core` for the `PRNG` and `PRNGFactory` interface definitions.
 	// (On GitHub, the source lives under `github.com/zintix-labs/problab/blob/main/sdk/core/core.go`.)
-	pRNGFactory core.PRNGFactory = core.Default()
-	// Config FS: provide game settings/spec files (usually embedded via `embed`).
+	pRNGFactory core.PRNGFactory = selectedPRNG
+	// Config sources: provide game settings/spec files (usually embedded via `embed`).
 	//
-	// Default expectation (recommended): a flat FS layout.
-	// Your provided fs.FS should map directly to the config folder itself:
-	//   - files are addressed by filename only (no nested paths)
-	//   - no subdirectories are used for config lookup
-	// This keeps config loading deterministic and avoids runtime path dependencies.
+	// The sources are mounted in order into one flat FS (configs.Mount), addressed by
+	// file name, which is what the upstream catalog reads:
+	//   - each source may organise its configs in subfolders (<studio>/<game>/*.yaml)
+	//   - a later source with Override replaces the games of earlier ones by game_id
+	//   - any other duplicate game_id, and any duplicate game name or file name, makes
+	//     New() fail with both files in the error
+	// Files are read once at startup, so configs do not change under a running process.
 	//
-	// Advanced: you can mount multiple fs.FS sources via catalog/multi-FS patterns,
-	// but start with a single FS to keep maintenance simple.
-	cfgs []fs.FS = problab.Configs(configs.FS)
+	// To override embedded games from a directory, add for example:
+	//   {Name: "local", FS: os.DirFS("configs.local"), Override: true},
+	// keeping in mind that the process then depends on its working directory.
+	sources = []configs.Source{
+		{Name: "embedded", FS: configs.FS},
+	}
+	// Config bundle: a signed archive of configs (internal/bundle, `run bundle`) mounted
+	// with Override after the sources, e.g. approved configs shipped apart from the binary.
+	// Its Ed25519 signature must verify with bundleKey, the hex public key printed by
+	// `run bundle keygen`, and every file must match its manifest before the engine reads
+	// any of them; otherwise New() fails. Set both here or at link time:
+	//   go build -ldflags "-X github.com/zintix-labs/problab-scaffold/pkg/engine.bundlePath=/etc/game/configs.bundle
+	//                      -X github.com/zintix-labs/problab-scaffold/pkg/engine.bundleKey=<hex>"
+	bundlePath = ""
+	bundleKey  = ""
+
+	mounted, cfgs, mountedBundle, mountErr = mountBundle(sources, bundlePath, bundleKey)
 	// Logic registry: register your game logic builders/handlers.
 	// You can merge multiple registries, but a single registry is easiest to reason about.
 	logics []*slot.LogicRegistry = problab.Logics(logic.Logics)
 )
 
 // New constructs a Problab instance using the scaffold's embedded configs and logic registry.
+// The first call also builds the build manifest of the binary (see Manifest).
 //
 // It returns an error so production callers can decide how to report/handle engine failures.
 // Typical usage:
 //
 //	pb, err := engine.New()
 func New() (*problab.Problab, error) {
+	if mountErr != nil {
+		return nil, mountErr
+	}
+	if _, err := manifest(); err != nil {
+		return nil, err
+	}
 	pb, err := problab.NewAuto(pRNGFactory, cfgs, logics)
 	if err != nil {
 		return nil, err
@@ -95,3 +127,26 @@ func MustNew() *problab.Problab {
 	}
 	return pb
 }
+
+// PRNG is the name of the generator this binary was built with, e.g. "pcg64".
+func PRNG() string {
+	if f, ok := pRNGFactory.(interface{ Name() string }); ok {
+		return f.Name()
+	}
+	return fmt.Sprintf("%T", pRNGFactory)
+}
+
+// PRNGFactory returns the generator factory this binary was built with, for tools that
+// test its output (cmd/run rng).
+func PRNGFactory() core.PRNGFactory {
+	return pRNGFactory
+}
+
+// mount mounts the config sources for problab.NewAuto.
+func mount(sources []configs.Source) (*configs.Mounted, []fs.FS, error) {
+	m, err := configs.Mount(sources...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, problab.Configs(m), nil
+}
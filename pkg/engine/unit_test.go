@@ -15,10 +15,28 @@
 package engine
 
 import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 
+	"github.com/zintix-labs/problab"
+	"github.com/zintix-labs/problab-scaffold/internal/bundle"
+	"github.com/zintix-labs/problab-scaffold/internal/configs"
+	"github.com/zintix-labs/problab-scaffold/internal/prng"
 	"github.com/zintix-labs/problab/catalog"
+	"github.com/zintix-labs/problab/sdk/core"
 	"github.com/zintix-labs/problab/spec"
 )
 
@@ -30,6 +48,10 @@ func TestConfigsEmbedded(t *testing.T) {
 		if !configExists(name) {
 			t.Fatalf("config not found in embedded FS: %s", name)
 		}
+		info, ok := Config(name)
+		if !ok || info.Name != name || len(info.Lineage) != 1 || info.Lineage[0] != info.Origin {
+			t.Fatalf("config info of %s: %+v", name, info)
+		}
 	}
 }
 
@@ -162,3 +184,181 @@ func configExists(name string) bool {
 	}
 	return false
 }
+
+func TestMountDuplicateSources(t *testing.T) {
+	embedded := configs.Source{Name: "embedded", FS: configs.FS}
+	if _, _, err := mount([]configs.Source{embedded, {Name: "copy", FS: configs.FS}}); err == nil || !strings.Contains(err.Error(), "with Override") {
+		t.Fatalf("duplicate game ids across sources: %v", err)
+	}
+	_, cfgs, err := mount([]configs.Source{embedded, {Name: "copy", FS: configs.FS, Override: true}})
+	if err != nil || len(cfgs) != 1 {
+		t.Fatalf("override: %v", err)
+	}
+	if _, err := fs.ReadFile(cfgs[0], "demo_1.yaml"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPRNG(t *testing.T) {
+	f, ok := prng.Lookup(PRNG())
+	if !ok || core.PRNGFactory(f) != pRNGFactory {
+		t.Fatalf("PRNG() = %q is not the built-in factory in use", PRNG())
+	}
+	a, b := pRNGFactory.New(7), f.New(7)
+	for range 10 {
+		if a.Uint64() != b.Uint64() {
+			t.Fatal("the engine PRNG is not deterministic per seed")
+		}
+	}
+}
+
+func TestManifest(t *testing.T) {
+	m, err := Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := Manifest(); again != m {
+		t.Fatal("the manifest is built more than once")
+	}
+	if m.Module.Path != "github.com/zintix-labs/problab-scaffold" || m.Engine.Path != upstreamModule || m.Engine.Sum == "" || m.PRNG != PRNG() {
+		t.Fatalf("manifest %+v", m)
+	}
+	if len(m.Configs) != 2 || m.Configs[0].Path != "zintix/demo_0/demo_0.yaml" || len(m.Mounted) != 2 || m.Bundle != nil {
+		t.Fatalf("configs %+v, mounted %+v", m.Configs, m.Mounted)
+	}
+	for i, c := range m.Mounted {
+		raw, _ := fs.ReadFile(configs.FS, m.Configs[i].Path)
+		if hash, _ := ConfigSHA256(c.Name); c.SHA256 != hash || m.Configs[i].SHA256 != hash || m.Configs[i].Size != len(raw) {
+			t.Fatalf("config %s: %+v, %+v", c.Name, c, m.Configs[i])
+		}
+	}
+	keys := make([]spec.LogicKey, len(m.Logics))
+	for i, l := range m.Logics {
+		keys[i] = l.Key
+		if l.Package != "github.com/zintix-labs/problab-scaffold/internal/logic" || l.Module.Path != m.Module.Path {
+			t.Fatalf("logic %+v", l)
+		}
+	}
+	if !slices.Equal(keys, []spec.LogicKey{"demo_cascade", "demo_normal"}) {
+		t.Fatalf("logic keys %v", keys)
+	}
+
+	// the digest covers the manifest; Diff names what changed
+	c := *m
+	c.SHA256 = ""
+	raw, _ := json.Marshal(&c)
+	if digest(raw) != m.SHA256 {
+		t.Fatal("SHA256 is not the digest of the manifest")
+	}
+	// where the bundle was read from is reported beside the digest, not in it
+	c.Bundle = &BundleInfo{Path: "/srv/a/configs.bundle", SHA256: "ab", Key: "cd"}
+	c.BundlePath = c.Bundle.Path
+	withBundle, _ := c.digest()
+	c.Bundle.Path, c.BundlePath = "/srv/b/configs.bundle", "/srv/b/configs.bundle"
+	if moved, _ := c.digest(); moved != withBundle || withBundle == m.SHA256 {
+		t.Fatalf("bundle digest %s, moved %s", withBundle, moved)
+	}
+	if raw, _ := json.Marshal(c.Bundle); strings.Contains(string(raw), "/srv/") {
+		t.Fatalf("bundle info encodes its path: %s", raw)
+	}
+	c.Bundle, c.BundlePath = nil, ""
+	if d := m.Diff(m); d != nil {
+		t.Fatalf("diff with itself: %v", d)
+	}
+	c.Configs = slices.Clone(m.Configs)
+	c.Configs[1].SHA256 = "00"
+	c.Logics = m.Logics[:1]
+	c.PRNG = "mt19937"
+	c.Bundle = &BundleInfo{SHA256: "ab", Key: "cd"}
+	want := []string{
+		`prng: "` + m.PRNG + `", want "mt19937"`,
+		`config zintix/demo_1/demo_1.yaml: "` + m.Configs[1].SHA256 + `", want "00"`,
+		`bundle: "", want "ab key cd"`,
+		"logic demo_normal: not in the wanted manifest",
+	}
+	if d := m.Diff(&c); !slices.Equal(d, want) {
+		t.Fatalf("diff %q", d)
+	}
+
+	// a logic registered without logic.Register is listed by its key only
+	keys = logicKeys(map[string]spec.LogicKey{"demo_0.yaml": "demo_normal", "x.yaml": "direct"})
+	if !slices.Equal(keys, []spec.LogicKey{"demo_cascade", "demo_normal", "direct"}) {
+		t.Fatalf("logic keys with a direct registration: %v", keys)
+	}
+}
+
+func TestManifestWithoutBuildInfo(t *testing.T) {
+	readBuildInfo = func() (*debug.BuildInfo, bool) { return nil, false }
+	defer func() { readBuildInfo = debug.ReadBuildInfo }()
+	m, err := buildManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Module.Path != "" || m.VCS != nil || m.Engine.Version != "unknown" || m.GoVersion != runtime.Version() || len(m.Mounted) != 2 || m.SHA256 == "" {
+		t.Fatalf("manifest without build info: %+v", m)
+	}
+	for _, l := range m.Logics {
+		if l.Module.Version != "unknown" {
+			t.Fatalf("logic %+v", l)
+		}
+	}
+}
+
+func TestMountBundle(t *testing.T) {
+	raw, _ := fs.ReadFile(configs.FS, "zintix/demo_0/demo_0.yaml")
+	tree := fstest.MapFS{"zintix/demo_0/demo_0.yaml": {Data: append(slices.Clone(raw), "# approved\n"...)}}
+	pub, privPEM, _ := bundle.GenerateKey(rand.Reader)
+	priv, _ := bundle.ParsePrivateKey(privPEM)
+	key := hex.EncodeToString(pub)
+	dir := t.TempDir()
+	write := func(name string, priv ed25519.PrivateKey) string {
+		var buf bytes.Buffer
+		if _, err := bundle.Create(&buf, tree, "approved", time.Now(), priv); err != nil {
+			t.Fatal(err)
+		}
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	signed, unsigned := write("signed.bundle", priv), write("unsigned.bundle", nil)
+
+	m, cfgs, info, err := mountBundle(sources, signed, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if origin, _ := m.Origin("demo_0.yaml"); origin != "bundle:zintix/demo_0/demo_0.yaml" {
+		t.Fatalf("demo_0.yaml mounted from %s", origin)
+	}
+	if origin, _ := m.Origin("demo_1.yaml"); origin != "embedded:zintix/demo_1/demo_1.yaml" {
+		t.Fatalf("demo_1.yaml mounted from %s", origin)
+	}
+	if info.Path != signed || info.Key != key || info.Files != 1 || info.Note != "approved" || len(info.SHA256) != 64 {
+		t.Fatalf("bundle info %+v", info)
+	}
+	if len(sources) != 1 {
+		t.Fatal("mountBundle changed the sources")
+	}
+	if _, err := problab.NewAuto(pRNGFactory, cfgs, logics); err != nil {
+		t.Fatal(err)
+	}
+
+	other, _, _ := bundle.GenerateKey(rand.Reader)
+	for _, tt := range []struct {
+		path, key, error string
+	}{
+		{signed, "", "no public key"},
+		{signed, "zz", "config bundle key"},
+		{signed, hex.EncodeToString(other), "does not verify"},
+		{unsigned, key, "not signed"},
+		{filepath.Join(dir, "missing.bundle"), key, "no such file"},
+	} {
+		if _, _, _, err := mountBundle(sources, tt.path, tt.key); err == nil || !strings.Contains(err.Error(), tt.error) {
+			t.Errorf("mountBundle(%s, %q) = %v, want an error containing %q", filepath.Base(tt.path), tt.key, err, tt.error)
+		}
+	}
+	if _, _, info, err := mountBundle(sources, "", key); err != nil || info != nil {
+		t.Fatalf("no bundle: %+v, %v", info, err)
+	}
+}
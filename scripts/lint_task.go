@@ -0,0 +1,43 @@
+// Copyright 2025 Zintix Labs
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"os/exec"
+)
+
+// runLint checks every embedded config without running it.
+//
+// Equivalent Makefile target:
+//
+//	lint:
+//
+//	  go run ./cmd/run lint
+//
+// Behavior:
+//  1. Print each diagnostic as file:line:column with a suggested fix
+//  2. Exit 1 when any config has an error
+func runLint() {
+	PrintGreen("linting configs")
+
+	cmd := exec.Command("go", "run", "./cmd/run", "lint")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		PrintRed("\nLint finished with errors\n")
+		os.Exit(1)
+	}
+}
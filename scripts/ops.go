@@ -42,6 +42,8 @@ func selectTask(task string) {
 		runTestAll()
 	case "test-detail":
 		runTestDetail()
+	case "lint":
+		runLint()
 	default:
 		PrintYellow(fmt.Sprintf("Unknown task: %s\n", task))
 		os.Exit(1)